@@ -0,0 +1,256 @@
+// Package cassette provides an http.RoundTripper that records API interactions to a file and replays them,
+// so tests written against a live Proxmox VE cluster can run offline.
+//
+// Secrets like passwords, tickets and API tokens are scrubbed before they are written to disk.
+//
+//	transport, err := cassette.New("testdata/pool.json", cassette.ModeReplay, cassette.MatchStrict, nil)
+//	client, err := proxmox.NewClient(apiUrl, transport.Client(), "", nil, "", 300, false)
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/Telmate/proxmox-api-go/internal/redact"
+)
+
+// Mode decides whether interactions are recorded or replayed.
+type Mode uint8
+
+const (
+	// Replays the interactions from the cassette file, requests are never sent.
+	ModeReplay Mode = 0
+	// Sends requests and records the interactions, they are written to the cassette file by Save.
+	ModeRecord Mode = 1
+)
+
+// Matching decides how a request is matched with a recorded interaction during replay.
+type Matching uint8
+
+const (
+	// Requests must be made in the recorded order, with the same method, path, query and body.
+	MatchStrict Matching = 0
+	// Requests are matched with the first unused interaction with the same method and path, in any order.
+	MatchLenient Matching = 1
+)
+
+const (
+	Transport_Error_Mismatch      = "cassette: request does not match the recorded interaction: "
+	Transport_Error_NoInteraction = "cassette: no recorded interaction for request: "
+	Transport_Error_NotRecording  = "cassette: only a recording cassette can be saved"
+)
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. Only the path and query are kept, so cassettes don't depend on the address of the cluster.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+func (r Request) String() string {
+	if r.Query != "" {
+		return r.Method + " " + r.Path + "?" + r.Query
+	}
+	return r.Method + " " + r.Path
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type file struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Transport records or replays interactions, it is safe for concurrent use.
+type Transport struct {
+	path     string
+	mode     Mode
+	matching Matching
+	next     http.RoundTripper
+
+	mutex        sync.Mutex
+	interactions []Interaction
+	used         []bool
+	position     int
+}
+
+var _ http.RoundTripper = &Transport{}
+
+// New creates a transport for the cassette file at path.
+// In ModeReplay the file is loaded immediately, in ModeRecord requests are sent with next, or http.DefaultTransport when nil.
+func New(path string, mode Mode, matching Matching, next http.RoundTripper) (*Transport, error) {
+	t := &Transport{path: path, mode: mode, matching: matching, next: next}
+	if t.next == nil {
+		t.next = http.DefaultTransport
+	}
+	if mode == ModeRecord {
+		return t, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cassette file
+	if err = json.Unmarshal(raw, &cassette); err != nil {
+		return nil, err
+	}
+	t.interactions = cassette.Interactions
+	t.used = make([]bool, len(cassette.Interactions))
+	return t, nil
+}
+
+// Client returns an http.Client that uses the transport, to pass to proxmox.NewClient.
+func (t *Transport) Client() *http.Client { return &http.Client{Transport: t} }
+
+// Interactions returns a copy of the interactions recorded or loaded so far.
+func (t *Transport) Interactions() []Interaction {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	interactions := make([]Interaction, len(t.interactions))
+	copy(interactions, t.interactions)
+	return interactions
+}
+
+// Unused returns the interactions that have not been replayed yet, useful to assert all were used at the end of a test.
+func (t *Transport) Unused() []Interaction {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	var unused []Interaction
+	for i := range t.interactions {
+		if !t.used[i] {
+			unused = append(unused, t.interactions[i])
+		}
+	}
+	return unused
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, body, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.mode == ModeRecord {
+		return t.record(req, recorded, body)
+	}
+	return t.replay(req, recorded)
+}
+
+func (t *Transport) record(req *http.Request, recorded Request, reqBody []byte) (*http.Response, error) {
+	if reqBody != nil {
+		// the body of the original request has been consumed
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	header := redact.Header(resp.Header)
+	header.Del("Date")
+	header.Del("Content-Length") // the body might be scrubbed
+	t.mutex.Lock()
+	t.interactions = append(t.interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			Status: resp.StatusCode,
+			Header: header,
+			Body:   string(redact.Body(resp.Header.Get("Content-Type"), body))}})
+	t.used = append(t.used, true)
+	t.mutex.Unlock()
+	return resp, nil
+}
+
+func (t *Transport) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	index := -1
+	switch t.matching {
+	case MatchStrict:
+		if t.position >= len(t.interactions) {
+			return nil, errors.New(Transport_Error_NoInteraction + recorded.String())
+		}
+		if t.interactions[t.position].Request != recorded {
+			return nil, errors.New(Transport_Error_Mismatch + recorded.String() + " expected: " + t.interactions[t.position].Request.String())
+		}
+		index = t.position
+		t.position++
+	case MatchLenient:
+		for i := range t.interactions {
+			if !t.used[i] && t.interactions[i].Request.Method == recorded.Method && t.interactions[i].Request.Path == recorded.Path {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return nil, errors.New(Transport_Error_NoInteraction + recorded.String())
+		}
+	}
+	t.used[index] = true
+	response := t.interactions[index].Response
+	return &http.Response{
+		Status:        strconv.Itoa(response.Status) + " " + http.StatusText(response.Status),
+		StatusCode:    response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader([]byte(response.Body))),
+		ContentLength: int64(len(response.Body)),
+		Request:       req}, nil
+}
+
+// Save writes the recorded interactions to the cassette file.
+func (t *Transport) Save() error {
+	if t.mode != ModeRecord {
+		return errors.New(Transport_Error_NotRecording)
+	}
+	t.mutex.Lock()
+	raw, err := json.MarshalIndent(file{Interactions: t.interactions}, "", "  ")
+	t.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, append(raw, '\n'), 0o644)
+}
+
+// newRequest converts the request to its recorded form, with all secrets scrubbed.
+// Also returns the unscrubbed body, as reading it consumes the body of the request.
+func newRequest(req *http.Request) (Request, []byte, error) {
+	recorded := Request{
+		Method: req.Method,
+		Path:   req.URL.EscapedPath(),
+		Query:  redact.Values(req.URL.Query()).Encode()}
+	if req.Body == nil || req.Body == http.NoBody {
+		return recorded, nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return Request{}, nil, err
+	}
+	recorded.Body = string(redact.Body(req.Header.Get("Content-Type"), body))
+	return recorded, body, nil
+}
@@ -0,0 +1,126 @@
+package cassette_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Telmate/proxmox-api-go/cassette"
+	"github.com/Telmate/proxmox-api-go/proxmox"
+	"github.com/stretchr/testify/require"
+)
+
+const apiUrl = "https://pve.example.com:8006/api2/json"
+
+func record(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api2/json/access/ticket":
+			issued := strconv.FormatInt(time.Now().Unix(), 16)
+			w.Write([]byte(`{"data":{"ticket":"PVE:root@pam:` + issued + `::secret","CSRFPreventionToken":"` + issued + `:csrf","username":"root@pam"}}`))
+		case "GET /api2/json/version":
+			w.Write([]byte(`{"data":{"version":"8.4.1","release":"8.4","repoid":"c4d7d1d6"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	transport, err := cassette.New(path, cassette.ModeRecord, cassette.MatchStrict, nil)
+	require.NoError(t, err)
+	c, err := proxmox.NewClient(server.URL+"/api2/json", transport.Client(), "", nil, "", 300, false)
+	require.NoError(t, err)
+	require.NoError(t, c.Login(context.Background(), "root@pam", "password", ""))
+	_, err = c.GetVersion(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, transport.Save())
+	return path
+}
+
+func Test_Transport(t *testing.T) {
+	t.Parallel()
+	path := record(t)
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "secret")
+	require.NotContains(t, string(raw), "csrf")
+	require.NotContains(t, string(raw), "password=password")
+	t.Run(`Replay strict`, func(t *testing.T) {
+		t.Parallel()
+		transport, err := cassette.New(path, cassette.ModeReplay, cassette.MatchStrict, nil)
+		require.NoError(t, err)
+		c, err := proxmox.NewClient(apiUrl, transport.Client(), "", nil, "", 300, false)
+		require.NoError(t, err)
+		require.NoError(t, c.Login(context.Background(), "root@pam", "other password", ""))
+		version, err := c.GetVersion(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, proxmox.Version{Major: 8, Minor: 4, Patch: 1}, version)
+		require.Empty(t, transport.Unused())
+	})
+	t.Run(`Replay strict out of order`, func(t *testing.T) {
+		t.Parallel()
+		transport, err := cassette.New(path, cassette.ModeReplay, cassette.MatchStrict, nil)
+		require.NoError(t, err)
+		c, err := proxmox.NewClient(apiUrl, transport.Client(), "", nil, "", 300, false)
+		require.NoError(t, err)
+		require.NoError(t, c.SetRetryPolicy(proxmox.RetryPolicyExponential{Attempts: 1}))
+		_, err = c.GetVersion(context.Background())
+		require.Error(t, err)
+		require.Len(t, transport.Unused(), 2)
+	})
+	t.Run(`Replay lenient out of order`, func(t *testing.T) {
+		t.Parallel()
+		transport, err := cassette.New(path, cassette.ModeReplay, cassette.MatchLenient, nil)
+		require.NoError(t, err)
+		c, err := proxmox.NewClient(apiUrl, transport.Client(), "", nil, "", 300, false)
+		require.NoError(t, err)
+		_, err = c.GetVersion(context.Background())
+		require.NoError(t, err)
+		require.Len(t, transport.Unused(), 1)
+	})
+	t.Run(`Save while replaying`, func(t *testing.T) {
+		t.Parallel()
+		transport, err := cassette.New(path, cassette.ModeReplay, cassette.MatchLenient, nil)
+		require.NoError(t, err)
+		require.Equal(t, errors.New(cassette.Transport_Error_NotRecording), transport.Save())
+	})
+}
+
+func Test_Transport_ApiTokenSecret(t *testing.T) {
+	t.Parallel()
+	const secret = "0e3a4c6e-2f5b-4b8e-9a1d-7c6f5e4d3b2a"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api2/json/access/ticket":
+			w.Write([]byte(`{"data":{"ticket":"PVE:root@pam:` + strconv.FormatInt(time.Now().Unix(), 16) + `::signature","CSRFPreventionToken":"csrf","username":"root@pam"}}`))
+		case "POST /api2/json/access/users/test@pve/token/testToken":
+			w.Write([]byte(`{"data":{"full-tokenid":"test@pve!testToken","info":{"privsep":1},"value":"` + secret + `"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	transport, err := cassette.New(path, cassette.ModeRecord, cassette.MatchStrict, nil)
+	require.NoError(t, err)
+	c, err := proxmox.NewClient(server.URL+"/api2/json", transport.Client(), "", nil, "", 300, false)
+	require.NoError(t, err)
+	require.NoError(t, c.Login(context.Background(), "root@pam", "password", ""))
+	tokenSecret, err := c.New().ApiToken.Create(context.Background(), proxmox.UserID{Name: "test", Realm: "pve"}, proxmox.ApiTokenConfig{Name: "testToken"})
+	require.NoError(t, err)
+	require.Equal(t, proxmox.ApiTokenSecret(secret), tokenSecret)
+	require.NoError(t, transport.Save())
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "test@pve!testToken")
+	require.NotContains(t, string(raw), secret)
+}
@@ -26,6 +26,7 @@ func init() {
 	RootCmd.PersistentFlags().IntP("timeout", "t", 300, "api task timeout in seconds")
 	RootCmd.PersistentFlags().StringP("file", "f", "", "file to get the config from")
 	RootCmd.PersistentFlags().StringP("proxyurl", "p", "", "proxy url to connect to")
+	RootCmd.PersistentFlags().String("profile", "", "profile to connect with, the current profile is used when PM_API_URL is not set")
 }
 
 func Context() context.Context {
@@ -63,6 +64,12 @@ func Client(ctx context.Context, apiUrl, userID, password, otp string, http_head
 	insecure, _ := RootCmd.Flags().GetBool("insecure")
 	timeout, _ := RootCmd.Flags().GetInt("timeout")
 	proxyUrl, _ := RootCmd.Flags().GetString("proxyurl")
+	profile, _ := RootCmd.Flags().GetString("profile")
+
+	// The profile holds all connection settings, so the other flags don't apply.
+	if profile != "" || (apiUrl == "" && os.Getenv("PM_API_URL") == "") {
+		return proxmox.NewClientFromProfile(ctx, "", profile)
+	}
 
 	tlsConf := &tls.Config{InsecureSkipVerify: true}
 	if !insecure {
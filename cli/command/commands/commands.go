@@ -1,6 +1,7 @@
 package commands
 
 import (
+	_ "github.com/Telmate/proxmox-api-go/cli/command/config"
 	_ "github.com/Telmate/proxmox-api-go/cli/command/content"
 	_ "github.com/Telmate/proxmox-api-go/cli/command/content/iso"
 	_ "github.com/Telmate/proxmox-api-go/cli/command/content/template"
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"github.com/Telmate/proxmox-api-go/cli"
+	"github.com/Telmate/proxmox-api-go/proxmox"
+	"github.com/spf13/cobra"
+)
+
+type profileItem struct {
+	Name    string   `json:"name"`
+	Current bool     `json:"current,omitempty"`
+	ApiUrls []string `json:"api_urls"`
+}
+
+var config_listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Prints a list of profiles in raw json format, credentials are omitted",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := proxmox.LoadProfiles("")
+		if err != nil {
+			return err
+		}
+		names := profiles.Names()
+		items := make([]profileItem, len(names))
+		for i := range names {
+			items[i] = profileItem{
+				Name:    names[i],
+				Current: names[i] == profiles.Current,
+				ApiUrls: profiles.Profiles[names[i]].ApiUrls}
+		}
+		cli.PrintFormattedJson(configCmd.OutOrStdout(), items)
+		return nil
+	}}
+
+func init() { configCmd.AddCommand(config_listCmd) }
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/Telmate/proxmox-api-go/cli"
+	"github.com/Telmate/proxmox-api-go/proxmox"
+	"github.com/spf13/cobra"
+)
+
+var config_setCmd = &cobra.Command{
+	Use:   "set PROFILE",
+	Short: "Sets the configuration of the specified profile",
+	Long: `Sets the configuration of the specified profile.
+Depending on if the profile already exists the profile will be created or replaced.
+The first profile becomes the current profile.
+The config can be set with the --file flag or piped from stdin.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := cli.RequiredIDset(args, 0, "Profile")
+		var profile proxmox.Profile
+		if err := json.Unmarshal(cli.NewConfig(), &profile); err != nil {
+			return err
+		}
+		profiles, err := proxmox.LoadProfiles("")
+		if err != nil {
+			return err
+		}
+		if err = profiles.Set(name, profile); err != nil {
+			return err
+		}
+		if err = profiles.Save(""); err != nil {
+			return err
+		}
+		cli.PrintItemSet(configCmd.OutOrStdout(), name, "Profile")
+		return nil
+	}}
+
+func init() { configCmd.AddCommand(config_setCmd) }
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/Telmate/proxmox-api-go/cli"
+	"github.com/Telmate/proxmox-api-go/proxmox"
+	"github.com/spf13/cobra"
+)
+
+var config_useCmd = &cobra.Command{
+	Use:   "use PROFILE",
+	Short: "Makes the specified profile the current profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := cli.RequiredIDset(args, 0, "Profile")
+		profiles, err := proxmox.LoadProfiles("")
+		if err != nil {
+			return err
+		}
+		if err = profiles.Use(name); err != nil {
+			return err
+		}
+		if err = profiles.Save(""); err != nil {
+			return err
+		}
+		fmt.Fprintf(configCmd.OutOrStdout(), "Profile (%s) is now the current profile\n", name)
+		return nil
+	}}
+
+func init() { configCmd.AddCommand(config_useCmd) }
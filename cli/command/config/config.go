@@ -0,0 +1,17 @@
+package config
+
+import (
+	"github.com/Telmate/proxmox-api-go/cli"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manages the profiles used to connect to proxmox",
+	Long: `Manages the profiles used to connect to proxmox.
+Profiles are stored in the file set by PM_PROFILES, or in the user's config directory.`,
+}
+
+func init() {
+	cli.RootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,82 @@
+package guest
+
+import (
+	"strconv"
+
+	"github.com/Telmate/proxmox-api-go/cli"
+	"github.com/Telmate/proxmox-api-go/proxmox"
+	"github.com/spf13/cobra"
+)
+
+var guest_batchCmd = &cobra.Command{
+	Use:   "batch ACTION GUESTID...",
+	Short: "Performs the same action on multiple guests",
+	Long: `Performs the same action on multiple guests at the same time.
+ACTION is one of: migrate, reboot, shutdown, snapshot, start, tags.
+Prints the result of each guest in raw json format.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		batch := proxmox.GuestBatch{Action: proxmox.GuestBatchAction(args[0])}
+		batch.Concurrency, _ = flags.GetUint("concurrency")
+		batch.FailFast, _ = flags.GetBool("fail-fast")
+		batch.Timeout, _ = flags.GetDuration("guest-timeout")
+		switch batch.Action {
+		case proxmox.GuestBatchAction_Migrate:
+			node, _ := flags.GetString("node")
+			online, _ := flags.GetBool("online")
+			batch.Migrate = &proxmox.GuestBatchMigrate{Node: proxmox.NodeName(node), Online: online}
+		case proxmox.GuestBatchAction_Snapshot:
+			name, _ := flags.GetString("name")
+			description, _ := flags.GetString("description")
+			vmState, _ := flags.GetBool("vmstate")
+			batch.Snapshot = &proxmox.GuestBatchSnapshot{Name: proxmox.SnapshotName(name), Description: description, VmState: vmState}
+		case proxmox.GuestBatchAction_Tags:
+			add, _ := flags.GetStringSlice("add")
+			remove, _ := flags.GetStringSlice("remove")
+			batch.Tags = &proxmox.GuestBatchTags{Add: toTags(add), Remove: toTags(remove)}
+		}
+		guests := make([]proxmox.VmRef, len(args)-1)
+		for i, arg := range args[1:] {
+			id, err := strconv.ParseUint(arg, 10, 32)
+			if err != nil {
+				return err
+			}
+			guests[i] = *proxmox.NewVmRef(proxmox.GuestID(id))
+		}
+		result, err := cli.NewClient().New().Guest.Batch(cli.Context(), guests, batch)
+		if result != nil {
+			output := make(map[proxmox.GuestID]string, len(result))
+			for id, guestErr := range result {
+				output[id] = "ok"
+				if guestErr != nil {
+					output[id] = guestErr.Error()
+				}
+			}
+			cli.PrintFormattedJson(GuestCmd.OutOrStdout(), output)
+		}
+		return err
+	},
+}
+
+func toTags(tags []string) proxmox.Tags {
+	typed := make(proxmox.Tags, len(tags))
+	for i := range tags {
+		typed[i] = proxmox.Tag(tags[i])
+	}
+	return typed
+}
+
+func init() {
+	GuestCmd.AddCommand(guest_batchCmd)
+	guest_batchCmd.Flags().Uint("concurrency", 4, "number of guests processed at the same time")
+	guest_batchCmd.Flags().Bool("fail-fast", false, "skip the remaining guests after the first failure")
+	guest_batchCmd.Flags().Duration("guest-timeout", 0, "maximum duration of the action on a single guest, e.g. 5m. The task keeps running in Proxmox VE when it expires")
+	guest_batchCmd.Flags().String("node", "", "migrate: node to migrate the guests to")
+	guest_batchCmd.Flags().Bool("online", false, "migrate: live migrate running guests")
+	guest_batchCmd.Flags().String("name", "", "snapshot: name of the snapshot")
+	guest_batchCmd.Flags().String("description", "", "snapshot: description of the snapshot")
+	guest_batchCmd.Flags().Bool("vmstate", false, "snapshot: include the memory of qemu guests")
+	guest_batchCmd.Flags().StringSlice("add", nil, "tags: tags to add")
+	guest_batchCmd.Flags().StringSlice("remove", nil, "tags: tags to remove")
+}
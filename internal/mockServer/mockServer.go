@@ -8,6 +8,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"math/big"
+	"net"
 	"net/http"
 	"testing"
 	"time"
@@ -33,10 +34,20 @@ func (p Path) String() string { return string(p) }
 func New(t *testing.T) *Server {
 
 	server := Server{
-		config: &config{},
+		config:   &config{},
+		listener: NewListener(t),
 	}
+	go func() { // http.Serve blocks, but only this goroutine will wait
+		_ = http.Serve(server.listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			server.config.handle(w, r)
+		}))
+	}()
+
+	return &server
+}
 
-	// --- HTTPS part ---
+// NewListener returns a TLS listener on a random local port, with a self-signed certificate.
+func NewListener(t *testing.T) net.Listener {
 	certPEM, keyPEM, err := generateSelfSignedCert()
 	if err != nil {
 		assert.FailNow(t, "Failed to generate TLS certificate: "+err.Error())
@@ -48,17 +59,11 @@ func New(t *testing.T) *Server {
 		assert.FailNow(t, "Failed to load TLS key pair: "+err.Error())
 	}
 
-	server.listener, err = tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
 	if err != nil {
 		assert.FailNow(t, "Uncaught error starting mock server: "+err.Error())
 	}
-	go func() { // http.Serve blocks, but only this goroutine will wait
-		_ = http.Serve(server.listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			server.config.handle(w, r)
-		}))
-	}()
-
-	return &server
+	return listener
 }
 
 func generateSelfSignedCert() (certPEM []byte, keyPEM []byte, err error) {
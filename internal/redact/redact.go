@@ -0,0 +1,97 @@
+// Package redact removes secrets from HTTP headers and bodies before they are logged or stored.
+package redact
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+)
+
+const Redacted = "[REDACTED]"
+
+// Headers that are always redacted.
+var headers = []string{"Authorization", "Cookie", "CSRFPreventionToken", "Set-Cookie"}
+
+// Parameters and JSON keys that are always redacted.
+var fields = map[string]struct{}{
+	"CSRFPreventionToken": {},
+	"cipassword":          {},
+	"keyring":             {},
+	"otp":                 {},
+	"password":            {},
+	"ticket":              {},
+	"token":               {},
+}
+
+// The secret of a newly created API token is in the value key, which is only redacted next to the full-tokenid key of the creation response.
+const (
+	tokenKeyID     = "full-tokenid"
+	tokenKeySecret = "value"
+)
+
+// Header returns a copy of the header with all secrets redacted.
+func Header(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range headers {
+		if header.Get(key) != "" {
+			header.Set(key, Redacted)
+		}
+	}
+	return header
+}
+
+// Values returns a copy of the values with all secrets redacted.
+func Values(values url.Values) url.Values {
+	redacted := make(url.Values, len(values))
+	for key, value := range values {
+		if _, isSet := fields[key]; isSet {
+			value = []string{Redacted}
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// Body redacts the secrets in form encoded and JSON bodies, other bodies are returned as is.
+func Body(contentType string, body []byte) []byte {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return body
+		}
+		return []byte(Values(values).Encode())
+	case "application/json":
+		var data any
+		if json.Unmarshal(body, &data) != nil {
+			return body
+		}
+		redacted, err := json.Marshal(jsonData(data))
+		if err != nil {
+			return body
+		}
+		return redacted
+	}
+	return body
+}
+
+func jsonData(data any) any {
+	switch data := data.(type) {
+	case map[string]any:
+		_, isToken := data[tokenKeyID]
+		for key, value := range data {
+			if _, isSet := fields[key]; isSet || (isToken && key == tokenKeySecret) {
+				data[key] = Redacted
+			} else {
+				data[key] = jsonData(value)
+			}
+		}
+	case []any:
+		for i := range data {
+			data[i] = jsonData(data[i])
+		}
+	}
+	return data
+}
@@ -0,0 +1,67 @@
+package redact
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Body(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		contentType string
+		input       string
+		output      string
+	}{
+		{name: `Form`,
+			contentType: "application/x-www-form-urlencoded",
+			input:       "cipassword=secret&name=test",
+			output:      "cipassword=%5BREDACTED%5D&name=test"},
+		{name: `JSON nested`,
+			contentType: "application/json;charset=UTF-8",
+			input:       `{"data":[{"token":"secret","type":"influxdb"}]}`,
+			output:      `{"data":[{"token":"[REDACTED]","type":"influxdb"}]}`},
+		{name: `JSON API token secret`,
+			contentType: "application/json;charset=utf-8",
+			input:       `{"data":{"full-tokenid":"root@pam!test","info":{"privsep":1},"value":"0e3a4c6e-2f5b-4b8e-9a1d-7c6f5e4d3b2a"}}`,
+			output:      `{"data":{"full-tokenid":"root@pam!test","info":{"privsep":1},"value":"[REDACTED]"}}`},
+		{name: `JSON pending value`,
+			contentType: "application/json",
+			input:       `{"data":[{"key":"name","pending":"new","value":"old"}]}`,
+			output:      `{"data":[{"key":"name","pending":"new","value":"old"}]}`},
+		{name: `JSON invalid`,
+			contentType: "application/json",
+			input:       `{"password":`,
+			output:      `{"password":`},
+		{name: `Other`,
+			contentType: "text/plain",
+			input:       "password=secret",
+			output:      "password=secret"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			require.Equal(t, test.output, string(Body(test.contentType, []byte(test.input))))
+		})
+	}
+}
+
+func Test_Header(t *testing.T) {
+	t.Parallel()
+	input := http.Header{
+		"Authorization": []string{"PVEAPIToken=root@pam!test=secret"},
+		"Content-Type":  []string{"application/json"}}
+	require.Equal(t, http.Header{
+		"Authorization": []string{Redacted},
+		"Content-Type":  []string{"application/json"}}, Header(input))
+	require.Equal(t, "PVEAPIToken=root@pam!test=secret", input.Get("Authorization"))
+}
+
+func Test_Values(t *testing.T) {
+	t.Parallel()
+	input := url.Values{"otp": []string{"123456"}, "username": []string{"root@pam"}}
+	require.Equal(t, url.Values{"otp": []string{Redacted}, "username": []string{"root@pam"}}, Values(input))
+	require.Equal(t, "123456", input.Get("otp"))
+}
@@ -95,12 +95,19 @@ func main() {
 	taskTimeout := flag.Int("timeout", 300, "API task timeout in seconds")
 	proxyURL := flag.String("proxy", "", "proxy URL to connect to")
 	fvmid := flag.Int("vmid", -1, "custom VMID (instead of auto)")
+	profile := flag.String("profile", "", "profile to connect with, replaces the PM_* environment variables")
 	flag.Parse()
 
 	ctx := context.Background()
 
 	// Initialize Proxmox client
-	c, err := initializeProxmoxClient(ctx, config, *insecure, *proxyURL, *taskTimeout, *debug)
+	var c *proxmox.Client
+	var err error
+	if *profile != "" {
+		c, err = proxmox.NewClientFromProfile(ctx, "", *profile)
+	} else {
+		c, err = initializeProxmoxClient(ctx, config, *insecure, *proxyURL, *taskTimeout, *debug)
+	}
 	if err != nil {
 		log.Fatalf("Failed to initialize Proxmox client: %v", err)
 	}
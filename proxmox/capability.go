@@ -0,0 +1,75 @@
+package proxmox
+
+import (
+	"errors"
+	"maps"
+	"slices"
+)
+
+// Capability is functionality that is only available from a certain Proxmox VE version onwards.
+type Capability string // Enum
+
+const (
+	CapabilityCloudInitUpgradePackages Capability = "cloud-init-upgrade-packages"
+	CapabilityForceStop                Capability = "force-stop" // Stopping a guest immediately, cancelling any stop/shutdown operations in progress.
+	CapabilityHaRules                  Capability = "ha-rules"
+	CapabilityMachineVIOMMU            Capability = "machine-viommu"
+	CapabilityNestedPools              Capability = "nested-pools"      // Pools inside of other pools, e.g. `parent/child`.
+	CapabilityOverruleShutdown         Capability = "overrule-shutdown" // Stopping a guest while a shutdown is in progress.
+	CapabilityPoolAllowMove            Capability = "pool-allow-move"   // Moving guests between pools in a single call.
+	CapabilitySdnFabrics               Capability = "sdn-fabrics"
+	CapabilityVgaClipboard             Capability = "vga-clipboard"
+	CapabilityVirtioFS                 Capability = "virtiofs"
+)
+
+const Capability_Error_Invalid = "invalid capability"
+
+// capabilities holds the first Proxmox VE version that supports each capability.
+var capabilities = map[Capability]Version{
+	CapabilityCloudInitUpgradePackages: {Major: 8},
+	CapabilityForceStop:                {Major: 8},
+	CapabilityHaRules:                  {Major: 9},
+	CapabilityMachineVIOMMU:            {Major: 8, Minor: 1},
+	CapabilityNestedPools:              {Major: 8, Minor: 1},
+	CapabilityOverruleShutdown:         {Major: 8},
+	CapabilityPoolAllowMove:            {Major: 8},
+	CapabilitySdnFabrics:               {Major: 9},
+	CapabilityVgaClipboard:             {Major: 8, Minor: 1},
+	CapabilityVirtioFS:                 {Major: 8, Minor: 4}}
+
+// Capabilities returns all known capabilities.
+func Capabilities() []Capability { return slices.Sorted(maps.Keys(capabilities)) }
+
+// check returns an error when the capability is not supported by the version.
+func (c Capability) check(version Version) error {
+	if c.SupportedBy(version) {
+		return nil
+	}
+	return c.error(version)
+}
+
+func (c Capability) error(version Version) error {
+	return &functionalityVersionWrapper{
+		err:           errNotSupportedInVersion,
+		functionality: c.String(),
+		minimum:       capabilities[c],
+		version:       version}
+}
+
+// MinimumVersion returns the first Proxmox VE version that supports the capability.
+func (c Capability) MinimumVersion() Version { return capabilities[c] }
+
+func (c Capability) String() string { return string(c) } // String is for fmt.Stringer.
+
+// SupportedBy returns true when the capability is supported by the version.
+func (c Capability) SupportedBy(version Version) bool {
+	minimum, ok := capabilities[c]
+	return ok && !version.Smaller(minimum)
+}
+
+func (c Capability) Validate() error {
+	if _, ok := capabilities[c]; !ok {
+		return errors.New(Capability_Error_Invalid)
+	}
+	return nil
+}
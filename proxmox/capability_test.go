@@ -0,0 +1,104 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Capability_check(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   Capability
+		version Version
+		output  error
+	}{
+		{name: `Valid equal`,
+			input:   CapabilityHaRules,
+			version: Version{Major: 9}},
+		{name: `Valid greater`,
+			input:   CapabilityNestedPools,
+			version: Version{Major: 8, Minor: 2, Patch: 1}},
+		{name: `Invalid minor`,
+			input:   CapabilityVirtioFS,
+			version: Version{Major: 8, Minor: 3, Patch: 5},
+			output: &functionalityVersionWrapper{
+				err:           errNotSupportedInVersion,
+				functionality: "virtiofs",
+				minimum:       Version{Major: 8, Minor: 4},
+				version:       Version{Major: 8, Minor: 3, Patch: 5}}},
+		{name: `Invalid unknown`,
+			input:   "unknown",
+			version: Version{Major: 255},
+			output: &functionalityVersionWrapper{
+				err:           errNotSupportedInVersion,
+				functionality: "unknown",
+				version:       Version{Major: 255}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := test.input.check(test.version)
+			require.Equal(t, test.output, err)
+			if test.output != nil {
+				require.ErrorIs(t, err, Error.FunctionalityNotSupportedInVersion())
+			}
+		})
+	}
+}
+
+func Test_Capability_Error(t *testing.T) {
+	t.Parallel()
+	require.Equal(t,
+		"functionality (ha-rules) requires Proxmox VE >= 9.0, got version (8.4.1)",
+		CapabilityHaRules.error(Version{Major: 8, Minor: 4, Patch: 1}).Error())
+}
+
+func Test_Capability_Validate(t *testing.T) {
+	t.Parallel()
+	for _, capability := range Capabilities() {
+		t.Run(capability.String(), func(t *testing.T) {
+			t.Parallel()
+			require.NoError(t, capability.Validate())
+			require.NotZero(t, capability.MinimumVersion())
+		})
+	}
+	require.Equal(t, errors.New(Capability_Error_Invalid), Capability("").Validate())
+}
+
+func Test_Client_Supports(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    Capability
+		requests []mockServer.Request
+		output   bool
+		err      error
+	}{
+		{name: `Supported`,
+			input:    CapabilityVirtioFS,
+			requests: mockServer.RequestsVersion("8.4.1"),
+			output:   true},
+		{name: `Not supported`,
+			input:    CapabilitySdnFabrics,
+			requests: mockServer.RequestsVersion("8.4.1")},
+		{name: `Invalid`,
+			input: "invalid",
+			err:   errors.New(Capability_Error_Invalid)},
+	}
+	server, c := testMockServerInit(t)
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			c.clearVersion()
+			server.Set(test.requests, t)
+			supported, err := c.Supports(context.Background(), test.input)
+			require.Equal(t, test.err, err)
+			require.Equal(t, test.output, supported)
+			server.Clear(t)
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -39,6 +40,7 @@ type Client struct {
 	version            *Version
 	versionMutex       sync.Mutex
 	guestCreationMutex sync.Mutex
+	retryPolicy        RetryPolicy
 	timeUnit           time.Duration
 }
 
@@ -69,7 +71,8 @@ const (
 )
 
 const (
-	VmRef_Error_IDnotSet = "vm reference id not set"
+	VmRef_Error_GuestType = "vm reference points to a guest of a different type"
+	VmRef_Error_IDnotSet  = "vm reference id not set"
 )
 
 // VmRef - virtual machine ref parts
@@ -146,6 +149,29 @@ func NewClient(apiUrl string, hclient *http.Client, http_headers string, tls *tl
 	return client, err_s
 }
 
+// NewClientFailover creates a client that is connected to multiple nodes of the same cluster.
+// Requests go to the first url, until it becomes unreachable.
+// Requests that could not connect, and idempotent requests (GET, HEAD and logging in) that got no response,
+// are then sent to the next node that passes a health check, which stays active from then on.
+// Other requests that got no response are never sent again, as the node might have processed them.
+// The authentication ticket is valid on all nodes of the cluster, so it is reused.
+func NewClientFailover(apiUrls []string, hclient *http.Client, http_headers string, tls *tls.Config, proxyString string, taskTimeout int, debug bool) (*Client, error) {
+	if len(apiUrls) == 0 {
+		return nil, errors.New(Failover_Error_NoEndpoints)
+	}
+	client, err := NewClient(apiUrls[0], hclient, http_headers, tls, proxyString, taskTimeout, debug)
+	if err != nil {
+		return nil, err
+	}
+	if err = client.session.setEndpoints(apiUrls); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// ActiveEndpoint returns the api url requests are currently sent to.
+func (c *Client) ActiveEndpoint() string { return c.session.activeEndpoint() }
+
 func (c *Client) api() *clientAPI {
 	var user UserID
 	if c.Username != "" {
@@ -159,6 +185,7 @@ func (c *Client) api() *clientAPI {
 	}
 	return &clientAPI{
 		session:     c.session,
+		retryPolicy: c.retryPolicy,
 		taskTimeout: time.Duration(c.TaskTimeout) * time.Second,
 		timeUnit:    c.timeUnit,
 		url:         c.ApiUrl,
@@ -171,10 +198,13 @@ func (c *Client) New() ClientNew {
 		ApiToken:  &apiTokenClient{oldClient: c, api: apiClientPtr},
 		Group:     &groupClient{oldClient: c, api: apiClientPtr},
 		Guest:     &guestClient{oldClient: c, api: apiClientPtr},
+		LxcGuest:  &lxcGuestClient{oldClient: c, api: apiClientPtr},
 		Pool:      &poolClient{oldClient: c, api: apiClientPtr},
 		QemuGuest: &qemuGuestClient{oldClient: c, api: apiClientPtr},
 		Snapshot:  &snapshotClient{oldClient: c, api: apiClientPtr},
-		User:      &userClient{oldClient: c, api: apiClientPtr}}
+		Task:      &taskClient{oldClient: c, api: apiClientPtr},
+		User:      &userClient{oldClient: c, api: apiClientPtr},
+		Watcher:   &watcherClient{oldClient: c, api: apiClientPtr}}
 }
 
 func (c *Client) new() ClientNewTest {
@@ -192,6 +222,7 @@ func (c *Client) new() ClientNewTest {
 		oldClient: c,
 		api: &clientAPI{
 			session:     c.session,
+			retryPolicy: c.retryPolicy,
 			taskTimeout: time.Duration(c.TaskTimeout) * time.Second,
 			timeUnit:    c.timeUnit,
 			url:         c.ApiUrl,
@@ -220,11 +251,88 @@ func (c *Client) SetTicket(ticket, csrfPreventionToken string) {
 	c.session.setTicket(ticket, csrfPreventionToken)
 }
 
+// SetTicketRenewalHook sets a function that is called after every attempt to renew the authentication ticket.
+// Tickets obtained with `Login` or set with `SetTicket` are renewed automatically before they expire,
+// and when a request is rejected with 401 Unauthorized.
+// The hook may be called from multiple goroutines, but never concurrently.
+func (c *Client) SetTicketRenewalHook(hook func(TicketRenewal)) { c.session.setRenewalHook(hook) }
+
+// SetRetryPolicy sets the policy that decides if, and after how long, failed requests are retried.
+// Setting it to nil restores the default policy, which retries network errors up to `RequestRetryCount` times.
+// Policies that implement `Validate() error`, like RetryPolicyExponential, are validated and rejected when invalid.
+// Must be called before the client is used.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) error {
+	if v, ok := policy.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return err
+		}
+	}
+	c.retryPolicy = policy
+	return nil
+}
+
+// SetLogger sets the logger that receives a structured record for every request and retry.
+// Requests are logged at the debug level, failures and retries at the warn level.
+// Secrets like passwords, tickets and API tokens are redacted.
+// Setting it to nil disables logging.
+func (c *Client) SetLogger(logger *slog.Logger) { c.session.setLogger(logger) }
+
+// SetRequestObserver sets the observer that is notified about every request made by the client.
+// Setting it to nil removes the observer.
+func (c *Client) SetRequestObserver(observer RequestObserver) { c.session.setObserver(observer) }
+
+// SetCache enables caching of read-heavy endpoints, see CacheConfig.
+// The cache is shared by all interfaces of the client.
+// Setting it to the zero value disables the cache, changing it discards all cached entries and statistics.
+func (c *Client) SetCache(config CacheConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	c.session.setCache(config)
+	return nil
+}
+
+// CacheStats returns the statistics of the cache, the zero value when caching is disabled.
+func (c *Client) CacheStats() CacheStats {
+	if cache := c.session.cache.Load(); cache != nil {
+		return cache.statistics()
+	}
+	return CacheStats{}
+}
+
+// ClearCache discards all cached entries.
+func (c *Client) ClearCache() {
+	if cache := c.session.cache.Load(); cache != nil {
+		cache.clear()
+	}
+}
+
+// SetRateLimit limits the rate and concurrency of all requests made by the client.
+// Setting it to the zero value removes the limit.
+func (c *Client) SetRateLimit(limit RateLimit) error {
+	if err := limit.Validate(); err != nil {
+		return err
+	}
+	c.session.setRateLimit(limit)
+	return nil
+}
+
+func (c *Client) getRetryPolicy() RetryPolicy {
+	if c.retryPolicy == nil {
+		return retryPolicyLinear{attempts: RequestRetryCount, timeUnit: c.timeUnit}
+	}
+	return c.retryPolicy
+}
+
 func (c *Client) Login(ctx context.Context, username string, password string, otp string) (err error) {
 	c.Username = username
 	c.Password = password
 	c.Otp = otp
-	return c.session.login(ctx, username, password, otp)
+	if err = c.session.login(ctx, username, password, otp); err != nil {
+		return
+	}
+	c.session.setCredentials(username, password, otp)
+	return
 }
 
 // Updates the client's cached version information and returns it.
@@ -259,20 +367,14 @@ func (c *Client) clearVersion() {
 	c.version = nil
 }
 
+// GetJsonRetryable retries according to the retry policy of the client, making no more than `tries` attempts.
 func (c *Client) GetJsonRetryable(ctx context.Context, url string, data *map[string]any, tries int) error {
-	var err error
-	var retry bool
-	for ii := 0; ii < tries; ii++ {
-		_, retry, err = c.session.getJSON(ctx, url, nil, nil, data)
-		if err == nil {
-			return nil
-		}
-		if !retry {
-			return err
-		}
-		// fmt.Printf("[DEBUG][GetJsonRetryable] Sleeping for %d seconds before asking url %s", ii+1, url)
-		time.Sleep(time.Duration(ii+1) * time.Second)
+	if tries < 1 {
+		tries = 1
 	}
+	_, err := retryRequest(ctx, c.getRetryPolicy(), uint(tries), c.session.logger(), http.MethodGet, url, func() (*http.Response, bool, error) {
+		return c.session.getJSON(ctx, url, nil, nil, data)
+	})
 	return err
 }
 
@@ -349,7 +451,7 @@ func (c *Client) WaitForVmLock(ctx context.Context, vmr *VmRef) error {
 	for {
 		select {
 		case <-timeout:
-			return fmt.Errorf("timed out waiting for VM %d to unlock after migration", vmr.VmId())
+			return timeoutError(fmt.Sprintf("timed out waiting for VM %d to unlock after migration", vmr.VmId()))
 		case <-ticker.C:
 			vmInfo, err := c.GetVmInfo(ctx, vmr)
 			if err != nil {
@@ -574,6 +676,8 @@ func (c *Client) Sendkey(ctx context.Context, vmr *VmRef, qmKey string) error {
 }
 
 // WaitForCompletion - poll the API for task completion
+//
+// Deprecated: use TaskInterface.Wait() instead.
 func (c *Client) WaitForCompletion(ctx context.Context, taskResponse map[string]interface{}) (waitExitStatus string, err error) {
 	if taskResponse["errors"] != nil {
 		errJSON, _ := json.MarshalIndent(taskResponse["errors"], "", "  ")
@@ -582,33 +686,35 @@ func (c *Client) WaitForCompletion(ctx context.Context, taskResponse map[string]
 	if taskResponse["data"] == nil {
 		return "", nil
 	}
-	waited := 0
 	taskUpid := taskResponse["data"].(string)
-	for waited < c.TaskTimeout {
-		exitStatus, statErr := c.GetTaskExitstatus(ctx, taskUpid)
-		if statErr != nil {
-			if statErr != io.ErrUnexpectedEOF { // don't give up on ErrUnexpectedEOF
-				return "", statErr
-			}
-		}
-		if exitStatus != nil {
-			waitExitStatus = exitStatus.(string)
-			return
+	var upid UPID
+	if err = upid.Parse(taskUpid); err != nil {
+		return "", err
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(c.TaskTimeout)*time.Second)
+	defer cancel()
+	raw, err := upid.wait(waitCtx, &clientAPI{session: c.session, retryPolicy: c.retryPolicy, timeUnit: c.timeUnit}, defaultTaskBackoff(c.timeUnit))
+	if raw == nil || !raw.Finished() {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return "", timeoutError("Wait timeout for:" + taskUpid)
 		}
-		time.Sleep(TaskStatusCheckInterval * time.Second)
-		waited = waited + TaskStatusCheckInterval
+		return "", err
 	}
-	return "", errors.New("Wait timeout for:" + taskUpid)
+	if exitStatus := raw.GetExitStatus(); rxExitStatusSuccess.FindString(exitStatus) == "" {
+		return "", errors.New(exitStatus)
+	}
+	return raw.GetExitStatus(), nil
 }
 
-var (
-	rxTaskNode          = regexp.MustCompile("UPID:(.*?):")
-	rxExitStatusSuccess = regexp.MustCompile(`^(OK|WARNINGS)`)
-)
+var rxExitStatusSuccess = regexp.MustCompile(`^(OK|WARNINGS)`)
 
+// Deprecated: use TaskInterface.Read() instead.
 func (c *Client) GetTaskExitstatus(ctx context.Context, taskUpid string) (exitStatus interface{}, err error) {
-	node := rxTaskNode.FindStringSubmatch(taskUpid)[1]
-	url := fmt.Sprintf("/nodes/%s/tasks/%s/status", node, taskUpid)
+	var upid UPID
+	if err = upid.Parse(taskUpid); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("/nodes/%s/tasks/%s/status", upid.Node, taskUpid)
 	var data map[string]interface{}
 	_, _, err = c.session.getJSON(ctx, url, nil, nil, &data)
 	if err == nil {
@@ -2497,6 +2603,18 @@ func (c *Client) Version(ctx context.Context) (Version, error) {
 	}, nil
 }
 
+// Supports returns true when the Proxmox VE version of the cluster supports the capability.
+func (c *Client) Supports(ctx context.Context, capability Capability) (bool, error) {
+	if err := capability.Validate(); err != nil {
+		return false, err
+	}
+	version, err := c.Version(ctx)
+	if err != nil {
+		return false, err
+	}
+	return capability.SupportedBy(version), nil
+}
+
 type Version struct {
 	Major uint8
 	Minor uint8
@@ -2,12 +2,15 @@ package proxmox
 
 import (
 	"context"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // in the future we might put the interface even lower, but for now this is sufficient
 type clientApiInterface interface {
+	cancelTask(ctx context.Context, upid UPID) error
+	createGuestTemplate(ctx context.Context, vmr *VmRef) error
 	createHaRule(ctx context.Context, params map[string]any) error
 	deleteGuest(ctx context.Context, vmr *VmRef, purge bool) error
 	deleteHaResource(ctx context.Context, id GuestID) error
@@ -17,9 +20,13 @@ type clientApiInterface interface {
 	getGuestQemuAgent(ctx context.Context, vmr *VmRef) (map[string]any, GuestAgentState, error)
 	getHaRule(ctx context.Context, id HaRuleID) (map[string]any, error)
 	getPoolConfig(ctx context.Context, pool PoolName) (map[string]any, error)
+	getTaskLog(ctx context.Context, upid UPID, start, limit uint) ([]any, error)
+	getTaskStatus(ctx context.Context, upid UPID) (map[string]any, error)
 	getUserConfig(ctx context.Context, userId UserID) (map[string]any, bool, error)
 	listGuestResources(ctx context.Context) (*rawGuestResources, error)
 	listHaRules(ctx context.Context) ([]any, error)
+	listTasks(ctx context.Context) ([]any, error)
+	taskFinished(upid UPID) // Called once a task has been observed to have finished.
 	updateGuestStatus(ctx context.Context, vmr *VmRef, setStatus string, body *[]byte) error
 	updateHaRule(ctx context.Context, id HaRuleID, params map[string]any) error
 }
@@ -28,19 +35,28 @@ type clientAPI struct {
 	session     *Session
 	url         string
 	user        UserID
+	retryPolicy RetryPolicy
 	taskTimeout time.Duration
 	timeUnit    time.Duration
 }
 
 // Interface methods
 
+func (c *clientAPI) cancelTask(ctx context.Context, upid UPID) error {
+	return c.deleteRetry(ctx, "/nodes/"+upid.Node.String()+"/tasks/"+upid.String())
+}
+
+func (c *clientAPI) createGuestTemplate(ctx context.Context, vmr *VmRef) error {
+	return c.postTask(ctx, "/nodes/"+vmr.node.String()+"/"+vmr.vmType.String()+"/"+vmr.vmId.String()+"/template", nil)
+}
+
 func (c *clientAPI) createHaRule(ctx context.Context, params map[string]any) error {
 	_, err := c.post(ctx, "/cluster/ha/rules", params)
 	return err
 }
 
 func (c *clientAPI) deleteHaResource(ctx context.Context, id GuestID) error {
-	return c.deleteRetry(ctx, "/cluster/ha/resources/"+id.String(), 3)
+	return c.deleteRetry(ctx, "/cluster/ha/resources/"+id.String())
 }
 
 func (c *clientAPI) deleteHaRule(ctx context.Context, id HaRuleID) error {
@@ -84,6 +100,18 @@ func (c *clientAPI) getPoolConfig(ctx context.Context, pool PoolName) (poolConfi
 	return c.getMap(ctx, "/pools/"+string(pool), "pool", "CONFIG")
 }
 
+func (c *clientAPI) getTaskLog(ctx context.Context, upid UPID, start, limit uint) ([]any, error) {
+	url := "/nodes/" + upid.Node.String() + "/tasks/" + upid.String() + "/log?start=" + strconv.FormatUint(uint64(start), 10)
+	if limit != 0 {
+		url += "&limit=" + strconv.FormatUint(uint64(limit), 10)
+	}
+	return c.getList(ctx, url, "task", "LOG")
+}
+
+func (c *clientAPI) getTaskStatus(ctx context.Context, upid UPID) (map[string]any, error) {
+	return c.getMap(ctx, "/nodes/"+upid.Node.String()+"/tasks/"+upid.String()+"/status", "task", "STATUS")
+}
+
 func (c *clientAPI) getUserConfig(ctx context.Context, userID UserID) (map[string]any, bool, error) {
 	config, err := c.getMap(ctx, "/access/users/"+userID.String(), "user", "CONFIG")
 	if err == nil {
@@ -109,6 +137,16 @@ func (c *clientAPI) listHaRules(ctx context.Context) ([]any, error) {
 	return c.getList(ctx, "/cluster/ha/rules", "ha rules", "CONFIG")
 }
 
+func (c *clientAPI) listTasks(ctx context.Context) ([]any, error) {
+	return c.getList(ctx, "/cluster/tasks", "", "")
+}
+
+func (c *clientAPI) taskFinished(upid UPID) {
+	if cache := c.session.cache.Load(); cache != nil {
+		cache.invalidateTask(upid)
+	}
+}
+
 func (c *clientAPI) updateGuestStatus(ctx context.Context, vmr *VmRef, setStatus string, body *[]byte) error {
 	return c.postRawTask(ctx, "/nodes/"+vmr.node.String()+"/"+vmr.vmType.String()+"/"+vmr.vmId.String()+"/status/"+setStatus, body)
 }
@@ -119,5 +157,5 @@ func (c *clientAPI) updateHaRule(ctx context.Context, id HaRuleID, params map[st
 }
 
 func (c clientAPI) updateUser(ctx context.Context, user UserID, body *[]byte) error {
-	return c.putRawRetry(ctx, "/access/users/"+user.String(), body, 3)
+	return c.putRawRetry(ctx, "/access/users/"+user.String(), body)
 }
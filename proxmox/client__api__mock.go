@@ -5,6 +5,8 @@ import (
 )
 
 type mockClientAPI struct {
+	cancelTaskFunc             func(ctx context.Context, upid UPID) error
+	createGuestTemplateFunc    func(ctx context.Context, vmr *VmRef) error
 	createHaRuleFunc           func(ctx context.Context, params map[string]any) error
 	deleteGuestFunc            func(ctx context.Context, vmr *VmRef, purge bool) error
 	deleteHaResourceFunc       func(ctx context.Context, id GuestID) error
@@ -14,9 +16,12 @@ type mockClientAPI struct {
 	getGuestQemuAgentFunc      func(ctx context.Context, vmr *VmRef) (map[string]any, GuestAgentState, error)
 	getHaRuleFunc              func(ctx context.Context, id HaRuleID) (map[string]any, error)
 	getPoolConfigFunc          func(ctx context.Context, pool PoolName) (map[string]any, error)
+	getTaskLogFunc             func(ctx context.Context, upid UPID, start, limit uint) ([]any, error)
+	getTaskStatusFunc          func(ctx context.Context, upid UPID) (map[string]any, error)
 	getUserConfigFunc          func(ctx context.Context, userId UserID) (map[string]any, bool, error)
 	listGuestResourcesFunc     func(ctx context.Context) (*rawGuestResources, error)
 	listHaRulesFunc            func(ctx context.Context) ([]any, error)
+	listTasksFunc              func(ctx context.Context) ([]any, error)
 	updateGuestStatusFunc      func(ctx context.Context, vmr *VmRef, setStatus string, body *[]byte) error
 	updateHaRuleFunc           func(ctx context.Context, id HaRuleID, params map[string]any) error
 }
@@ -27,6 +32,20 @@ func (m *mockClientAPI) panic(field string) { panic(field + " not set in mockCli
 
 // Interface methods
 
+func (m *mockClientAPI) cancelTask(ctx context.Context, upid UPID) error {
+	if m.cancelTaskFunc == nil {
+		m.panic("cancelTaskFunc")
+	}
+	return m.cancelTaskFunc(ctx, upid)
+}
+
+func (m *mockClientAPI) createGuestTemplate(ctx context.Context, vmr *VmRef) error {
+	if m.createGuestTemplateFunc == nil {
+		m.panic("createGuestTemplateFunc")
+	}
+	return m.createGuestTemplateFunc(ctx, vmr)
+}
+
 func (m *mockClientAPI) createHaRule(ctx context.Context, params map[string]any) error {
 	if m.createHaRuleFunc == nil {
 		m.panic("createHaRuleFunc")
@@ -90,6 +109,20 @@ func (m *mockClientAPI) getPoolConfig(ctx context.Context, pool PoolName) (poolC
 	return m.getPoolConfigFunc(ctx, pool)
 }
 
+func (m *mockClientAPI) getTaskLog(ctx context.Context, upid UPID, start, limit uint) ([]any, error) {
+	if m.getTaskLogFunc == nil {
+		m.panic("getTaskLogFunc")
+	}
+	return m.getTaskLogFunc(ctx, upid, start, limit)
+}
+
+func (m *mockClientAPI) getTaskStatus(ctx context.Context, upid UPID) (map[string]any, error) {
+	if m.getTaskStatusFunc == nil {
+		m.panic("getTaskStatusFunc")
+	}
+	return m.getTaskStatusFunc(ctx, upid)
+}
+
 func (m *mockClientAPI) getUserConfig(ctx context.Context, userId UserID) (map[string]any, bool, error) {
 	if m.getUserConfigFunc == nil {
 		m.panic("getUserConfigFunc")
@@ -111,6 +144,16 @@ func (m *mockClientAPI) listHaRules(ctx context.Context) ([]any, error) {
 	return m.listHaRulesFunc(ctx)
 }
 
+func (m *mockClientAPI) listTasks(ctx context.Context) ([]any, error) {
+	if m.listTasksFunc == nil {
+		m.panic("listTasksFunc")
+	}
+	return m.listTasksFunc(ctx)
+}
+
+// taskFinished only notifies, so it does not have to be set.
+func (m *mockClientAPI) taskFinished(UPID) {}
+
 func (m *mockClientAPI) updateGuestStatus(ctx context.Context, vmr *VmRef, setStatus string, body *[]byte) error {
 	if m.updateGuestStatusFunc == nil {
 		m.panic("updateGuestStatusFunc")
@@ -5,16 +5,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
-	"time"
 )
 
 // Reusable low-level API methods
 
+// RequestRetryCount is the default amount of attempts made for requests that are retried.
 const RequestRetryCount = 3
 
+// retry executes the request according to the configured retry policy.
+func (c *clientAPI) retry(ctx context.Context, method, url string, request func() (*http.Response, bool, error)) (*http.Response, error) {
+	policy := c.retryPolicy
+	if policy == nil {
+		policy = retryPolicyLinear{attempts: RequestRetryCount, timeUnit: c.timeUnit}
+	}
+	return retryRequest(ctx, policy, 0, c.session.logger(), method, url, request)
+}
+
 func (c *clientAPI) getResourceList(ctx context.Context, resourceType string) ([]any, error) {
 	var url strings.Builder
 	url.WriteString("/cluster/resources")
@@ -34,34 +42,19 @@ func (c *clientAPI) delete(ctx context.Context, url string) (retry bool, err err
 	return
 }
 
-func (c *clientAPI) deleteRetry(ctx context.Context, url string, tries int) (err error) {
-	var retry bool
-	for i := range time.Duration(tries) {
-		_, retry, err = c.session.delete(ctx, url, nil, nil)
-		if err == nil {
-			return nil
-		}
-		if !retry {
-			return
-		}
-		time.Sleep((i + 1) * c.timeUnit)
-	}
-	return
+func (c *clientAPI) deleteRetry(ctx context.Context, url string) error {
+	_, err := c.retry(ctx, http.MethodDelete, url, func() (*http.Response, bool, error) {
+		return c.session.delete(ctx, url, nil, nil)
+	})
+	return err
 }
 
 // Makes a DELETE request and waits on proxmox for the task to complete.
 // It returns the HTTP error as 'err'.
 func (c *clientAPI) deleteTask(ctx context.Context, url string) error {
-	var response *http.Response
-	var retry bool
-	var err error
-	for i := range time.Duration(RequestRetryCount) {
-		response, retry, err = c.session.delete(ctx, url, nil, nil)
-		if err == nil || !retry {
-			break
-		}
-		time.Sleep((i + 1) * c.timeUnit)
-	}
+	response, err := c.retry(ctx, http.MethodDelete, url, func() (*http.Response, bool, error) {
+		return c.session.delete(ctx, url, nil, nil)
+	})
 	if err != nil {
 		return err
 	}
@@ -90,7 +83,7 @@ func (c *clientAPI) getList(ctx context.Context, url, text, message string) ([]a
 
 func (c *clientAPI) getRootMap(ctx context.Context, url, text, message string) (map[string]any, error) {
 	var config map[string]any
-	if err := c.getJsonRetry(ctx, url, &config, 3); err != nil {
+	if err := c.getJsonRetry(ctx, url, &config); err != nil {
 		return nil, err
 	}
 	if config["data"] == nil {
@@ -101,7 +94,7 @@ func (c *clientAPI) getRootMap(ctx context.Context, url, text, message string) (
 
 func (c *clientAPI) getRootList(ctx context.Context, url, text, message string) (map[string]any, error) {
 	var data map[string]any
-	if err := c.getJsonRetry(ctx, url, &data, 3); err != nil {
+	if err := c.getJsonRetry(ctx, url, &data); err != nil {
 		return nil, err
 	}
 	if data["data"] == nil {
@@ -127,7 +120,7 @@ func (c *clientAPI) postMap(ctx context.Context, url string, body *[]byte, text,
 }
 
 func (c *clientAPI) postRootMap(ctx context.Context, url string, body *[]byte, text, message string) (map[string]any, error) {
-	config, err := c.postJsonRetry(ctx, url, body, 3)
+	config, err := c.postJsonRetry(ctx, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -137,33 +130,17 @@ func (c *clientAPI) postRootMap(ctx context.Context, url string, body *[]byte, t
 	return config, nil
 }
 
-func (c *clientAPI) postRawRetry(ctx context.Context, url string, body *[]byte, tries int) (err error) {
-	var retry bool
-	for i := range time.Duration(tries) {
-		_, retry, err = c.session.post(ctx, url, nil, nil, body)
-		if err == nil {
-			return
-		}
-		if !retry {
-			return
-		}
-		time.Sleep((i + 1) * c.timeUnit)
-	}
-	return
+func (c *clientAPI) postRawRetry(ctx context.Context, url string, body *[]byte) error {
+	_, err := c.retry(ctx, http.MethodPost, url, func() (*http.Response, bool, error) {
+		return c.session.post(ctx, url, nil, nil, body)
+	})
+	return err
 }
 
-func (c *clientAPI) postJsonRetry(ctx context.Context, url string, body *[]byte, tries int) (response map[string]any, err error) {
-	var retry bool
-	for i := range time.Duration(tries) {
-		_, retry, err = c.session.postJSON(ctx, url, nil, nil, body, &response)
-		if err == nil {
-			return
-		}
-		if !retry {
-			return
-		}
-		time.Sleep((i + 1) * c.timeUnit)
-	}
+func (c *clientAPI) postJsonRetry(ctx context.Context, url string, body *[]byte) (response map[string]any, err error) {
+	_, err = c.retry(ctx, http.MethodPost, url, func() (*http.Response, bool, error) {
+		return c.session.postJSON(ctx, url, nil, nil, body, &response)
+	})
 	return
 }
 
@@ -177,16 +154,9 @@ func (c *clientAPI) postTask(ctx context.Context, url string, params map[string]
 }
 
 func (c *clientAPI) postRawTask(ctx context.Context, url string, body *[]byte) error {
-	var response *http.Response
-	var retry bool
-	var err error
-	for i := range time.Duration(RequestRetryCount) {
-		response, retry, err = c.session.post(ctx, url, nil, nil, body)
-		if err == nil || !retry {
-			break
-		}
-		time.Sleep((i + 1) * c.timeUnit)
-	}
+	response, err := c.retry(ctx, http.MethodPost, url, func() (*http.Response, bool, error) {
+		return c.session.post(ctx, url, nil, nil, body)
+	})
 	if err != nil {
 		return err
 	}
@@ -201,19 +171,11 @@ func (c *clientAPI) put(ctx context.Context, url string, params map[string]any)
 	return
 }
 
-func (c *clientAPI) putRawRetry(ctx context.Context, url string, body *[]byte, tries int) (err error) {
-	var retry bool
-	for i := range time.Duration(tries) {
-		_, retry, err = c.session.put(ctx, url, nil, nil, body)
-		if err == nil {
-			return
-		}
-		if !retry {
-			return
-		}
-		time.Sleep((i + 1) * c.timeUnit)
-	}
-	return
+func (c *clientAPI) putRawRetry(ctx context.Context, url string, body *[]byte) error {
+	_, err := c.retry(ctx, http.MethodPut, url, func() (*http.Response, bool, error) {
+		return c.session.put(ctx, url, nil, nil, body)
+	})
+	return err
 }
 
 // checkTask polls the API to check if the Proxmox task has been completed.
@@ -235,80 +197,23 @@ func (c *clientAPI) waitForCompletion(ctx context.Context, taskResponse map[stri
 	if taskResponse["data"] == nil {
 		return nil
 	}
-	waited := time.Duration(0)
 	taskUpid := taskResponse["data"].(string)
-	for waited < c.taskTimeout {
-		retry, err := c.getTaskExitStatus(ctx, taskUpid)
-		if err != nil {
-			return err
-		}
-		if !retry {
-			return nil
-		}
-		time.Sleep(TaskStatusCheckInterval * time.Second)
-		waited = waited + TaskStatusCheckInterval
-	}
-	return errors.New("Wait timeout for:" + taskUpid)
-}
-
-func (c *clientAPI) getTaskExitStatus(ctx context.Context, taskUpID string) (bool, error) {
-
-	// "UPID:pve-01:00068180:17BE8318:697285F2:qmdelsnapshot:801:root@pam:"
-	const prefixLen = len("UPID:")
-	secondColon := strings.IndexByte(taskUpID[prefixLen:], ':') // find position of second colon
-	node := taskUpID[prefixLen : prefixLen+secondColon]
-
-	var err error
-	var data map[string]any
-	dataPtr := &data
-	url := "/nodes/" + node + "/tasks/" + taskUpID + "/status"
-	for i := range time.Duration(RequestRetryCount) {
-		var retry bool
-		_, retry, err = c.session.getJSON(ctx, url, nil, nil, dataPtr)
-		if err == nil || !retry {
-			break
-		}
-		if err == io.ErrUnexpectedEOF { // Early EOF can happen, don't retry
-			return true, nil
-		}
-		time.Sleep((i + 1) * c.timeUnit)
-	}
-	if err != nil {
-		return false, err
-	}
-	var exitStatus string
-	if v, isSet := data["data"].(map[string]any)["exitstatus"]; isSet {
-		exitStatus = v.(string)
-	} else {
-		return true, nil // still running
-	}
-
-	const taskSuccess = "OK"
-	const taskWarning = "WARNING"
-
-	if exitStatus == taskSuccess {
-		return false, nil
+	var upid UPID
+	if err := upid.Parse(taskUpid); err != nil {
+		return err
 	}
-	if strings.HasPrefix(exitStatus, taskWarning) {
-		return false, nil
+	waitCtx, cancel := context.WithTimeout(ctx, c.taskTimeout)
+	defer cancel()
+	_, err := upid.wait(waitCtx, c, defaultTaskBackoff(c.timeUnit))
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return timeoutError("Wait timeout for:" + taskUpid)
 	}
-	return false, &TaskError{
-		Message: exitStatus,
-		TaskID:  taskUpID}
+	return err
 }
 
-func (c *clientAPI) getJsonRetry(ctx context.Context, url string, data *map[string]any, tries int) error {
-	var err error
-	var retry bool
-	for i := range time.Duration(tries) {
-		_, retry, err = c.session.getJSON(ctx, url, nil, nil, data)
-		if err == nil {
-			return nil
-		}
-		if !retry {
-			return err
-		}
-		time.Sleep((i + 1) * c.timeUnit)
-	}
+func (c *clientAPI) getJsonRetry(ctx context.Context, url string, data *map[string]any) error {
+	_, err := c.retry(ctx, http.MethodGet, url, func() (*http.Response, bool, error) {
+		return c.session.getJSON(ctx, url, nil, nil, data)
+	})
 	return err
 }
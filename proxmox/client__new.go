@@ -4,8 +4,11 @@ type ClientNew struct {
 	ApiToken  ApiTokenInterface
 	Group     GroupInterface
 	Guest     GuestInterface
+	LxcGuest  LxcGuestInterface
 	Pool      PoolInterface
 	QemuGuest QemuGuestInterface
 	Snapshot  SnapshotInterface
+	Task      TaskInterface
 	User      UserInterface
+	Watcher   WatcherInterface
 }
@@ -0,0 +1,198 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy decides if, and after how long, a failed request is retried.
+// Implementations must be safe for concurrent use.
+type RetryPolicy interface {
+	// Retry is called after every failed attempt.
+	// Returns the delay before the next attempt and whether a next attempt should be made.
+	Retry(RetryAttempt) (delay time.Duration, retry bool)
+}
+
+// RetryAttempt describes a failed request.
+type RetryAttempt struct {
+	Attempt uint   // Number of the attempt that failed, starting at 1.
+	Err     error  // The error returned for this attempt, an *ApiError when the API returned an error message.
+	Method  string // HTTP method of the request.
+	Path    string // Path of the request, relative to the API URL.
+	Status  int    // HTTP status code of the response, 0 when no response was received.
+	// Retryable is the classification made by the SDK.
+	// True for network errors and responses without an error message, false when the API returned an error message.
+	Retryable bool
+}
+
+// RetryPolicyExponential retries with an exponentially growing delay and random jitter.
+// Fields left at their zero value use the defaults.
+type RetryPolicyExponential struct {
+	// Total number of attempts, including the first one. Default: 3
+	Attempts uint
+	// Delay after the first failed attempt. Default: 1s
+	Initial time.Duration
+	// Upper bound of the delay. Default: 30s
+	Maximum time.Duration
+	// Factor the delay grows by after each attempt. Default: 2, minimum 1
+	Multiplier float64
+	// Fraction of the delay that is randomized, between 0 and 1.
+	// A jitter of 0.2 results in a delay between 80% and 100% of the calculated delay.
+	Jitter float64
+	// Overrides the classification of the SDK for the specified HTTP status codes.
+	// e.g. {596: true} retries when pveproxy could not reach the node, {500: false} never retries internal server errors.
+	Status map[int]bool
+	// When set, decides if a request that failed with an *ApiError is retried.
+	// Takes precedence over Status.
+	ApiError func(*ApiError) bool
+}
+
+const (
+	RetryPolicyExponential_Error_Jitter     = "retry jitter must be between 0 and 1"
+	RetryPolicyExponential_Error_Initial    = "initial retry delay may not be negative"
+	RetryPolicyExponential_Error_Maximum    = "maximum retry delay may not be negative"
+	RetryPolicyExponential_Error_MaxInitial = "maximum retry delay may not be smaller than the initial retry delay"
+	RetryPolicyExponential_Error_Multiplier = "retry multiplier must be 1 or greater"
+)
+
+const (
+	retryPolicyExponentialDefaultInitial    = time.Second
+	retryPolicyExponentialDefaultMaximum    = 30 * time.Second
+	retryPolicyExponentialDefaultMultiplier = 2
+)
+
+var _ RetryPolicy = RetryPolicyExponential{}
+
+func (p RetryPolicyExponential) Retry(attempt RetryAttempt) (time.Duration, bool) {
+	attempts := p.Attempts
+	if attempts == 0 {
+		attempts = RequestRetryCount
+	}
+	if attempt.Attempt >= attempts || !p.retryable(attempt) {
+		return 0, false
+	}
+	return p.delay(attempt.Attempt, rand.Float64()), true
+}
+
+// calculates the delay after the specified attempt, random should be in the range [0,1).
+func (p RetryPolicyExponential) delay(attempt uint, random float64) time.Duration {
+	initial := p.Initial
+	if initial == 0 {
+		initial = retryPolicyExponentialDefaultInitial
+	}
+	maximum := p.Maximum
+	if maximum == 0 {
+		maximum = retryPolicyExponentialDefaultMaximum
+	}
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = retryPolicyExponentialDefaultMultiplier
+	}
+	delay := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if delay > float64(maximum) {
+		delay = float64(maximum)
+	}
+	return time.Duration(delay - delay*p.Jitter*random)
+}
+
+func (p RetryPolicyExponential) retryable(attempt RetryAttempt) bool {
+	if p.ApiError != nil {
+		var apiErr *ApiError
+		if errors.As(attempt.Err, &apiErr) {
+			return p.ApiError(apiErr)
+		}
+	}
+	if attempt.Status != 0 {
+		if retry, isSet := p.Status[attempt.Status]; isSet {
+			return retry
+		}
+	}
+	return attempt.Retryable
+}
+
+func (p RetryPolicyExponential) Validate() error {
+	if p.Initial < 0 {
+		return errors.New(RetryPolicyExponential_Error_Initial)
+	}
+	if p.Maximum < 0 {
+		return errors.New(RetryPolicyExponential_Error_Maximum)
+	}
+	if p.Initial != 0 && p.Maximum != 0 && p.Maximum < p.Initial {
+		return errors.New(RetryPolicyExponential_Error_MaxInitial)
+	}
+	if p.Multiplier != 0 && p.Multiplier < 1 {
+		return errors.New(RetryPolicyExponential_Error_Multiplier)
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return errors.New(RetryPolicyExponential_Error_Jitter)
+	}
+	return nil
+}
+
+// The policy used when no policy is configured.
+// Retries the requests the SDK classifies as retryable with a linearly growing delay.
+type retryPolicyLinear struct {
+	attempts uint
+	timeUnit time.Duration
+}
+
+func (p retryPolicyLinear) Retry(attempt RetryAttempt) (time.Duration, bool) {
+	if !attempt.Retryable || attempt.Attempt >= p.attempts {
+		return 0, false
+	}
+	return time.Duration(attempt.Attempt) * p.timeUnit, true
+}
+
+// retryRequest executes the request until it succeeds, the policy gives up, or the context is done.
+// When maxAttempts is not 0, no more than maxAttempts attempts are made regardless of the policy.
+// Every retry is logged to the logger, when it is not nil.
+func retryRequest(ctx context.Context, policy RetryPolicy, maxAttempts uint, logger *slog.Logger, method, path string, request func() (*http.Response, bool, error)) (*http.Response, error) {
+	for attempt := uint(1); ; attempt++ {
+		resp, retryable, err := request()
+		if err == nil {
+			return resp, nil
+		}
+		if maxAttempts != 0 && attempt >= maxAttempts {
+			return resp, err
+		}
+		var status int
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		failed := RetryAttempt{
+			Attempt:   attempt,
+			Err:       err,
+			Method:    method,
+			Path:      path,
+			Status:    status,
+			Retryable: retryable}
+		delay, retry := policy.Retry(failed)
+		if !retry {
+			return resp, err
+		}
+		logRetry(ctx, logger, failed, delay)
+		if err := sleep(ctx, delay); err != nil {
+			return resp, err
+		}
+	}
+}
+
+// sleep pauses for the specified duration, or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,215 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_SetRetryPolicy(t *testing.T) {
+	t.Parallel()
+	const path = "/access/users/test@pve/token/testToken"
+	tests := []struct {
+		name     string
+		policy   RetryPolicy
+		requests []mockServer.Request
+		err      error
+	}{
+		{name: `default policy`,
+			requests: mockServer.RequestsError(path, mockServer.DELETE, 500, RequestRetryCount),
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
+		{name: `more attempts`,
+			policy:   RetryPolicyExponential{Attempts: 5, Initial: time.Nanosecond},
+			requests: mockServer.RequestsError(path, mockServer.DELETE, 500, 5),
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
+		{name: `status not retried`,
+			policy:   RetryPolicyExponential{Status: map[int]bool{500: false}},
+			requests: mockServer.RequestsError(path, mockServer.DELETE, 500, 1),
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
+		{name: `ApiError retried`,
+			policy: RetryPolicyExponential{Initial: time.Nanosecond, ApiError: func(err *ApiError) bool { return err.Code == "596" }},
+			requests: mockServer.Append(
+				mockServer.RequestsErrorHandled(path, mockServer.DELETE, mockServer.JsonError(596, map[string]any{"message": "Broken pipe"})),
+				mockServer.RequestsDelete(path, nil))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			server, c := testMockServerInit(t)
+			require.NoError(t, c.SetRetryPolicy(test.policy))
+			server.Set(test.requests, t)
+			_, err := c.New().ApiToken.Delete(context.Background(), ApiTokenID{
+				User:      UserID{Name: "test", Realm: "pve"},
+				TokenName: "testToken"})
+			require.Equal(t, test.err, err)
+			server.Clear(t)
+		})
+	}
+}
+
+func Test_Client_SetRetryPolicy_Invalid(t *testing.T) {
+	t.Parallel()
+	c := &Client{}
+	require.NoError(t, c.SetRetryPolicy(RetryPolicyExponential{Attempts: 1}))
+	require.Equal(t, errors.New(RetryPolicyExponential_Error_Initial), c.SetRetryPolicy(RetryPolicyExponential{Initial: -time.Second}))
+	require.Equal(t, errors.New(RetryPolicyExponential_Error_MaxInitial), c.SetRetryPolicy(RetryPolicyExponential{Initial: time.Minute, Maximum: time.Second}))
+	require.Equal(t, RetryPolicyExponential{Attempts: 1}, c.retryPolicy, "invalid policy may not replace the current one")
+	require.NoError(t, c.SetRetryPolicy(nil))
+	require.Nil(t, c.retryPolicy)
+}
+
+func Test_RetryPolicyExponential_delay(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		policy  RetryPolicyExponential
+		attempt uint
+		random  float64
+		output  time.Duration
+	}{
+		{name: `defaults first attempt`,
+			attempt: 1,
+			output:  time.Second},
+		{name: `defaults third attempt`,
+			attempt: 3,
+			output:  4 * time.Second},
+		{name: `maximum`,
+			policy:  RetryPolicyExponential{Initial: time.Second, Maximum: 5 * time.Second, Multiplier: 3},
+			attempt: 3,
+			output:  5 * time.Second},
+		{name: `jitter`,
+			policy:  RetryPolicyExponential{Initial: 10 * time.Second, Jitter: 0.5},
+			attempt: 1,
+			random:  0.5,
+			output:  7500 * time.Millisecond},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			require.Equal(t, test.output, test.policy.delay(test.attempt, test.random))
+		})
+	}
+}
+
+func Test_RetryPolicyExponential_Retry(t *testing.T) {
+	t.Parallel()
+	apiError := func(code string) *ApiError { return &ApiError{Code: code} }
+	tests := []struct {
+		name    string
+		policy  RetryPolicyExponential
+		attempt RetryAttempt
+		retry   bool
+	}{
+		{name: `retryable`,
+			attempt: RetryAttempt{Attempt: 1, Retryable: true},
+			retry:   true},
+		{name: `not retryable`,
+			attempt: RetryAttempt{Attempt: 1}},
+		{name: `attempts exhausted default`,
+			attempt: RetryAttempt{Attempt: RequestRetryCount, Retryable: true}},
+		{name: `attempts exhausted`,
+			policy:  RetryPolicyExponential{Attempts: 2},
+			attempt: RetryAttempt{Attempt: 2, Retryable: true}},
+		{name: `status retry`,
+			policy:  RetryPolicyExponential{Status: map[int]bool{596: true}},
+			attempt: RetryAttempt{Attempt: 1, Status: 596},
+			retry:   true},
+		{name: `status no retry`,
+			policy:  RetryPolicyExponential{Status: map[int]bool{500: false}},
+			attempt: RetryAttempt{Attempt: 1, Status: 500, Retryable: true}},
+		{name: `ApiError retry`,
+			policy: RetryPolicyExponential{
+				Status:   map[int]bool{500: false},
+				ApiError: func(err *ApiError) bool { return err.Code == "500" }},
+			attempt: RetryAttempt{Attempt: 1, Status: 500, Err: apiError("500")},
+			retry:   true},
+		{name: `ApiError no retry`,
+			policy:  RetryPolicyExponential{ApiError: func(err *ApiError) bool { return false }},
+			attempt: RetryAttempt{Attempt: 1, Err: apiError("500"), Retryable: true}},
+		{name: `ApiError func not applicable`,
+			policy:  RetryPolicyExponential{ApiError: func(err *ApiError) bool { return false }},
+			attempt: RetryAttempt{Attempt: 1, Err: errors.New("connection reset"), Retryable: true},
+			retry:   true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			_, retry := test.policy.Retry(test.attempt)
+			require.Equal(t, test.retry, retry)
+		})
+	}
+}
+
+func Test_RetryPolicyExponential_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input RetryPolicyExponential
+		err   error
+	}{
+		{name: `Valid empty`},
+		{name: `Valid full`,
+			input: RetryPolicyExponential{Attempts: 5, Initial: time.Second, Maximum: time.Minute, Multiplier: 2, Jitter: 1}},
+		{name: `Invalid Initial`,
+			input: RetryPolicyExponential{Initial: -1},
+			err:   errors.New(RetryPolicyExponential_Error_Initial)},
+		{name: `Invalid Maximum`,
+			input: RetryPolicyExponential{Maximum: -1},
+			err:   errors.New(RetryPolicyExponential_Error_Maximum)},
+		{name: `Invalid Maximum smaller than Initial`,
+			input: RetryPolicyExponential{Initial: time.Minute, Maximum: time.Second},
+			err:   errors.New(RetryPolicyExponential_Error_MaxInitial)},
+		{name: `Invalid Multiplier`,
+			input: RetryPolicyExponential{Multiplier: 0.5},
+			err:   errors.New(RetryPolicyExponential_Error_Multiplier)},
+		{name: `Invalid Jitter`,
+			input: RetryPolicyExponential{Jitter: 1.1},
+			err:   errors.New(RetryPolicyExponential_Error_Jitter)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			require.Equal(t, test.err, test.input.Validate())
+		})
+	}
+}
+
+func Test_retryRequest(t *testing.T) {
+	t.Parallel()
+	errRetry := errors.New("retry")
+	policy := retryPolicyLinear{attempts: 5, timeUnit: time.Nanosecond}
+	t.Run(`success after retry`, func(t *testing.T) {
+		var attempts uint
+		_, err := retryRequest(context.Background(), policy, 0, nil, http.MethodGet, "/", func() (*http.Response, bool, error) {
+			attempts++
+			if attempts < 3 {
+				return nil, true, errRetry
+			}
+			return nil, false, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint(3), attempts)
+	})
+	t.Run(`max attempts`, func(t *testing.T) {
+		var attempts uint
+		_, err := retryRequest(context.Background(), policy, 2, nil, http.MethodGet, "/", func() (*http.Response, bool, error) {
+			attempts++
+			return nil, true, errRetry
+		})
+		require.Equal(t, errRetry, err)
+		require.Equal(t, uint(2), attempts)
+	})
+	t.Run(`context canceled while sleeping`, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var attempts uint
+		_, err := retryRequest(ctx, retryPolicyLinear{attempts: 5, timeUnit: time.Hour}, 0, nil, http.MethodGet, "/", func() (*http.Response, bool, error) {
+			attempts++
+			cancel()
+			return nil, true, errRetry
+		})
+		require.Equal(t, context.Canceled, err)
+		require.Equal(t, uint(1), attempts)
+	})
+}
@@ -207,7 +207,7 @@ func (token ApiTokenConfig) update(ctx context.Context, c *clientAPI, userID Use
 	if body == nil {
 		return nil
 	}
-	err := c.putRawRetry(ctx, "/access/users/"+userID.String()+"/token/"+token.Name.String(), body, 3)
+	err := c.putRawRetry(ctx, "/access/users/"+userID.String()+"/token/"+token.Name.String(), body)
 	return err
 }
 
@@ -297,7 +297,7 @@ type ApiTokenID struct {
 }
 
 func (id ApiTokenID) delete(ctx context.Context, c *clientAPI) (bool, error) {
-	err := c.deleteRetry(ctx, "/access/users/"+id.User.String()+"/token/"+id.TokenName.String(), 3)
+	err := c.deleteRetry(ctx, "/access/users/"+id.User.String()+"/token/"+id.TokenName.String())
 	if apiErr, ok := err.(*ApiError); ok {
 		if strings.HasPrefix(apiErr.Message, "no such token ") {
 			return false, nil
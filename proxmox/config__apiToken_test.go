@@ -47,7 +47,7 @@ func Test_apiTokenClient_Create(t *testing.T) {
 			apiToken: ApiTokenConfig{
 				Name: "testToken"},
 			requests: mockServer.RequestsError(path, mockServer.POST, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -93,7 +93,7 @@ func Test_apiTokenClient_Delete(t *testing.T) {
 				User:      UserID{Name: "test", Realm: "pve"},
 				TokenName: "testToken"},
 			requests: mockServer.RequestsError(path, mockServer.DELETE, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -140,7 +140,7 @@ func Test_apiTokenClient_Exists(t *testing.T) {
 				User:      UserID{Name: "test", Realm: "pve"},
 				TokenName: "testToken"},
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -212,7 +212,7 @@ func Test_apiTokenClient_List(t *testing.T) {
 		{name: `500 internal server error`,
 			user:     UserID{Name: "test", Realm: "pve"},
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -266,7 +266,7 @@ func Test_apiTokenClient_Read(t *testing.T) {
 		{name: `500 internal server error`,
 			token:    ApiTokenID{User: UserID{Name: "test", Realm: "pve"}, TokenName: "token1"},
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -324,7 +324,7 @@ func Test_apiTokenClient_Update(t *testing.T) {
 				Expiration:          util.Pointer(uint(123456)),
 				PrivilegeSeparation: util.Pointer(true)},
 			requests: mockServer.RequestsError(path, mockServer.PUT, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
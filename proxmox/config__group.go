@@ -207,7 +207,7 @@ func (config ConfigGroup) Create(ctx context.Context, client *Client) error {
 }
 
 func (config ConfigGroup) create(ctx context.Context, c *clientAPI) error {
-	if err := c.postRawRetry(ctx, "/access/groups", config.mapToApiCreate(), 3); err != nil {
+	if err := c.postRawRetry(ctx, "/access/groups", config.mapToApiCreate()); err != nil {
 		return err
 	}
 	if config.Members != nil {
@@ -263,7 +263,7 @@ func (config ConfigGroup) Update(ctx context.Context, client *Client) error {
 // current is required when we want to update the members
 func (config ConfigGroup) update(ctx context.Context, current *rawGroupConfig, c *clientAPI) error {
 	if b := config.mapToApiUpdate(current); b != nil {
-		if err := c.putRawRetry(ctx, "/access/groups/"+config.Name.String(), b, 3); err != nil {
+		if err := c.putRawRetry(ctx, "/access/groups/"+config.Name.String(), b); err != nil {
 			return err
 		}
 	}
@@ -446,7 +446,7 @@ func (group GroupName) Delete(ctx context.Context, client *Client) error {
 }
 
 func (group GroupName) delete(ctx context.Context, c *clientAPI) (bool, error) {
-	if err := c.deleteRetry(ctx, "/access/groups/"+group.String(), 3); err != nil {
+	if err := c.deleteRetry(ctx, "/access/groups/"+group.String()); err != nil {
 		if apiErr, ok := err.(*ApiError); ok {
 			if strings.HasPrefix(apiErr.Message, "delete group failed: group '"+group.String()+"' does not exist") {
 				return false, nil
@@ -71,7 +71,7 @@ func Test_groupClient_AddMembers(t *testing.T) {
 			groups:   []GroupName{"group4", "group5"},
 			users:    []UserID{{Name: "test", Realm: "pve"}},
 			requests: mockServer.RequestsError("/access/users/test@pve", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -126,7 +126,7 @@ func Test_groupClient_Create(t *testing.T) {
 				mockServer.RequestsPost("/access/groups", map[string]any{
 					"groupid": "group1"}),
 				mockServer.RequestsError("/access/users/user1@pam", mockServer.GET, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `members multiple`,
 			input: ConfigGroup{
 				Name: "group1",
@@ -172,7 +172,7 @@ func Test_groupClient_Create(t *testing.T) {
 		{name: `API error`,
 			input:    ConfigGroup{Name: "group"},
 			requests: mockServer.RequestsError("/access/groups", mockServer.POST, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -210,7 +210,7 @@ func Test_groupClient_Delete(t *testing.T) {
 		{name: `API error`,
 			input:    "group1",
 			requests: mockServer.RequestsError(path, mockServer.DELETE, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -251,7 +251,7 @@ func Test_groupClient_Exists(t *testing.T) {
 		{name: `API error`,
 			input:    "group1",
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -287,7 +287,7 @@ func Test_groupClient_List(t *testing.T) {
 				}}})},
 		{name: `API error`,
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -340,7 +340,7 @@ func Test_groupClient_Read(t *testing.T) {
 		{name: `API error`,
 			input:    "group1",
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -415,7 +415,7 @@ func Test_groupClient_RemoveMembers(t *testing.T) {
 			groups:   []GroupName{"group4", "group5"},
 			users:    []UserID{{Name: "test", Realm: "pve"}},
 			requests: mockServer.RequestsError("/access/users/test@pve", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -458,7 +458,7 @@ func Test_groupClient_Set(t *testing.T) {
 		{name: `API error`,
 			input:    ConfigGroup{Name: "group1"},
 			requests: mockServer.RequestsError("/access/groups/group1", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -500,7 +500,7 @@ func Test_groupClient_Update(t *testing.T) {
 				Name:    "group1",
 				Members: &[]UserID{}},
 			requests: mockServer.RequestsError("/access/groups/group1", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `members empty error handled`,
 			input: ConfigGroup{
 				Name:    "group1",
@@ -567,7 +567,7 @@ func Test_groupClient_Update(t *testing.T) {
 					"groups": "group2,group3",
 				}}),
 				mockServer.RequestsError("/access/users/test@pve", mockServer.PUT, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `members set to empty`,
 			input: ConfigGroup{
 				Name:    "group1",
@@ -603,7 +603,7 @@ func Test_groupClient_Update(t *testing.T) {
 					"groups": "group1,group2,group3",
 				}}),
 				mockServer.RequestsError("/access/users/test@pve", mockServer.PUT, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `members set to set`,
 			input: ConfigGroup{
 				Name: "group1",
@@ -633,7 +633,7 @@ func Test_groupClient_Update(t *testing.T) {
 			input: ConfigGroup{Name: "group1",
 				Comment: util.Pointer("Test")},
 			requests: mockServer.RequestsError("/access/groups/group1", mockServer.PUT, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
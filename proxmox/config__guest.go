@@ -15,6 +15,11 @@ import (
 
 type (
 	GuestInterface interface {
+		// Batch performs the same operation on all guests, see GuestBatch for the options.
+		// The result holds the outcome of every guest, the error combines the errors of all guests that failed.
+		Batch(ctx context.Context, guests []VmRef, batch GuestBatch) (GuestBatchResult, error)
+		BatchNoCheck(ctx context.Context, guests []VmRef, batch GuestBatch) (GuestBatchResult, error)
+
 		// Returns true if the guest existed and was deleted, false if the guest did not exist.
 		Delete(context.Context, VmRef) (bool, error)
 		DeleteNoCheck(context.Context, VmRef) (bool, error)
@@ -284,7 +289,7 @@ func (guestID GuestID) setPool(ctx context.Context, c *clientAPI, newPool PoolNa
 		}
 	} else {
 		if currentPool == nil || *currentPool == "" { // join pool
-			if version < version_8_0_0 {
+			if version < CapabilityPoolAllowMove.MinimumVersion().Encode() {
 				if err = newPool.addGuestsV7(ctx, c, &[]GuestID{guestID}, nil); err != nil {
 					return
 				}
@@ -292,7 +297,7 @@ func (guestID GuestID) setPool(ctx context.Context, c *clientAPI, newPool PoolNa
 				newPool.addGuestsV8(ctx, c, &[]GuestID{guestID}, nil)
 			}
 		} else if newPool != *currentPool { // change pool
-			if version < version_8_0_0 {
+			if version < CapabilityPoolAllowMove.MinimumVersion().Encode() {
 				if err = (*currentPool).removeMembers(ctx, c, &[]GuestID{guestID}, nil); err != nil {
 					return
 				}
@@ -0,0 +1,269 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// GuestBatch is an operation that is performed on many guests at once.
+type GuestBatch struct {
+	Action GuestBatchAction `json:"action"`
+	// Number of guests processed at the same time, defaults to 4.
+	Concurrency uint `json:"concurrency,omitempty"`
+	// When set no new guests are processed after the first failure, the remaining guests are skipped.
+	FailFast bool `json:"fail_fast,omitempty"`
+	// Maximum duration of the operation on a single guest, zero means no limit.
+	// Only the wait is abandoned when it expires, the task keeps running in Proxmox VE while the guest is reported as failed.
+	Timeout  time.Duration       `json:"timeout,omitempty"`
+	Migrate  *GuestBatchMigrate  `json:"migrate,omitempty"`  // Required when Action is GuestBatchAction_Migrate.
+	Snapshot *GuestBatchSnapshot `json:"snapshot,omitempty"` // Required when Action is GuestBatchAction_Snapshot.
+	Tags     *GuestBatchTags     `json:"tags,omitempty"`     // Required when Action is GuestBatchAction_Tags.
+}
+
+const (
+	GuestBatch_Error_DuplicateGuest = "guest specified more than once"
+	GuestBatch_Error_MigrateNotSet  = "migrate must be set when the action is migrate"
+	GuestBatch_Error_NoGuests       = "no guests specified"
+	GuestBatch_Error_SnapshotNotSet = "snapshot must be set when the action is snapshot"
+	GuestBatch_Error_TagsNotSet     = "tags must be set when the action is tags"
+)
+
+const guestBatchDefaultConcurrency = 4
+
+var errGuestBatchSkipped = errors.New("skipped after an earlier failure")
+
+// GuestBatchSkipped matches the result of guests that were skipped because an earlier guest in a fail-fast batch failed.
+func (errorMsg) GuestBatchSkipped() error { return errGuestBatchSkipped }
+
+func (batch GuestBatch) concurrency() int {
+	if batch.Concurrency == 0 {
+		return guestBatchDefaultConcurrency
+	}
+	return int(batch.Concurrency)
+}
+
+// run performs the batch operation on a single guest, the node and type of the guest must be known.
+func (batch GuestBatch) run(ctx context.Context, c *guestClient, vmr VmRef, status PowerState) error {
+	if batch.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, batch.Timeout)
+		defer cancel()
+	}
+	switch batch.Action {
+	case GuestBatchAction_Migrate:
+		if vmr.node == batch.Migrate.Node {
+			return nil
+		}
+		return vmr.migrate_Unsafe(ctx, c.oldClient, batch.Migrate.Node, batch.Migrate.Online)
+	case GuestBatchAction_Reboot:
+		return c.RebootNoCheck(ctx, vmr)
+	case GuestBatchAction_Shutdown:
+		if status == PowerStateStopped {
+			return nil
+		}
+		return c.ShutdownNoCheck(ctx, vmr)
+	case GuestBatchAction_Snapshot:
+		return batch.Snapshot.Name.create(ctx, c.api, vmr, batch.Snapshot.Description, batch.Snapshot.VmState && vmr.vmType == GuestQemu)
+	case GuestBatchAction_Start:
+		if status == PowerStateRunning {
+			return nil
+		}
+		return c.StartNoCheck(ctx, vmr)
+	case GuestBatchAction_Tags:
+		return vmr.updateTags_Unsafe(ctx, c.api, batch.Tags.Add, batch.Tags.Remove)
+	}
+	return nil
+}
+
+func (batch GuestBatch) Validate() error {
+	if err := batch.Action.Validate(); err != nil {
+		return err
+	}
+	switch batch.Action {
+	case GuestBatchAction_Migrate:
+		if batch.Migrate == nil {
+			return errors.New(GuestBatch_Error_MigrateNotSet)
+		}
+		return batch.Migrate.Node.Validate()
+	case GuestBatchAction_Snapshot:
+		if batch.Snapshot == nil {
+			return errors.New(GuestBatch_Error_SnapshotNotSet)
+		}
+		return batch.Snapshot.Name.Validate()
+	case GuestBatchAction_Tags:
+		if batch.Tags == nil {
+			return errors.New(GuestBatch_Error_TagsNotSet)
+		}
+		if err := batch.Tags.Add.Validate(); err != nil {
+			return err
+		}
+		return batch.Tags.Remove.Validate()
+	}
+	return nil
+}
+
+type GuestBatchAction string // Enum
+
+const (
+	GuestBatchAction_Migrate  GuestBatchAction = "migrate"
+	GuestBatchAction_Reboot   GuestBatchAction = "reboot"
+	GuestBatchAction_Shutdown GuestBatchAction = "shutdown"
+	GuestBatchAction_Snapshot GuestBatchAction = "snapshot"
+	GuestBatchAction_Start    GuestBatchAction = "start"
+	GuestBatchAction_Tags     GuestBatchAction = "tags"
+)
+
+const GuestBatchAction_Error_Invalid = "action should be one of: migrate, reboot, shutdown, snapshot, start, tags"
+
+func (action GuestBatchAction) Validate() error {
+	switch action {
+	case GuestBatchAction_Migrate, GuestBatchAction_Reboot, GuestBatchAction_Shutdown,
+		GuestBatchAction_Snapshot, GuestBatchAction_Start, GuestBatchAction_Tags:
+		return nil
+	}
+	return errors.New(GuestBatchAction_Error_Invalid)
+}
+
+type GuestBatchMigrate struct {
+	Node   NodeName `json:"node"`
+	Online bool     `json:"online,omitempty"` // Live migrate running guests.
+}
+
+type GuestBatchSnapshot struct {
+	Name        SnapshotName `json:"name"`
+	Description string       `json:"description,omitempty"`
+	VmState     bool         `json:"vmstate,omitempty"` // Only applies to Qemu guests.
+}
+
+// GuestBatchTags adds and removes tags, other tags of the guest are kept.
+type GuestBatchTags struct {
+	Add    Tags `json:"add,omitempty"`
+	Remove Tags `json:"remove,omitempty"`
+}
+
+// GuestBatchResult holds the outcome of each guest in a batch, nil when the operation succeeded.
+type GuestBatchResult map[GuestID]error
+
+// Err returns the errors of all failed guests in order of their ID, or nil when all guests succeeded.
+func (r GuestBatchResult) Err() error {
+	var errs []error
+	for _, id := range slices.Sorted(maps.Keys(r)) {
+		if r[id] != nil {
+			errs = append(errs, &errorWrapper[GuestID]{err: r[id], id: id})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Failed returns the ID of all guests that failed or were skipped in ascending order.
+func (r GuestBatchResult) Failed() []GuestID {
+	ids := make([]GuestID, 0)
+	for id, err := range r {
+		if err != nil {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func (c *guestClient) Batch(ctx context.Context, guests []VmRef, batch GuestBatch) (GuestBatchResult, error) {
+	if len(guests) == 0 {
+		return nil, errors.New(GuestBatch_Error_NoGuests)
+	}
+	if err := batch.Validate(); err != nil {
+		return nil, err
+	}
+	// The result is keyed by guest ID, and running the action twice on the same guest at once would race.
+	ids := make(map[GuestID]struct{}, len(guests))
+	for i := range guests {
+		if err := guests[i].vmId.Validate(); err != nil {
+			return nil, err
+		}
+		if _, isSet := ids[guests[i].vmId]; isSet {
+			return nil, &errorWrapper[GuestID]{err: errors.New(GuestBatch_Error_DuplicateGuest), id: guests[i].vmId}
+		}
+		ids[guests[i].vmId] = struct{}{}
+	}
+	return c.BatchNoCheck(ctx, guests, batch)
+}
+
+func (c *guestClient) BatchNoCheck(ctx context.Context, guests []VmRef, batch GuestBatch) (GuestBatchResult, error) {
+	// A single listing tells us the node, type and status of every guest.
+	raws, err := c.api.listGuestResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make(GuestBatchResult, len(guests))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	var failed bool
+	limit := make(chan struct{}, batch.concurrency())
+	for _, vmr := range guests {
+		raw, exists := raws.selectID(vmr.vmId)
+		if !exists {
+			mutex.Lock()
+			result[vmr.vmId] = errGuestDoesNotExist
+			failed = true
+			mutex.Unlock()
+			continue
+		}
+		vmr.node = raw.GetNode()
+		vmr.vmType = raw.GetType()
+		status := raw.GetStatus()
+		limit <- struct{}{}
+		mutex.Lock()
+		var skipped error
+		if failed && batch.FailFast {
+			skipped = errGuestBatchSkipped
+		} else if ctx.Err() != nil {
+			skipped = ctx.Err()
+		}
+		if skipped != nil {
+			result[vmr.vmId] = skipped
+			mutex.Unlock()
+			<-limit
+			continue
+		}
+		mutex.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := batch.run(ctx, c, vmr, status)
+			mutex.Lock()
+			result[vmr.vmId] = err
+			failed = failed || err != nil
+			mutex.Unlock()
+			<-limit
+		}()
+	}
+	wg.Wait()
+	return result, result.Err()
+}
+
+// updateTags_Unsafe adds and removes tags while keeping the other tags of the guest.
+func (vmr *VmRef) updateTags_Unsafe(ctx context.Context, c *clientAPI, add, remove Tags) error {
+	config, err := c.getGuestConfig(ctx, vmr)
+	if err != nil {
+		return err
+	}
+	var current Tags
+	if v, isSet := config[lxcApiKeyTags]; isSet {
+		current.mapToSDK(v.(string))
+	}
+	tags := make(Tags, 0, len(current)+len(add))
+	for _, tag := range slices.Concat(current, add) {
+		if !slices.Contains(remove, tag) && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	body := []byte(lxcApiKeyTags + "=" + tags.mapToApiCreate())
+	if len(tags) == 0 {
+		body = []byte("delete=" + lxcApiKeyTags)
+	}
+	return c.putRawRetry(ctx, "/nodes/"+vmr.node.String()+"/"+vmr.vmType.String()+"/"+vmr.vmId.String()+"/config", &body)
+}
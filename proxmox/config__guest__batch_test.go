@@ -0,0 +1,111 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GuestBatch_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  GuestBatch
+		output error
+	}{
+		{name: `Valid reboot`,
+			input: GuestBatch{Action: GuestBatchAction_Reboot}},
+		{name: `Valid migrate`,
+			input: GuestBatch{Action: GuestBatchAction_Migrate, Migrate: &GuestBatchMigrate{Node: "pve"}}},
+		{name: `Valid snapshot`,
+			input: GuestBatch{Action: GuestBatchAction_Snapshot, Snapshot: &GuestBatchSnapshot{Name: "patch"}}},
+		{name: `Valid tags`,
+			input: GuestBatch{Action: GuestBatchAction_Tags, Tags: &GuestBatchTags{Add: Tags{"a"}, Remove: Tags{"b"}}}},
+		{name: `Invalid action`,
+			input:  GuestBatch{Action: "delete"},
+			output: errors.New(GuestBatchAction_Error_Invalid)},
+		{name: `Invalid migrate not set`,
+			input:  GuestBatch{Action: GuestBatchAction_Migrate},
+			output: errors.New(GuestBatch_Error_MigrateNotSet)},
+		{name: `Invalid migrate node`,
+			input:  GuestBatch{Action: GuestBatchAction_Migrate, Migrate: &GuestBatchMigrate{}},
+			output: NodeName("").Validate()},
+		{name: `Invalid snapshot not set`,
+			input:  GuestBatch{Action: GuestBatchAction_Snapshot},
+			output: errors.New(GuestBatch_Error_SnapshotNotSet)},
+		{name: `Invalid snapshot name`,
+			input:  GuestBatch{Action: GuestBatchAction_Snapshot, Snapshot: &GuestBatchSnapshot{}},
+			output: SnapshotName("").Validate()},
+		{name: `Invalid tags not set`,
+			input:  GuestBatch{Action: GuestBatchAction_Tags},
+			output: errors.New(GuestBatch_Error_TagsNotSet)},
+		{name: `Invalid tags duplicate`,
+			input:  GuestBatch{Action: GuestBatchAction_Tags, Tags: &GuestBatchTags{Remove: Tags{"a", "a"}}},
+			output: errors.New(Tags_Error_Duplicate)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.output, test.input.Validate())
+		})
+	}
+}
+
+func Test_GuestBatchResult(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  GuestBatchResult
+		failed []GuestID
+		err    string
+	}{
+		{name: `Success`,
+			input:  GuestBatchResult{100: nil, 101: nil},
+			failed: []GuestID{}},
+		{name: `Failed`,
+			input:  GuestBatchResult{102: errGuestBatchSkipped, 100: nil, 101: errors.New("VM is locked (backup)")},
+			failed: []GuestID{101, 102},
+			err:    "VM is locked (backup): ID 101\nskipped after an earlier failure: ID 102"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.failed, test.input.Failed())
+			if test.err == "" {
+				require.NoError(t, test.input.Err())
+				return
+			}
+			require.EqualError(t, test.input.Err(), test.err)
+		})
+	}
+}
+
+func Test_GuestInterface_Batch(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		guests []VmRef
+		err    error
+	}{
+		{name: `No guests`,
+			err: errors.New(GuestBatch_Error_NoGuests)},
+		{name: `Invalid guest`,
+			guests: []VmRef{*NewVmRef(100), *NewVmRef(0)},
+			err:    errors.New(GuestID_Error_Minimum)},
+		{name: `Duplicate guest`,
+			guests: []VmRef{*NewVmRef(100), *NewVmRef(101), *NewVmRef(100)},
+			err:    &errorWrapper[GuestID]{err: errors.New(GuestBatch_Error_DuplicateGuest), id: 100}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			server, c := testMockServerInit(t)
+			result, err := c.New().Guest.Batch(context.Background(), test.guests, GuestBatch{Action: GuestBatchAction_Start})
+			require.Equal(t, test.err, err)
+			require.Nil(t, result)
+			server.Clear(t)
+		})
+	}
+}
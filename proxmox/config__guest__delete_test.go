@@ -31,21 +31,21 @@ func Test_GuestInterface_Delete(t *testing.T) {
 		{name: `failed to list guests`,
 			guest:    VmRef{vmId: 100},
 			requests: mockServer.RequestsError("/cluster/resources?type=vm", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `get config lxc failed`,
 			guest: VmRef{vmId: 200},
 			requests: mockServer.Append(
 				mockServer.RequestsGetJsonData("/cluster/resources?type=vm", []any{
 					map[string]any{"vmid": float64(200), "node": "pve2", "type": "lxc"}}),
 				mockServer.RequestsError("/nodes/pve2/lxc/200/config", mockServer.GET, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `get config qemu failed`,
 			guest: VmRef{vmId: 200},
 			requests: mockServer.Append(
 				mockServer.RequestsGetJsonData("/cluster/resources?type=vm", []any{
 					map[string]any{"vmid": float64(200), "node": "test", "type": "qemu"}}),
 				mockServer.RequestsError("/nodes/test/qemu/200/config", mockServer.GET, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `get config lxc protectted`,
 			guest: VmRef{vmId: 200},
 			requests: mockServer.Append(
@@ -161,7 +161,7 @@ func Test_GuestInterface_Delete(t *testing.T) {
 						"hastate": ""}}),
 				mockServer.RequestsGetJsonData("/nodes/test/qemu/200/config", map[string]any{}),
 				mockServer.RequestsError("/cluster/ha/resources/200", mockServer.DELETE, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `success lxc all issues`,
 			guest:   VmRef{vmId: 200},
 			deleted: true,
@@ -246,7 +246,7 @@ func Test_GuestInterface_Delete(t *testing.T) {
 						"status": "stopped"}}),
 				mockServer.RequestsGetJsonData("/nodes/test/qemu/200/config", map[string]any{}),
 				mockServer.RequestsError("/nodes/test/qemu/200", mockServer.DELETE, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `error api qemu | stopped | no HA`,
 			guest: VmRef{vmId: 200},
 			requests: mockServer.Append(
@@ -269,7 +269,7 @@ func Test_GuestInterface_Delete(t *testing.T) {
 					map[string]any{"vmid": float64(200), "node": "test", "type": "qemu"}}),
 				mockServer.RequestsGetJsonData("/nodes/test/qemu/200/config", map[string]any{}),
 				mockServer.RequestsError("/version", mockServer.GET, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `error | lxc all issues | stop error`,
 			guest: VmRef{vmId: 200},
 			requests: mockServer.Append(
@@ -285,7 +285,7 @@ func Test_GuestInterface_Delete(t *testing.T) {
 				mockServer.RequestsVersion("7.255.255"),
 				// in loop
 				mockServer.RequestsError("/nodes/test/lxc/200/status/stop", mockServer.POST, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `error api | lxc all issues | stop error`,
 			guest: VmRef{vmId: 200},
 			requests: mockServer.Append(
@@ -322,7 +322,7 @@ func Test_GuestInterface_Delete(t *testing.T) {
 				mockServer.RequestsGetJsonData("/nodes/test/tasks/"+mockServer.Path(UPID("test", "qmstop", GuestID(200)))+"/status",
 					map[string]any{"exitstatus": string("OK")}),
 				mockServer.RequestsError("/nodes/test/qemu/200", mockServer.DELETE, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `error api | qemu all issues | delete error`,
 			guest: VmRef{vmId: 200},
 			requests: mockServer.Append(
@@ -0,0 +1,347 @@
+package proxmox
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PermissionCheck is the outcome of comparing the privileges an action requires with the privileges of the user.
+type PermissionCheck struct {
+	Required []Permission `json:"required"`
+	Missing  []Permission `json:"missing"` // Empty when the user has all required privileges.
+}
+
+// Err returns a permission error for every missing privilege, or nil when nothing is missing.
+func (check PermissionCheck) Err() error {
+	if len(check.Missing) == 0 {
+		return nil
+	}
+	errs := make([]error, len(check.Missing))
+	for i := range check.Missing {
+		errs[i] = check.Missing[i].error()
+	}
+	return errors.Join(errs...)
+}
+
+// permissionCheck compares the required permissions with the cached permissions of the user.
+func (c *Client) permissionCheck(ctx context.Context, required []Permission) (PermissionCheck, error) {
+	check := PermissionCheck{Required: required, Missing: []Permission{}}
+	if c.Username == "root@pam" { // no permissions check for root
+		return check, nil
+	}
+	permissions, err := c.cachedPermissions(ctx, Permission{}.buildPathList(required))
+	if err != nil {
+		return PermissionCheck{}, err
+	}
+	for _, perm := range required {
+		if perm.check(permissions) != nil {
+			check.Missing = append(check.Missing, perm)
+		}
+	}
+	return check, nil
+}
+
+// The SDN zone Proxmox VE uses for bridges that are not managed by SDN.
+const guestPermissionLocalZone = "localnetwork"
+
+// guestPermissions collects the permissions needed to change a guest.
+// The privileges are derived from the settings sent to the API, the same way Proxmox VE checks them.
+// Settings only root@pam may change, like hookscripts and raw PCI devices, have no privilege and are not listed.
+type guestPermissions struct {
+	id    GuestID
+	perms []Permission
+}
+
+// add adds the permission, every privilege is listed separately and only once per path.
+func (p *guestPermissions) add(category PermissionCategory, item PermissionItem, privileges Privileges) {
+	perm := Permission{Category: category, Item: item, Privileges: privileges}
+	if !slices.Contains(p.perms, perm) {
+		p.perms = append(p.perms, perm)
+	}
+}
+
+// bridge adds the privilege to use the bridge, and the vlan when one is set, of a network interface.
+func (p *guestPermissions) bridge(settings string) {
+	var bridge, tag string
+	for _, e := range strings.Split(settings, ",") {
+		key, value, _ := strings.Cut(e, "=")
+		switch key {
+		case "bridge":
+			bridge = value
+		case "tag":
+			tag = value
+		}
+	}
+	if bridge == "" {
+		return
+	}
+	item := guestPermissionLocalZone + "/" + bridge
+	if tag != "" {
+		item += "/" + tag
+	}
+	p.add(PermissionCategory_Zone, PermissionItem(item), Privileges{SDNUse: true})
+}
+
+// mapping adds the privilege to use the resource mapping of a hostpci, usb or virtiofs setting.
+// kind is the type of mapping (dir, pci, usb), key is the setting that holds the ID of the mapping.
+// Returns false when the setting does not use a mapping.
+func (p *guestPermissions) mapping(kind, key, settings string) bool {
+	for i, e := range strings.Split(settings, ",") {
+		k, id, isSet := strings.Cut(e, "=")
+		if !isSet && i == 0 && key == qemuApiSettingVirtioFSMappingID { // dirid is the default key of virtiofs
+			k, id = key, e
+		}
+		if k == key && id != "" {
+			p.add(PermissionCategory_Mapping, PermissionItem(kind+"/"+id), Privileges{MappingUse: true})
+			return true
+		}
+	}
+	return false
+}
+
+func (p *guestPermissions) guest(privileges Privileges) {
+	var item PermissionItem
+	if p.id != 0 {
+		item = PermissionItem(p.id.String())
+	}
+	p.add(PermissionCategory_Guest, item, privileges)
+}
+
+// list returns the permissions in order of their path.
+func (p *guestPermissions) list() []Permission {
+	perms := slices.Clone(p.perms)
+	if perms == nil {
+		perms = []Permission{}
+	}
+	slices.SortStableFunc(perms, func(a, b Permission) int {
+		return cmp.Or(
+			cmp.Compare(a.Category.path().append(a.Item), b.Category.path().append(b.Item)),
+			cmp.Compare(a.Privileges.String(), b.Privileges.String()))
+	})
+	return perms
+}
+
+// request adds the privileges required by every setting in the body of a config update.
+func (p *guestPermissions) request(body *[]byte, setting func(key, value string)) {
+	settings, deletes := guestApiSettings(body)
+	for _, key := range deletes {
+		setting(key, "")
+	}
+	for key, value := range settings {
+		setting(key, value)
+	}
+}
+
+func (p *guestPermissions) storage(storage string, privileges Privileges) {
+	if storage != "" {
+		p.add(PermissionCategory_Storage, PermissionItem(storage), privileges)
+	}
+}
+
+// volume adds the storage privileges of a disk setting.
+// Allocating a new volume requires space on the storage, using an ISO image requires read access.
+func (p *guestPermissions) volume(settings string) {
+	volume, _, _ := strings.Cut(settings, ",")
+	storage, path, ok := strings.Cut(volume, ":")
+	if !ok {
+		return
+	}
+	if strings.HasPrefix(path, "iso/") {
+		p.storage(storage, Privileges{DatastoreAudit: true})
+		return
+	}
+	if _, err := strconv.ParseFloat(path, 64); err == nil || path == "cloudinit" {
+		p.storage(storage, Privileges{DatastoreAllocateSpace: true})
+	}
+}
+
+// lxcSetting adds the privileges Proxmox VE requires to change the LXC setting.
+func (p *guestPermissions) lxcSetting(key, value string) {
+	switch strings.TrimRightFunc(key, unicode.IsDigit) {
+	case "cores", "cpulimit", "cpuunits":
+		p.guest(Privileges{VMConfigCPU: true})
+	case "memory", "swap":
+		p.guest(Privileges{VMConfigMemory: true})
+	case "mp", "rootfs", "unused":
+		p.guest(Privileges{VMConfigDisk: true})
+		p.volume(value)
+	case "hostname", "nameserver", "searchdomain":
+		p.guest(Privileges{VMConfigNetwork: true})
+	case "net":
+		p.guest(Privileges{VMConfigNetwork: true})
+		p.bridge(value)
+	case "arch", "cmode", "console", "description", "features", "onboot", "ostype", "protection", "startup", "tags", "timezone", "tty":
+		p.guest(Privileges{VMConfigOptions: true})
+	}
+}
+
+// qemuSetting adds the privileges Proxmox VE requires to change the QEMU setting.
+func (p *guestPermissions) qemuSetting(key, value string) {
+	switch strings.TrimRightFunc(key, unicode.IsDigit) {
+	case "affinity", "cores", "cpu", "cpulimit", "cpuunits", "numa", "smp", "sockets", "vcpus":
+		p.guest(Privileges{VMConfigCPU: true})
+	case "balloon", "memory", "shares":
+		p.guest(Privileges{VMConfigMemory: true})
+	case "acpi", "audio", "hotplug", "kvm", "machine", "parallel", "rng", "scsihw", "serial", "smbios", "tablet", "vga", "watchdog":
+		p.guest(Privileges{VMConfigHWType: true})
+	case "hostpci": // raw PCI devices can only be passed through by root@pam
+		if value == "" || p.mapping("pci", "mapping", value) {
+			p.guest(Privileges{VMConfigHWType: true})
+		}
+	case "usb":
+		p.guest(Privileges{VMConfigHWType: true})
+		p.mapping("usb", "mapping", value)
+	case "virtiofs":
+		p.guest(Privileges{VMConfigHWType: true})
+		p.mapping("dir", qemuApiSettingVirtioFSMappingID, value)
+	case "agent", "autostart", "bios", "description", "keyboard", "localtime", "migrate_downtime", "migrate_speed", "name",
+		"onboot", "ostype", "protection", "reboot", "startdate", "startup", "tags", "tdf", "template":
+		p.guest(Privileges{VMConfigOptions: true})
+	case "boot", "bootdisk", "unused", "vmstatestorage":
+		p.guest(Privileges{VMConfigDisk: true})
+	case "efidisk", "tpmstate":
+		p.guest(Privileges{VMConfigDisk: true})
+		p.volume(value)
+	case "ide", "sata", "scsi", "virtio":
+		if strings.Contains(value, "media=cdrom") {
+			p.guest(Privileges{VMConfigCDROM: true})
+		} else {
+			p.guest(Privileges{VMConfigDisk: true})
+		}
+		p.volume(value)
+	case "cicustom", "cipassword", "citype", "ciupgrade", "ciuser", "ipconfig", "nameserver", "searchdomain", "sshkeys":
+		p.guest(Privileges{VMConfigCloudinit: true})
+	case "net":
+		p.guest(Privileges{VMConfigNetwork: true})
+		p.bridge(value)
+	}
+}
+
+// move adds the privileges to move or resize disks.
+func (p *guestPermissions) move(storages []string, resize bool) {
+	if len(storages) != 0 || resize {
+		p.guest(Privileges{VMConfigDisk: true})
+	}
+	for _, storage := range storages {
+		p.storage(storage, Privileges{DatastoreAllocateSpace: true})
+	}
+}
+
+// pool adds the privileges to move the guest from the current pool to the new pool.
+func (p *guestPermissions) pool(pool PoolName, current *PoolName) {
+	if current != nil && *current == pool {
+		return
+	}
+	if pool != "" {
+		p.add(PermissionCategory_Pool, PermissionItem(pool), Privileges{PoolAllocate: true})
+	}
+	if current != nil && *current != "" {
+		p.add(PermissionCategory_Pool, PermissionItem(*current), Privileges{PoolAllocate: true})
+	}
+}
+
+// permissions returns the permissions required to update the guest from the current config.
+func (config ConfigLXC) permissions(id GuestID, current ConfigLXC) []Permission {
+	p := guestPermissions{id: id}
+	var storages []string
+	var resize bool
+	if config.BootMount != nil && current.BootMount != nil {
+		changes := config.BootMount.markMountChanges_Unsafe(current.BootMount)
+		for _, e := range changes.move {
+			storages = append(storages, e.storage)
+		}
+		resize = len(changes.resize) != 0
+	}
+	if config.Mounts != nil && current.Mounts != nil {
+		changes := config.Mounts.markMountChanges(current.Mounts)
+		for _, e := range changes.move {
+			storages = append(storages, e.storage)
+		}
+		resize = resize || len(changes.resize) != 0
+	}
+	p.move(storages, resize)
+	params, body := config.mapToApiUpdate(current)
+	p.request(combineParamsAndBody(params, body), p.lxcSetting)
+	if config.State != nil {
+		p.guest(Privileges{VMPowerMgmt: true})
+	}
+	if config.Pool != nil {
+		p.pool(*config.Pool, current.Pool)
+	}
+	return p.list()
+}
+
+// CheckUpdatePermissions reports which privileges updating the guest requires and which of them the user is missing, without changing anything.
+func (config ConfigLXC) CheckUpdatePermissions(ctx context.Context, vmr *VmRef, c *Client) (PermissionCheck, error) {
+	return config.checkUpdatePermissions(ctx, vmr, c, true)
+}
+
+func (config ConfigLXC) checkUpdatePermissions(ctx context.Context, vmr *VmRef, c *Client, validate bool) (PermissionCheck, error) {
+	if err := c.checkInitialized(); err != nil {
+		return PermissionCheck{}, err
+	}
+	if err := c.CheckVmRef(ctx, vmr); err != nil {
+		return PermissionCheck{}, err
+	}
+	raw, err := guestGetLxcRawConfig_Unsafe(ctx, vmr, c.new().apiGet())
+	if err != nil {
+		return PermissionCheck{}, err
+	}
+	current := raw.get(*vmr)
+	if validate {
+		if err = config.Validate(current); err != nil {
+			return PermissionCheck{}, err
+		}
+	}
+	return c.permissionCheck(ctx, config.permissions(vmr.vmId, *current))
+}
+
+// permissions returns the permissions required to update the guest from the current config.
+func (config ConfigQemu) permissions(id GuestID, currentLegacy *ConfigQemu, update configQemuUpdate, version Version) []Permission {
+	p := guestPermissions{id: id}
+	changes, efiDisk := config.markUpdate(currentLegacy, &update, &strings.Builder{})
+	storages := make([]string, 0, len(changes.Move)+1)
+	for _, e := range changes.Move {
+		storages = append(storages, e.Storage)
+	}
+	if efiDisk != nil {
+		storages = append(storages, efiDisk.Storage)
+	}
+	p.move(storages, len(changes.Resize) != 0)
+	params, body := config.mapToApiUpdate(currentLegacy, update, version)
+	p.request(combineParamsAndBody(params, body), p.qemuSetting)
+	if config.Node != nil && currentLegacy.Node != nil && *config.Node != *currentLegacy.Node {
+		p.guest(Privileges{VMMigrate: true})
+	}
+	if config.State != nil {
+		p.guest(Privileges{VMPowerMgmt: true})
+	}
+	if config.Pool != nil {
+		p.pool(*config.Pool, currentLegacy.Pool)
+	}
+	if config.HaState != currentLegacy.HaState || config.HaGroup != currentLegacy.HaGroup {
+		p.add(PermissionCategory_Root, permissionItemEmpty, Privileges{SysConsole: true})
+	}
+	return p.list()
+}
+
+func (c *qemuGuestClient) CheckUpdatePermissions(ctx context.Context, vmr VmRef, config ConfigQemu) (PermissionCheck, error) {
+	return c.checkUpdatePermissions(ctx, vmr, config, true)
+}
+
+func (c *qemuGuestClient) CheckUpdatePermissionsNoCheck(ctx context.Context, vmr VmRef, config ConfigQemu) (PermissionCheck, error) {
+	return c.checkUpdatePermissions(ctx, vmr, config, false)
+}
+
+func (c *qemuGuestClient) checkUpdatePermissions(ctx context.Context, vmr VmRef, config ConfigQemu, validate bool) (PermissionCheck, error) {
+	rawConfig, currentLegacy, version, err := c.current(ctx, &vmr, config, validate)
+	if err != nil {
+		return PermissionCheck{}, err
+	}
+	return c.oldClient.permissionCheck(ctx, config.permissions(vmr.vmId, currentLegacy, configQemuUpdate{raw: rawConfig}, version))
+}
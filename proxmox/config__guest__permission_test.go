@@ -0,0 +1,192 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Telmate/proxmox-api-go/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PermissionCheck_Err(t *testing.T) {
+	t.Parallel()
+	disk := Permission{Category: PermissionCategory_Guest, Item: "100", Privileges: Privileges{VMConfigDisk: true}}
+	storage := Permission{Category: PermissionCategory_Storage, Item: "local", Privileges: Privileges{DatastoreAllocateSpace: true}}
+	tests := []struct {
+		name   string
+		input  PermissionCheck
+		output error
+	}{
+		{name: `Nothing missing`,
+			input: PermissionCheck{Required: []Permission{disk}, Missing: []Permission{}}},
+		{name: `Missing`,
+			input:  PermissionCheck{Required: []Permission{disk, storage}, Missing: []Permission{disk, storage}},
+			output: errors.Join(disk.error(), storage.error())},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := test.input.Err()
+			require.Equal(t, test.output, err)
+			if err != nil {
+				require.ErrorIs(t, err, Error.PermissionDenied())
+			}
+		})
+	}
+}
+
+func Test_Client_permissionCheck(t *testing.T) {
+	t.Parallel()
+	disk := Permission{Category: PermissionCategory_Guest, Item: "100", Privileges: Privileges{VMConfigDisk: true}}
+	storage := Permission{Category: PermissionCategory_Storage, Item: "local", Privileges: Privileges{DatastoreAllocateSpace: true}}
+	mapping := Permission{Category: PermissionCategory_Mapping, Item: "dir/share", Privileges: Privileges{MappingUse: true}}
+	required := []Permission{disk, storage, mapping}
+	permissions := map[permissionPath]privileges{
+		"/vms/100":           {VMConfigDisk: privilegeTrue},
+		"/storage/local":     {DatastoreAudit: privilegeTrue},
+		"/mapping/dir/share": {MappingUse: privilegeTrue}}
+	tests := []struct {
+		name     string
+		username string
+		output   PermissionCheck
+	}{
+		{name: `root`,
+			username: "root@pam",
+			output:   PermissionCheck{Required: required, Missing: []Permission{}}},
+		{name: `user`,
+			username: "test@pve",
+			output:   PermissionCheck{Required: required, Missing: []Permission{storage}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			c := &Client{Username: test.username, permissions: permissions}
+			check, err := c.permissionCheck(context.Background(), required)
+			require.NoError(t, err)
+			require.Equal(t, test.output, check)
+		})
+	}
+}
+
+func Test_guestPermissions_qemuSetting(t *testing.T) {
+	t.Parallel()
+	guest := func(privileges Privileges) Permission {
+		return Permission{Category: PermissionCategory_Guest, Item: "100", Privileges: privileges}
+	}
+	tests := []struct {
+		name   string
+		input  string
+		output []Permission
+	}{
+		{name: `Empty`,
+			output: []Permission{}},
+		{name: `Digest`,
+			input:  "digest=af064923bbf2301596aac4c273ba32178ebc4a96",
+			output: []Permission{}},
+		{name: `Disk allocate`,
+			input: "scsi0=local-lvm%3A32%2Cformat%3Draw",
+			output: []Permission{
+				{Category: PermissionCategory_Storage, Item: "local-lvm", Privileges: Privileges{DatastoreAllocateSpace: true}},
+				guest(Privileges{VMConfigDisk: true})}},
+		{name: `Disk existing`,
+			input:  "virtio1=local-lvm%3Avm-100-disk-1",
+			output: []Permission{guest(Privileges{VMConfigDisk: true})}},
+		{name: `CD-ROM iso`,
+			input: "ide2=local%3Aiso%2Fdebian.iso%2Cmedia%3Dcdrom",
+			output: []Permission{
+				{Category: PermissionCategory_Storage, Item: "local", Privileges: Privileges{DatastoreAudit: true}},
+				guest(Privileges{VMConfigCDROM: true})}},
+		{name: `Network`,
+			input: "net0=virtio%3DBC%3A24%3A11%3AE2%3A20%3A5B%2Cbridge%3Dvmbr0%2Ctag%3D10",
+			output: []Permission{
+				{Category: PermissionCategory_Zone, Item: "localnetwork/vmbr0/10", Privileges: Privileges{SDNUse: true}},
+				guest(Privileges{VMConfigNetwork: true})}},
+		{name: `Delete`,
+			input: "delete=cores%2Cmemory%2Ccores%2Cciuser",
+			output: []Permission{
+				guest(Privileges{VMConfigCPU: true}),
+				guest(Privileges{VMConfigCloudinit: true}),
+				guest(Privileges{VMConfigMemory: true})}},
+		{name: `Mapping PCI`,
+			input: "hostpci0=mapping%3Dgpu%2Cpcie%3D1",
+			output: []Permission{
+				{Category: PermissionCategory_Mapping, Item: "pci/gpu", Privileges: Privileges{MappingUse: true}},
+				guest(Privileges{VMConfigHWType: true})}},
+		{name: `Mapping PCI raw`,
+			input:  "hostpci0=0000%3A01%3A00%2Cpcie%3D1",
+			output: []Permission{}},
+		{name: `Mapping PCI delete`,
+			input:  "delete=hostpci0",
+			output: []Permission{guest(Privileges{VMConfigHWType: true})}},
+		{name: `Mapping USB`,
+			input: "usb0=mapping%3Dkeyboard%2Cusb3%3D1",
+			output: []Permission{
+				{Category: PermissionCategory_Mapping, Item: "usb/keyboard", Privileges: Privileges{MappingUse: true}},
+				guest(Privileges{VMConfigHWType: true})}},
+		{name: `Mapping USB spice`,
+			input:  "usb1=spice",
+			output: []Permission{guest(Privileges{VMConfigHWType: true})}},
+		{name: `Mapping virtiofs`,
+			input: "virtiofs0=dirid%3Dshare%2Ccache%3Dnever&virtiofs1=data",
+			output: []Permission{
+				{Category: PermissionCategory_Mapping, Item: "dir/data", Privileges: Privileges{MappingUse: true}},
+				{Category: PermissionCategory_Mapping, Item: "dir/share", Privileges: Privileges{MappingUse: true}},
+				guest(Privileges{VMConfigHWType: true})}},
+		{name: `Options`,
+			input: "name=test&onboot=1&vga=std",
+			output: []Permission{
+				guest(Privileges{VMConfigHWType: true}),
+				guest(Privileges{VMConfigOptions: true})}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			p := guestPermissions{id: 100}
+			body := []byte(test.input)
+			p.request(&body, p.qemuSetting)
+			require.Equal(t, test.output, p.list())
+		})
+	}
+}
+
+func Test_ConfigLXC_permissions(t *testing.T) {
+	t.Parallel()
+	guest := func(privileges Privileges) Permission {
+		return Permission{Category: PermissionCategory_Guest, Item: "100", Privileges: privileges}
+	}
+	pool := func(name PoolName) Permission {
+		return Permission{Category: PermissionCategory_Pool, Item: PermissionItem(name), Privileges: Privileges{PoolAllocate: true}}
+	}
+	current := ConfigLXC{
+		Description: util.Pointer("test"),
+		Memory:      util.Pointer(LxcMemory(512)),
+		Pool:        util.Pointer(PoolName("old"))}
+	tests := []struct {
+		name   string
+		input  ConfigLXC
+		output []Permission
+	}{
+		{name: `No changes`,
+			input:  current,
+			output: []Permission{}},
+		{name: `Memory`,
+			input:  ConfigLXC{Memory: util.Pointer(LxcMemory(1024))},
+			output: []Permission{guest(Privileges{VMConfigMemory: true})}},
+		{name: `Delete description`,
+			input:  ConfigLXC{Description: util.Pointer("")},
+			output: []Permission{guest(Privileges{VMConfigOptions: true})}},
+		{name: `Pool`,
+			input:  ConfigLXC{Pool: util.Pointer(PoolName("new"))},
+			output: []Permission{pool("new"), pool("old")}},
+		{name: `State`,
+			input:  ConfigLXC{State: util.Pointer(PowerStateRunning)},
+			output: []Permission{guest(Privileges{VMPowerMgmt: true})}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.output, test.input.permissions(100, current))
+		})
+	}
+}
@@ -1,9 +1,6 @@
 package proxmox
 
-import (
-	"context"
-	"errors"
-)
+import "context"
 
 func (c *guestClient) Reboot(ctx context.Context, vmr VmRef) error {
 	if _, err := vmr.check_unsafe(ctx, c.api); err != nil {
@@ -74,7 +71,7 @@ func (c *guestClient) Stop(ctx context.Context, vmr VmRef, overrule bool) error
 		if err != nil {
 			return err
 		}
-		if version.Major >= 8 {
+		if CapabilityOverruleShutdown.SupportedBy(version) {
 			return c.StopOverruleNoCheck(ctx, vmr)
 		}
 	}
@@ -90,8 +87,8 @@ func (c *guestClient) StopOverrule(ctx context.Context, vmr VmRef) error {
 	if err != nil {
 		return err
 	}
-	if version.Major < 8 {
-		return errors.New("overrule stop is only supported on Proxmox VE 8.0 and higher")
+	if err = CapabilityOverruleShutdown.check(version); err != nil {
+		return err
 	}
 	raw, err := vmr.check_unsafe(ctx, c.api)
 	if err != nil {
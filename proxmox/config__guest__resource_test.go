@@ -2,7 +2,6 @@ package proxmox
 
 import (
 	"context"
-	"errors"
 	"testing"
 	"time"
 
@@ -144,7 +143,7 @@ func Test_guestClient_List(t *testing.T) {
 					}}})},
 		{name: `500 internal server error`,
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
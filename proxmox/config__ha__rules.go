@@ -13,6 +13,7 @@ import (
 	"github.com/Telmate/proxmox-api-go/internal/util"
 )
 
+// Deprecated: the error is now returned by CapabilityHaRules.
 const HaRule_Error_VersionTooLow = "HA rules require Proxmox VE 9.0 or higher"
 
 func ListHaRules(ctx context.Context, c *Client) (HaRules, error) { return c.new().haListRules(ctx) }
@@ -920,8 +921,5 @@ func haVersionCheck(ctx context.Context, c *clientNewTest) error {
 	if err != nil {
 		return err
 	}
-	if version.Encode() < version_9_0_0 {
-		return errors.New(HaRule_Error_VersionTooLow)
-	}
-	return nil
+	return CapabilityHaRules.check(version)
 }
@@ -12,6 +12,127 @@ import (
 	"github.com/Telmate/proxmox-api-go/internal/util"
 )
 
+type (
+	LxcGuestInterface interface {
+		// CheckUpdatePermissions reports which privileges the update requires and which of them the user is missing, without changing anything.
+		CheckUpdatePermissions(ctx context.Context, vmr VmRef, config ConfigLXC) (PermissionCheck, error)
+		CheckUpdatePermissionsNoCheck(ctx context.Context, vmr VmRef, config ConfigLXC) (PermissionCheck, error)
+
+		// Clone creates a new container from the guest, and returns the reference of the new container.
+		Clone(ctx context.Context, vmr VmRef, target CloneLxcTarget) (*VmRef, error)
+		CloneNoCheck(ctx context.Context, vmr VmRef, target CloneLxcTarget) (*VmRef, error)
+
+		Create(context.Context, ConfigLXC) (*VmRef, error)
+		CreateNoCheck(context.Context, ConfigLXC) (*VmRef, error)
+
+		// Read returns the configuration of the guest, including pending changes.
+		Read(context.Context, VmRef) (RawConfigLXC, error)
+		ReadNoCheck(context.Context, VmRef) (RawConfigLXC, error)
+
+		// Template converts the guest into a template, the guest has to be stopped.
+		Template(context.Context, VmRef) error
+		TemplateNoCheck(context.Context, VmRef) error
+
+		// When allowRestart is false an error is returned if the update requires a reboot or shutdown.
+		Update(ctx context.Context, vmr VmRef, allowRestart bool, config ConfigLXC) error
+		UpdateNoCheck(ctx context.Context, vmr VmRef, allowRestart bool, config ConfigLXC) error
+	}
+
+	lxcGuestClient struct {
+		api       *clientAPI
+		oldClient *Client
+	}
+)
+
+var _ LxcGuestInterface = (*lxcGuestClient)(nil)
+
+func (c *lxcGuestClient) check(ctx context.Context, vmr *VmRef) error {
+	_, err := vmr.checkType_unsafe(ctx, c.api, GuestLxc)
+	return err
+}
+
+func (c *lxcGuestClient) CheckUpdatePermissions(ctx context.Context, vmr VmRef, config ConfigLXC) (PermissionCheck, error) {
+	if err := c.check(ctx, &vmr); err != nil {
+		return PermissionCheck{}, err
+	}
+	return config.checkUpdatePermissions(ctx, &vmr, c.oldClient, true)
+}
+
+func (c *lxcGuestClient) CheckUpdatePermissionsNoCheck(ctx context.Context, vmr VmRef, config ConfigLXC) (PermissionCheck, error) {
+	vmr.SetVmType(GuestLxc)
+	return config.checkUpdatePermissions(ctx, &vmr, c.oldClient, false)
+}
+
+func (c *lxcGuestClient) Clone(ctx context.Context, vmr VmRef, target CloneLxcTarget) (*VmRef, error) {
+	if err := target.Validate(); err != nil {
+		return nil, err
+	}
+	if err := c.check(ctx, &vmr); err != nil {
+		return nil, err
+	}
+	return vmr.cloneLxc_Unsafe(ctx, target, c.oldClient)
+}
+
+func (c *lxcGuestClient) CloneNoCheck(ctx context.Context, vmr VmRef, target CloneLxcTarget) (*VmRef, error) {
+	vmr.SetVmType(GuestLxc)
+	return vmr.cloneLxc_Unsafe(ctx, target, c.oldClient)
+}
+
+func (c *lxcGuestClient) Create(ctx context.Context, config ConfigLXC) (*VmRef, error) {
+	if err := config.Validate(nil); err != nil {
+		return nil, err
+	}
+	vmr, err := config.CreateNoCheck(ctx, c.oldClient)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.oldClient.insertCachedPermission(ctx, permissionPath(permissionCategory_GuestPath)+"/"+permissionPath(vmr.vmId.String())); err != nil {
+		return nil, err
+	}
+	return vmr, nil
+}
+
+func (c *lxcGuestClient) CreateNoCheck(ctx context.Context, config ConfigLXC) (*VmRef, error) {
+	return config.CreateNoCheck(ctx, c.oldClient)
+}
+
+func (c *lxcGuestClient) Read(ctx context.Context, vmr VmRef) (RawConfigLXC, error) {
+	if err := c.check(ctx, &vmr); err != nil {
+		return nil, err
+	}
+	return c.ReadNoCheck(ctx, vmr)
+}
+
+func (c *lxcGuestClient) ReadNoCheck(ctx context.Context, vmr VmRef) (RawConfigLXC, error) {
+	vmr.SetVmType(GuestLxc)
+	raw, err := guestGetLxcRawConfig_Unsafe(ctx, &vmr, c.api)
+	if err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (c *lxcGuestClient) Template(ctx context.Context, vmr VmRef) error {
+	return vmr.template_Unsafe(ctx, c.api, GuestLxc)
+}
+
+func (c *lxcGuestClient) TemplateNoCheck(ctx context.Context, vmr VmRef) error {
+	vmr.SetVmType(GuestLxc)
+	return c.api.createGuestTemplate(ctx, &vmr)
+}
+
+func (c *lxcGuestClient) Update(ctx context.Context, vmr VmRef, allowRestart bool, config ConfigLXC) error {
+	if err := c.check(ctx, &vmr); err != nil {
+		return err
+	}
+	return config.Update(ctx, allowRestart, &vmr, c.oldClient)
+}
+
+func (c *lxcGuestClient) UpdateNoCheck(ctx context.Context, vmr VmRef, allowRestart bool, config ConfigLXC) error {
+	vmr.SetVmType(GuestLxc)
+	return config.UpdateNoCheck(ctx, allowRestart, &vmr, c.oldClient)
+}
+
 type LxcCpuArchitecture string
 
 func (arch LxcCpuArchitecture) String() string { return string(arch) } // String is for fmt.Stringer.
@@ -435,7 +556,7 @@ func (config ConfigLXC) update_Unsafe(
 		urlBuilder.WriteString("/lxc/")
 		urlBuilder.WriteString(vmr.vmId.String())
 		urlBuilder.WriteString("/config")
-		if err = ca.putRawRetry(ctx, urlBuilder.String(), body, 3); err != nil {
+		if err = ca.putRawRetry(ctx, urlBuilder.String(), body); err != nil {
 			return err
 		}
 		if currentState == PowerStateRunning || currentState == PowerStateUnknown { // If the guest is running, we have to check if it has pending changes
@@ -276,7 +276,7 @@ func (config ConfigPool) mapToApiUpdate(current *rawPoolInfo, currentGuests map[
 					builder.WriteString(array.CSV(storagesRemove))
 				}
 			} else if len(guestsAdd)+len(storagesAdd) > 0 {
-				if len(guestsAdd) > 0 && version.Major >= 8 { // We don't know if the guests are members of another pool
+				if len(guestsAdd) > 0 && CapabilityPoolAllowMove.SupportedBy(version) { // We don't know if the guests are members of another pool
 					// state = poolUpdateStateAdded
 					builder.WriteString("&allow-move=1&" + poolApiKeyGuests + "=")
 					builder.WriteString(array.CSV(guestsAdd))
@@ -322,7 +322,7 @@ func (config ConfigPool) mapToApi(current *ConfigPool) map[string]any {
 }
 
 func (config ConfigPool) create(ctx context.Context, c *clientAPI, oldClient *Client) error {
-	if err := c.postRawRetry(ctx, "/pools", config.mapToApiCreate(), 3); err != nil {
+	if err := c.postRawRetry(ctx, "/pools", config.mapToApiCreate()); err != nil {
 		return err
 	}
 	if config.Guests != nil || config.Storages != nil {
@@ -775,7 +775,7 @@ func (pool PoolName) addGuests(
 			return err
 		}
 	}
-	if version.Major >= 8 {
+	if CapabilityPoolAllowMove.SupportedBy(version) {
 		return pool.addGuestsV8(ctx, c, guests, storages)
 	}
 	raw, err := c.listGuestResources(ctx)
@@ -829,7 +829,7 @@ func (pool PoolName) AddGuestsNoCheck(ctx context.Context, c *Client, guestIDs [
 
 func (pool PoolName) delete(ctx context.Context, c *clientAPI) (bool, error) {
 	url := "/pools/" + pool.String()
-	if err := c.deleteRetry(ctx, url, 3); err != nil {
+	if err := c.deleteRetry(ctx, url); err != nil {
 		if apiErr, ok := err.(*ApiError); ok {
 			const prefix = "delete pool failed: pool '"
 			const prefixLen = len(prefix)
@@ -838,7 +838,7 @@ func (pool PoolName) delete(ctx context.Context, c *clientAPI) (bool, error) {
 					if err = pool.empty(ctx, c); err != nil {
 						return false, err
 					}
-					if err = c.deleteRetry(ctx, url, 3); err != nil {
+					if err = c.deleteRetry(ctx, url); err != nil {
 						return false, err
 					}
 					return true, nil
@@ -906,7 +906,7 @@ func (pool PoolName) GetNoCheck(ctx context.Context, c *Client) (RawPoolInfo, er
 }
 
 func (pool PoolName) put(ctx context.Context, c *clientAPI, body *[]byte) error {
-	return c.putRawRetry(ctx, "/pools/"+pool.String(), body, 3)
+	return c.putRawRetry(ctx, "/pools/"+pool.String(), body)
 }
 
 // errExists is non-nil if the pool does not exist.
@@ -112,7 +112,7 @@ func Test_poolClient_AddMembers(t *testing.T) {
 						{"vmid": float64(500), "pool": "original_pool"},
 					}}),
 				mockServer.RequestsError("/pools/original_pool", mockServer.PUT, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Add Members 8.0-, list error`,
 			pool:     "test_pool",
 			guests:   []GuestID{100, 200, 300, 400},
@@ -120,7 +120,7 @@ func Test_poolClient_AddMembers(t *testing.T) {
 			requests: mockServer.Append(
 				mockServer.RequestsVersion("7.255.255"),
 				mockServer.RequestsError("/cluster/resources?type=vm", mockServer.GET, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Add Members 8.0+`,
 			pool:     "test_pool",
 			guests:   []GuestID{100, 200, 300},
@@ -137,7 +137,7 @@ func Test_poolClient_AddMembers(t *testing.T) {
 			guests:   []GuestID{100, 200, 300},
 			storages: []StorageName{"local", "nfs-1"},
 			requests: mockServer.RequestsError("/version", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Validate error PoolName`,
 			err: errors.New("PoolName cannot be empty")},
 		{name: `Validate error GuestID`,
@@ -261,7 +261,7 @@ func Test_poolClient_Create(t *testing.T) {
 		{name: `500 internal server error`,
 			pool:     ConfigPool{Name: "test_pool"},
 			requests: mockServer.RequestsError(path, mockServer.POST, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -297,7 +297,7 @@ func Test_poolClient_List(t *testing.T) {
 				}})},
 		{name: `500 internal server error`,
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -375,7 +375,7 @@ func Test_poolClient_Delete(t *testing.T) {
 					"vms":     "100,200",
 					"storage": "local"}),
 				mockServer.RequestsError(path, mockServer.DELETE, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Delete, errors while reading members`,
 			pool: "test_pool",
 			requests: mockServer.Append(
@@ -383,7 +383,7 @@ func Test_poolClient_Delete(t *testing.T) {
 					Code:    400,
 					Message: `{"message":"delete pool failed: pool 'test_pool' is not empty\n"}`}),
 				mockServer.RequestsError(path, mockServer.GET, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Delete, errors while reading members, does not exist`,
 			pool: "test_pool",
 			requests: mockServer.Append(
@@ -401,7 +401,7 @@ func Test_poolClient_Delete(t *testing.T) {
 		{name: `500 internal server error`,
 			pool:     "test_pool",
 			requests: mockServer.RequestsError(path, mockServer.DELETE, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -440,7 +440,7 @@ func Test_poolClient_Exists(t *testing.T) {
 		{name: `500 internal server error`,
 			pool:     "test_pool",
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -493,7 +493,7 @@ func Test_poolClient_Read(t *testing.T) {
 		{name: `500 internal server error`,
 			pool:     "test_pool",
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -551,7 +551,7 @@ func Test_poolClient_RemoveMembers(t *testing.T) {
 			pool:     "test_pool",
 			guests:   []GuestID{200},
 			requests: mockServer.RequestsError(path, mockServer.PUT, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -623,7 +623,7 @@ func Test_poolClient_Update(t *testing.T) {
 						"type": "qemu",
 						"vmid": 200}}}}),
 				mockServer.RequestsError("/version", mockServer.GET, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Update add guests, 8.0-`,
 			pool: ConfigPool{
 				Name:   "test_pool",
@@ -671,7 +671,7 @@ func Test_poolClient_Update(t *testing.T) {
 						"vmid": 200}}}}),
 				mockServer.RequestsVersion("8.0.0"),
 				mockServer.RequestsError("/pools/test_pool", mockServer.PUT, 500, 3)),
-			err: errors.New(mockServer.InternalServerError)},
+			err: &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Update remove storage and update comment`,
 			pool: ConfigPool{
 				Name:     "test_pool",
@@ -781,7 +781,7 @@ func Test_poolClient_Update(t *testing.T) {
 				Name:   "test_pool",
 				Guests: &[]GuestID{}},
 			requests: mockServer.RequestsError("/pools/test_pool", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -1045,7 +1045,7 @@ func Test_poolClient_Set(t *testing.T) {
 		{name: `500 internal server error`,
 			pool:     ConfigPool{Name: "test_pool"},
 			requests: mockServer.RequestsError("/pools/test_pool", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
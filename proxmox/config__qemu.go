@@ -19,9 +19,17 @@ import (
 
 type (
 	QemuGuestInterface interface {
+		// CheckUpdatePermissions reports which privileges the update requires and which of them the user is missing, without changing anything.
+		CheckUpdatePermissions(ctx context.Context, vmr VmRef, config ConfigQemu) (PermissionCheck, error)
+		CheckUpdatePermissionsNoCheck(ctx context.Context, vmr VmRef, config ConfigQemu) (PermissionCheck, error)
+
 		Create(context.Context, ConfigQemu) (*VmRef, error)
 		CreateNoCheck(context.Context, ConfigQemu) (*VmRef, error)
 
+		// Plan returns the changes the update would make, without applying anything.
+		Plan(ctx context.Context, vmr VmRef, config ConfigQemu) (QemuUpdatePlan, error)
+		PlanNoCheck(ctx context.Context, vmr VmRef, config ConfigQemu) (QemuUpdatePlan, error)
+
 		// When allowRestart is false an error is return if the update rewuires a reboot or shutdown.
 		Update(ctx context.Context, vmr VmRef, allowRestart bool, allowForceStop bool, config ConfigQemu) error
 		UpdateNoCheck(ctx context.Context, vmr VmRef, allowRestart bool, allowForceStop bool, config ConfigQemu) error
@@ -152,9 +160,10 @@ type ConfigQemu struct {
 	Agent            *QemuGuestAgent       `json:"agent,omitempty"`
 	Architecture     *QemuCpuArchitecture  `json:"architecture,omitempty"` // only used during creation
 	Args             string                `json:"args,omitempty"`
+	Audio            *QemuAudio            `json:"audio,omitempty"`
 	Bios             string                `json:"bios,omitempty"`
-	Boot             string                `json:"boot,omitempty"`     // TODO should be an array of custom enums
-	BootDisk         string                `json:"bootdisk,omitempty"` // TODO discuss deprecation? Only returned as it's deprecated in the proxmox api
+	Boot             *QemuBootOrder        `json:"boot,omitempty"`     // May only reference disks in Disks or Iso, the deprecated QemuDisks and QemuIso are never sent to Proxmox VE
+	BootDisk         string                `json:"bootdisk,omitempty"` // Deprecated use Boot *QemuBootOrder instead, only returned as it's deprecated in the proxmox api
 	CPU              *QemuCPU              `json:"cpu,omitempty"`      // never nil when returned
 	CloudInit        *CloudInit            `json:"cloudinit,omitempty"`
 	Description      *string               `json:"description,omitempty"` // never nil when returned
@@ -164,9 +173,9 @@ type ConfigQemu struct {
 	HaGroup          string                `json:"hagroup,omitempty"`
 	HaState          string                `json:"hastate,omitempty"` // TODO should be custom type with enum
 	Hookscript       string                `json:"hookscript,omitempty"`
-	Hotplug          string                `json:"hotplug,omitempty"`   // TODO should be a struct
+	Hotplug          *QemuHotplug          `json:"hotplug,omitempty"`   // Never nil when returned
 	LinkedID         *GuestID              `json:"linked_id,omitempty"` // Only returned setting it has no effect
-	Machine          string                `json:"machine,omitempty"`   // TODO should be custom type with enum
+	Machine          *QemuMachine          `json:"machine,omitempty"`
 	Memory           *QemuMemory           `json:"memory,omitempty"`
 	Name             *GuestName            `json:"name,omitempty"` // never nil when returned
 	Networks         QemuNetworkInterfaces `json:"networks,omitempty"`
@@ -178,12 +187,12 @@ type ConfigQemu struct {
 	QemuOs           string                `json:"ostype,omitempty"`
 	PciDevices       QemuPciDevices        `json:"pci_devices,omitempty"`
 	QemuPxe          bool                  `json:"pxe,omitempty"`
-	QemuUnusedDisks  QemuDevices           `json:"unused,omitempty"` // TODO should be a struct
 	USBs             QemuUSBs              `json:"usbs,omitempty"`
-	QemuVga          QemuDevice            `json:"vga,omitempty"`    // TODO should be a struct
-	Scsihw           string                `json:"scsihw,omitempty"` // TODO should be custom type with enum
+	VGA              *QemuVGA              `json:"vga,omitempty"`
+	VirtioFS         QemuVirtioFS          `json:"virtiofs,omitempty"`
+	ScsiController   *QemuScsiController   `json:"scsi_controller,omitempty"` // Never nil when returned
 	Serials          SerialInterfaces      `json:"serials,omitempty"`
-	Smbios1          string                `json:"smbios1,omitempty"`            // TODO should be custom type with enum?
+	Smbios           *QemuSmbios           `json:"smbios,omitempty"`
 	StartAtNodeBoot  *bool                 `json:"start_at_node_boot,omitempty"` // Never nil when returned
 	StartupShutdown  *StartupAndShutdown   `json:"startup_shutdown,omitempty"`
 	State            *PowerState           `json:"state,omitempty"`   // Never returned
@@ -259,15 +268,9 @@ func (config *ConfigQemu) defaults() {
 	if config == nil {
 		return
 	}
-	if config.Boot == "" {
-		config.Boot = "cdn"
-	}
 	if config.Bios == "" {
 		config.Bios = "seabios"
 	}
-	if config.Hotplug == "" {
-		config.Hotplug = "network,disk,usb"
-	}
 	if config.QemuDisks == nil {
 		config.QemuDisks = QemuDevices{}
 	}
@@ -277,15 +280,6 @@ func (config *ConfigQemu) defaults() {
 	if config.QemuOs == "" {
 		config.QemuOs = "other"
 	}
-	if config.QemuUnusedDisks == nil {
-		config.QemuUnusedDisks = QemuDevices{}
-	}
-	if config.QemuVga == nil {
-		config.QemuVga = QemuDevice{}
-	}
-	if config.Scsihw == "" {
-		config.Scsihw = "lsi"
-	}
 }
 
 func (config *ConfigQemu) mapToAPI(currentConfig ConfigQemu, version Version) (params map[string]interface{}) {
@@ -303,21 +297,12 @@ func (config *ConfigQemu) mapToAPI(currentConfig ConfigQemu, version Version) (p
 	if config.Bios != "" {
 		params["bios"] = config.Bios
 	}
-	if config.Boot != "" {
-		params["boot"] = config.Boot
-	}
 	if config.Hookscript != "" {
 		params["hookscript"] = config.Hookscript
 	}
-	if config.Hotplug != "" {
-		params["hotplug"] = config.Hotplug
-	}
 	if config.QemuKVM != nil {
 		params["kvm"] = *config.QemuKVM
 	}
-	if config.Machine != "" {
-		params["machine"] = config.Machine
-	}
 	if config.Name != nil {
 		if currentConfig.Name == nil || *config.Name != *currentConfig.Name {
 			params[qemuApiKeyName] = config.Name.String()
@@ -329,9 +314,6 @@ func (config *ConfigQemu) mapToAPI(currentConfig ConfigQemu, version Version) (p
 	if config.QemuOs != "" {
 		params["ostype"] = config.QemuOs
 	}
-	if config.Scsihw != "" {
-		params["scsihw"] = config.Scsihw
-	}
 	if config.StartAtNodeBoot != nil {
 		if currentConfig.StartAtNodeBoot != nil {
 			itemsToDelete += startAtNodeBootMapToApiUpdate(params, *config.StartAtNodeBoot, *currentConfig.StartAtNodeBoot)
@@ -349,9 +331,6 @@ func (config *ConfigQemu) mapToAPI(currentConfig ConfigQemu, version Version) (p
 	if config.Tablet != nil {
 		params[qemuApiKeyTablet] = *config.Tablet
 	}
-	if config.Smbios1 != "" {
-		params["smbios1"] = config.Smbios1
-	}
 	if config.TPM != nil {
 		if delete := config.TPM.mapToApi(params, currentConfig.TPM); delete != "" {
 			itemsToDelete = AddToList(itemsToDelete, delete)
@@ -383,6 +362,14 @@ func (config *ConfigQemu) mapToAPI(currentConfig ConfigQemu, version Version) (p
 		itemsToDelete += config.Serials.mapToAPI(currentConfig.Serials, params)
 	}
 
+	if config.Audio != nil {
+		if currentConfig.Audio != nil {
+			itemsToDelete += config.Audio.mapToAPIUpdateUnsafe(currentConfig.Audio, params)
+		} else {
+			config.Audio.mapToAPICreate(params)
+		}
+	}
+
 	if config.RandomnessDevice != nil {
 		if currentConfig.RandomnessDevice != nil {
 			itemsToDelete += config.RandomnessDevice.mapToAPIUpdateUnsafe(currentConfig.RandomnessDevice, params)
@@ -394,13 +381,6 @@ func (config *ConfigQemu) mapToAPI(currentConfig ConfigQemu, version Version) (p
 	// Create networks config.
 	itemsToDelete += config.Networks.mapToAPI(currentConfig.Networks, params)
 
-	// Create vga config.
-	vgaParam := QemuDeviceParam{}
-	vgaParam = vgaParam.createDeviceParam(config.QemuVga, nil)
-	if len(vgaParam) > 0 {
-		params["vga"] = strings.Join(vgaParam, ",")
-	}
-
 	if config.PciDevices != nil {
 		itemsToDelete += config.PciDevices.mapToAPI(currentConfig.PciDevices, params)
 	}
@@ -418,6 +398,9 @@ func (config ConfigQemu) mapToApiCreate(version Version) (map[string]any, *[]byt
 		builder.WriteString("&" + qemuApiKeyArchitecture + "=")
 		builder.WriteString(config.Architecture.String())
 	}
+	if config.Boot != nil {
+		config.Boot.mapToApiCreate(&builder)
+	}
 	if config.CPU != nil {
 		config.CPU.mapToApiCreate(version, &builder)
 	}
@@ -431,6 +414,18 @@ func (config ConfigQemu) mapToApiCreate(version Version) (map[string]any, *[]byt
 	if config.EfiDisk != nil {
 		config.EfiDisk.mapToApiCreate(&builder)
 	}
+	if config.Hotplug != nil {
+		config.Hotplug.mapToApiCreate(&builder)
+	}
+	if config.Machine != nil {
+		config.Machine.mapToApiCreate(version, &builder)
+	}
+	if config.ScsiController != nil {
+		config.ScsiController.mapToApiCreate(&builder)
+	}
+	if config.Smbios != nil {
+		config.Smbios.mapToApiCreate(&builder)
+	}
 	if config.State != nil && *config.State == PowerStateRunning {
 		builder.WriteString("&start=1")
 	}
@@ -443,6 +438,12 @@ func (config ConfigQemu) mapToApiCreate(version Version) (map[string]any, *[]byt
 	if config.USBs != nil {
 		config.USBs.mapToApiCreate(&builder)
 	}
+	if config.VirtioFS != nil && CapabilityVirtioFS.SupportedBy(version) {
+		config.VirtioFS.mapToApiCreate(&builder)
+	}
+	if config.VGA != nil && !config.VGA.Delete {
+		config.VGA.mapToApiCreate(version, &builder)
+	}
 	if config.Watchdog != nil && !config.Watchdog.Delete {
 		config.Watchdog.mapToApiCreate(&builder)
 	}
@@ -459,6 +460,15 @@ func (config ConfigQemu) mapToApiUpdate(currentLegacy *ConfigQemu, current confi
 	params := config.mapToAPI(*currentLegacy, version)
 	builder := strings.Builder{}
 	delete := strings.Builder{}
+	if config.Boot != nil {
+		var currentBoot QemuBootOrder
+		if currentLegacy.Boot != nil {
+			currentBoot = *currentLegacy.Boot
+		}
+		config.Boot.mapToApiUpdate(currentBoot, &builder, &delete)
+	} else if currentLegacy.Boot != nil { // Devices removed by the update are also removed from the boot order.
+		currentLegacy.Boot.prune(&config, currentLegacy).mapToApiUpdate(*currentLegacy.Boot, &builder, &delete)
+	}
 	if config.CPU != nil {
 		config.CPU.mapToApiUpdate(*currentLegacy.CPU, version, &builder, &delete)
 	}
@@ -482,6 +492,34 @@ func (config ConfigQemu) mapToApiUpdate(currentLegacy *ConfigQemu, current confi
 			config.EfiDisk.mapToApiCreate(&builder)
 		}
 	}
+	if config.Hotplug != nil {
+		hotplug := qemuHotplugDefault()
+		if currentLegacy.Hotplug != nil {
+			hotplug = *currentLegacy.Hotplug
+		}
+		config.Hotplug.mapToApiUpdate(hotplug, &builder)
+	}
+	if config.Machine != nil {
+		if currentLegacy.Machine != nil {
+			config.Machine.mapToApiUpdate(*currentLegacy.Machine, version, &builder, &delete)
+		} else {
+			config.Machine.mapToApiCreate(version, &builder)
+		}
+	}
+	if config.ScsiController != nil {
+		if currentLegacy.ScsiController != nil {
+			config.ScsiController.mapToApiUpdate(*currentLegacy.ScsiController, &builder)
+		} else {
+			config.ScsiController.mapToApiCreate(&builder)
+		}
+	}
+	if config.Smbios != nil {
+		if currentLegacy.Smbios != nil {
+			config.Smbios.mapToApiUpdate(*currentLegacy.Smbios, &builder, &delete)
+		} else {
+			config.Smbios.mapToApiCreate(&builder)
+		}
+	}
 	if config.Tags != nil {
 		if cur := current.raw.GetTags(); len(cur) != 0 {
 			if v, ok := config.Tags.mapToApiUpdate(cur); ok {
@@ -502,6 +540,16 @@ func (config ConfigQemu) mapToApiUpdate(currentLegacy *ConfigQemu, current confi
 			config.USBs.mapToApiCreate(&builder)
 		}
 	}
+	if config.VirtioFS != nil && CapabilityVirtioFS.SupportedBy(version) {
+		config.VirtioFS.mapToApiUpdate(currentLegacy.VirtioFS, &builder, &delete)
+	}
+	if config.VGA != nil {
+		if currentLegacy.VGA != nil {
+			config.VGA.mapToApiUpdate(*currentLegacy.VGA, version, &builder, &delete)
+		} else if !config.VGA.Delete {
+			config.VGA.mapToApiCreate(version, &builder)
+		}
+	}
 	if config.Watchdog != nil {
 		if currentLegacy.Watchdog != nil {
 			config.Watchdog.mapToApiUpdate(currentLegacy.Watchdog, &builder, &delete)
@@ -555,26 +603,15 @@ func (config *ConfigQemu) mapToStruct(vmr *VmRef, params map[string]interface{})
 	if _, isSet := params["args"]; isSet {
 		config.Args = strings.TrimSpace(params["args"].(string))
 	}
-	//boot by default from hard disk (c), CD-ROM (d), network (n).
-	if _, isSet := params["boot"]; isSet {
-		config.Boot = params["boot"].(string)
-	}
 	if _, isSet := params["bootdisk"]; isSet {
 		config.BootDisk = params["bootdisk"].(string)
 	}
 	if _, isSet := params["bios"]; isSet {
 		config.Bios = params["bios"].(string)
 	}
-	//Can be network,disk,cpu,memory,usb
-	if _, isSet := params["hotplug"]; isSet {
-		config.Hotplug = params["hotplug"].(string)
-	}
 	if _, isSet := params["hookscript"]; isSet {
 		config.Hookscript = params["hookscript"].(string)
 	}
-	if _, isSet := params["machine"]; isSet {
-		config.Machine = params["machine"].(string)
-	}
 	if itemValue, isSet := params["tpmstate0"]; isSet {
 		config.TPM = TpmState{}.mapToSDK(itemValue.(string))
 	}
@@ -584,68 +621,10 @@ func (config *ConfigQemu) mapToStruct(vmr *VmRef, params map[string]interface{})
 	if _, isSet := params["ostype"]; isSet {
 		config.QemuOs = params["ostype"].(string)
 	}
-	if _, isSet := params["scsihw"]; isSet {
-		config.Scsihw = params["scsihw"].(string)
-	}
-	if _, isSet := params["smbios1"]; isSet {
-		config.Smbios1 = params["smbios1"].(string)
-	}
 
 	if config.Disks != nil && config.Disks.Ide != nil && config.Disks.Ide.Disk_2 != nil && config.Disks.Ide.Disk_2.CdRom != nil {
 		config.Iso = config.Disks.Ide.Disk_2.CdRom.Iso
 	}
-
-	// Add unused disks
-	// unused0:local:100/vm-100-disk-1.qcow2
-	unusedDiskNames := []string{}
-	for k := range params {
-		// look for entries from the config in the format "unusedX:<storagepath>" where X is an integer
-		if unusedDiskName := rxUnusedDiskName.FindStringSubmatch(k); len(unusedDiskName) > 0 {
-			unusedDiskNames = append(unusedDiskNames, unusedDiskName[0])
-		}
-	}
-	// if len(unusedDiskNames) > 0 {
-	// 	log.Printf("[DEBUG] unusedDiskNames: %v", unusedDiskNames)
-	// }
-
-	if len(unusedDiskNames) > 0 {
-		config.QemuUnusedDisks = QemuDevices{}
-		for _, unusedDiskName := range unusedDiskNames {
-			unusedDiskConfStr := params[unusedDiskName].(string)
-			finalDiskConfMap := QemuDevice{}
-
-			// parse "unused0" to get the id '0' as an int
-			id := rxDeviceID.FindStringSubmatch(unusedDiskName)
-			diskID, err := strconv.Atoi(id[0])
-			if err != nil {
-				return fmt.Errorf("unable to parse unused disk id from input string '%v' tried to convert '%v' to integer", unusedDiskName, diskID)
-			}
-			finalDiskConfMap["slot"] = diskID
-
-			// parse the attributes from the unused disk
-			// extract the storage and file path from the unused disk entry
-			parsedUnusedDiskMap := ParsePMConf(unusedDiskConfStr, "storage+file")
-			storageName, fileName := ParseSubConf(parsedUnusedDiskMap["storage+file"].(string), ":")
-			finalDiskConfMap["storage"] = storageName
-			finalDiskConfMap["file"] = fileName
-
-			config.QemuUnusedDisks[diskID] = finalDiskConfMap
-			config.QemuUnusedDisks[diskID] = finalDiskConfMap
-			config.QemuUnusedDisks[diskID] = finalDiskConfMap
-		}
-	}
-	//Display
-
-	if vga, isSet := params["vga"]; isSet {
-		vgaList := strings.Split(vga.(string), ",")
-		vgaMap := QemuDevice{}
-
-		vgaMap.readDeviceConfig(vgaList)
-		if len(vgaMap) > 0 {
-			config.QemuVga = vgaMap
-		}
-	}
-
 	return nil
 }
 
@@ -746,7 +725,7 @@ func (config ConfigQemu) updateNoCheck(
 		urlBuilder.WriteString("/nodes/")
 		urlBuilder.WriteString(vmr.node.String())
 		urlBuilder.WriteString(urlPart)
-		if err := cl.putRawRetry(ctx, urlBuilder.String(), new([]byte("delete="+itemsToDeleteBeforeUpdate)), 3); err != nil {
+		if err := cl.putRawRetry(ctx, urlBuilder.String(), new([]byte("delete="+itemsToDeleteBeforeUpdate))); err != nil {
 			return false, fmt.Errorf("error updating VM: %v", err)
 		}
 	}
@@ -795,7 +774,7 @@ func (config ConfigQemu) updateNoCheck(
 		urlBuilder.WriteString("/nodes/")
 		urlBuilder.WriteString(vmr.node.String())
 		urlBuilder.WriteString(urlPart)
-		if err = c.putRawRetry(ctx, urlBuilder.String(), body, 3); err != nil {
+		if err = c.putRawRetry(ctx, urlBuilder.String(), body); err != nil {
 			return false, fmt.Errorf("error updating VM: %v", err)
 		}
 		pending = true
@@ -919,6 +898,11 @@ func (config ConfigQemu) Validate(current *ConfigQemu, version Version) (err err
 				return
 			}
 		}
+		if config.Audio != nil {
+			if err = config.Audio.validateCreate(); err != nil {
+				return
+			}
+		}
 		if config.RandomnessDevice != nil {
 			if err = config.RandomnessDevice.validateCreate(); err != nil {
 				return
@@ -953,6 +937,11 @@ func (config ConfigQemu) Validate(current *ConfigQemu, version Version) (err err
 				return
 			}
 		}
+		if config.Audio != nil {
+			if err = config.Audio.Validate(current.Audio); err != nil {
+				return
+			}
+		}
 		if config.RandomnessDevice != nil {
 			if err = config.RandomnessDevice.Validate(current.RandomnessDevice); err != nil {
 				return
@@ -1008,6 +997,16 @@ func (config ConfigQemu) Validate(current *ConfigQemu, version Version) (err err
 }
 
 func (config ConfigQemu) validateCreate(version Version) error {
+	if config.Boot != nil {
+		if err := config.Boot.validate(&config, nil); err != nil {
+			return err
+		}
+	}
+	if config.Disks != nil && config.Disks.Unused != nil {
+		if err := config.Disks.Unused.Validate(config.Disks, nil); err != nil {
+			return err
+		}
+	}
 	if config.CPU == nil {
 		return errors.New(ConfigQemu_Error_CpuRequired)
 	} else {
@@ -1020,11 +1019,41 @@ func (config ConfigQemu) validateCreate(version Version) error {
 			return err
 		}
 	}
+	if config.Hotplug != nil {
+		if err := config.Hotplug.Validate(nil, config.CPU != nil && config.CPU.Numa != nil && *config.CPU.Numa); err != nil {
+			return err
+		}
+	}
+	if config.Machine != nil {
+		if err := config.Machine.Validate(nil, version); err != nil {
+			return err
+		}
+	}
+	if config.ScsiController != nil {
+		if err := config.ScsiController.Validate(); err != nil {
+			return err
+		}
+	}
+	if config.Smbios != nil {
+		if err := config.Smbios.Validate(nil); err != nil {
+			return err
+		}
+	}
 	if config.USBs != nil {
 		if err := config.USBs.validateCreate(); err != nil {
 			return err
 		}
 	}
+	if config.VGA != nil {
+		if err := config.VGA.Validate(nil, version); err != nil {
+			return err
+		}
+	}
+	if config.VirtioFS != nil {
+		if err := config.VirtioFS.Validate(nil, version); err != nil {
+			return err
+		}
+	}
 	if config.Watchdog != nil {
 		if err := config.Watchdog.validateCreate(); err != nil {
 			return err
@@ -1034,6 +1063,16 @@ func (config ConfigQemu) validateCreate(version Version) error {
 }
 
 func (config ConfigQemu) validateUpdate(current *ConfigQemu, version Version) error {
+	if config.Boot != nil {
+		if err := config.Boot.validate(&config, current); err != nil {
+			return err
+		}
+	}
+	if config.Disks != nil && config.Disks.Unused != nil {
+		if err := config.Disks.Unused.Validate(config.Disks, current.Disks); err != nil {
+			return err
+		}
+	}
 	if config.CPU != nil {
 		if err := config.CPU.validateUpdate(current.CPU, version); err != nil {
 			return err
@@ -1050,6 +1089,36 @@ func (config ConfigQemu) validateUpdate(current *ConfigQemu, version Version) er
 			}
 		}
 	}
+	if config.Hotplug != nil || (config.CPU != nil && config.CPU.Numa != nil) {
+		var numa bool
+		if config.CPU != nil && config.CPU.Numa != nil {
+			numa = *config.CPU.Numa
+		} else if current.CPU != nil && current.CPU.Numa != nil {
+			numa = *current.CPU.Numa
+		}
+		hotplug := QemuHotplug{}
+		if config.Hotplug != nil {
+			hotplug = *config.Hotplug
+		}
+		if err := hotplug.Validate(current.Hotplug, numa); err != nil {
+			return err
+		}
+	}
+	if config.Machine != nil {
+		if err := config.Machine.validateUpdate(current.Machine, version); err != nil {
+			return err
+		}
+	}
+	if config.ScsiController != nil {
+		if err := config.ScsiController.Validate(); err != nil {
+			return err
+		}
+	}
+	if config.Smbios != nil {
+		if err := config.Smbios.Validate(current.Smbios); err != nil {
+			return err
+		}
+	}
 	if config.USBs != nil {
 		if len(current.USBs) > 0 { // update
 			if err := config.USBs.validateUpdate(current.USBs); err != nil {
@@ -1061,6 +1130,16 @@ func (config ConfigQemu) validateUpdate(current *ConfigQemu, version Version) er
 			}
 		}
 	}
+	if config.VGA != nil {
+		if err := config.VGA.Validate(current.VGA, version); err != nil {
+			return err
+		}
+	}
+	if config.VirtioFS != nil {
+		if err := config.VirtioFS.Validate(current.VirtioFS, version); err != nil {
+			return err
+		}
+	}
 	if config.Watchdog != nil {
 		if current.Watchdog != nil { // update
 			if err := config.Watchdog.validateUpdate(); err != nil {
@@ -1387,11 +1466,15 @@ type RawConfigQemu interface {
 	Get(vmr VmRef) (*ConfigQemu, error)
 	GetAgent() *QemuGuestAgent
 	GetArchitecture() *QemuCpuArchitecture
+	GetAudio() *QemuAudio
+	GetBoot() *QemuBootOrder
 	GetCPU() QemuCPU
 	GetCloudInit() *CloudInit
 	GetDescription() string
 	GetEfiDisk() *EfiDisk
+	GetHotplug() QemuHotplug
 	GetID() GuestID
+	GetMachine() *QemuMachine
 	GetMemory() *QemuMemory
 	GetName() GuestName
 	GetNetworks() QemuNetworkInterfaces
@@ -1399,12 +1482,16 @@ type RawConfigQemu interface {
 	GetPciDevices() QemuPciDevices
 	GetProtection() bool
 	GetRandomnessDevice() *VirtIoRNG
+	GetScsiController() QemuScsiController
+	GetSmbios() *QemuSmbios
 	GetSerials() SerialInterfaces
 	GetStartAtNodeBoot() bool
 	GetStartupShutdown() *StartupAndShutdown
 	GetTablet() bool
 	GetTags() Tags
 	GetUSBs() QemuUSBs
+	GetVGA() *QemuVGA
+	GetVirtioFS() QemuVirtioFS
 	GetWatchdog() *Watchdog
 }
 
@@ -1429,13 +1516,17 @@ func (raw *rawConfigQemu) get(vmr VmRef) (*ConfigQemu, error) {
 	config := ConfigQemu{
 		Agent:            raw.GetAgent(),
 		Architecture:     raw.GetArchitecture(),
+		Audio:            raw.GetAudio(),
+		Boot:             raw.GetBoot(),
 		CPU:              new(raw.GetCPU()),
 		CloudInit:        raw.GetCloudInit(),
 		Description:      util.Pointer(raw.GetDescription()),
 		EfiDisk:          raw.GetEfiDisk(),
 		HaGroup:          vmr.HaGroup(),
 		HaState:          vmr.HaState(),
+		Hotplug:          new(raw.GetHotplug()),
 		ID:               new(raw.GetID()),
+		Machine:          raw.GetMachine(),
 		Memory:           raw.GetMemory(),
 		Name:             util.Pointer(raw.GetName()),
 		Networks:         raw.GetNetworks(),
@@ -1443,12 +1534,16 @@ func (raw *rawConfigQemu) get(vmr VmRef) (*ConfigQemu, error) {
 		PciDevices:       raw.GetPciDevices(),
 		Protection:       util.Pointer(raw.GetProtection()),
 		RandomnessDevice: raw.GetRandomnessDevice(),
+		ScsiController:   new(raw.GetScsiController()),
+		Smbios:           raw.GetSmbios(),
 		Serials:          raw.GetSerials(),
 		StartAtNodeBoot:  util.Pointer(raw.GetStartAtNodeBoot()),
 		StartupShutdown:  raw.GetStartupShutdown(),
 		Tablet:           util.Pointer(raw.GetTablet()),
 		Tags:             new(raw.GetTags()),
 		USBs:             raw.GetUSBs(),
+		VGA:              raw.GetVGA(),
+		VirtioFS:         raw.GetVirtioFS(),
 		Watchdog:         raw.GetWatchdog(),
 	}
 	config.Disks, config.LinkedID = raw.GetDisks()
@@ -1506,6 +1601,8 @@ func (raw *rawConfigQemu) GetTags() Tags {
 
 const (
 	qemuApiKeyArchitecture      = "arch"
+	qemuApiKeyAudio             = "audio0"
+	qemuApiKeyBoot              = "boot"
 	qemuApiKeyCloudInitCustom   = "cicustom"
 	qemuApiKeyCloudInitPassword = "cipassword"
 	qemuApiKeyCloudInitSshKeys  = "sshkeys"
@@ -1522,14 +1619,19 @@ const (
 	qemuApiKeyDescription       = "description"
 	qemuApiKeyEfiDisk           = "efidisk0"
 	qemuApiKeyGuestAgent        = "agent"
+	qemuApiKeyHotplug           = "hotplug"
+	qemuApiKeyMachine           = "machine"
 	qemuApiKeyMemoryBallooning  = "balloon"
 	qemuApiKeyMemoryCapacity    = "memory"
 	qemuApiKeyMemoryShares      = "shares"
 	qemuApiKeyName              = "name"
 	qemuApiKeyProtection        = "protection"
 	qemuApiKeyRandomnessDevice  = "rng0"
+	qemuApiKeyScsiController    = "scsihw"
+	qemuApiKeySmbios            = "smbios1"
 	qemuApiKeyTablet            = "tablet"
 	qemuApiKeyTags              = "tags"
+	qemuApiKeyVGA               = "vga"
 	qemuApiKeyWatchdog          = "watchdog"
 	qemuPrefixApiKeyDiskIde     = "ide"
 	qemuPrefixApiKeyDiskSCSI    = "scsi"
@@ -1538,7 +1640,9 @@ const (
 	qemuPrefixApiKeyNetwork     = "net"
 	qemuPrefixApiKeyPCI         = "hostpci"
 	qemuPrefixApiKeySerial      = "serial"
+	qemuPrefixApiKeyUnused      = "unused"
 	qemuPrefixApiKeyUSB         = "usb"
+	qemuPrefixApiKeyVirtioFS    = "virtiofs"
 )
 
 // NewRawConfigQemuFromApi returns the configuration of the Qemu guest.
@@ -1643,7 +1747,7 @@ func waitForMigrationLockRelease(ctx context.Context, client *Client, vmr *VmRef
 	for {
 		select {
 		case <-timeout:
-			return fmt.Errorf("timed out waiting for VM %d to unlock on node %s after migration", vmr.VmId(), targetNode)
+			return timeoutError(fmt.Sprintf("timed out waiting for VM %d to unlock on node %s after migration", vmr.VmId(), targetNode))
 		case <-ticker.C:
 			// We need to create a new VmRef for GetVmInfo as it updates the node info in the ref.
 			currentStatusVmr := NewVmRef(vmr.VmId())
@@ -0,0 +1,166 @@
+package proxmox
+
+import (
+	"errors"
+	"strings"
+)
+
+type QemuAudio struct {
+	Device *QemuAudioDevice `json:"device,omitempty"` // Never nil when returned
+	Driver *QemuAudioDriver `json:"driver,omitempty"` // Never nil when returned
+	Delete bool             `json:"delete,omitempty"`
+}
+
+const (
+	QemuAudioErrorDeviceNotSet = "device must be set on creation"
+)
+
+const (
+	qemuAPISettingAudioDevice = "device"
+	qemuAPISettingAudioDriver = "driver"
+)
+
+func (config QemuAudio) combine(current QemuAudio) QemuAudio {
+	var newConfig QemuAudio
+	if config.Device != nil {
+		newConfig.Device = config.Device
+	} else {
+		newConfig.Device = current.Device
+	}
+	if config.Driver != nil {
+		newConfig.Driver = config.Driver
+	} else {
+		newConfig.Driver = current.Driver
+	}
+	return newConfig
+}
+
+func (config QemuAudio) mapToAPICreate(params map[string]any) {
+	if !config.Delete {
+		params[qemuApiKeyAudio] = config.string()
+	}
+}
+
+func (config QemuAudio) mapToAPIUpdateUnsafe(current *QemuAudio, params map[string]any) (delete string) {
+	if config.Delete {
+		return "," + qemuApiKeyAudio
+	}
+	new := config.combine(*current).string()
+	if new != current.string() {
+		params[qemuApiKeyAudio] = new
+	}
+	return ""
+}
+
+func (config QemuAudio) Validate(current *QemuAudio) error {
+	if current != nil {
+		return config.validateUpdate()
+	}
+	return config.validateCreate()
+}
+
+func (config QemuAudio) validateCreate() error {
+	if config.Delete {
+		return nil
+	}
+	if config.Device == nil {
+		return errors.New(QemuAudioErrorDeviceNotSet)
+	}
+	return config.validateUpdate()
+}
+
+func (config QemuAudio) validateUpdate() error {
+	if config.Delete {
+		return nil
+	}
+	if config.Device != nil {
+		if err := config.Device.Validate(); err != nil {
+			return err
+		}
+	}
+	if config.Driver != nil {
+		return config.Driver.Validate()
+	}
+	return nil
+}
+
+func (config QemuAudio) string() string {
+	settings := make([]string, 0, 2)
+	if config.Device != nil {
+		settings = append(settings, qemuAPISettingAudioDevice+"="+config.Device.String())
+	}
+	if config.Driver != nil {
+		settings = append(settings, qemuAPISettingAudioDriver+"="+config.Driver.String())
+	}
+	return strings.Join(settings, ",")
+}
+
+// Enum
+//
+//	const (
+//		QemuAudioDeviceAC97
+//		QemuAudioDeviceIch9IntelHDA
+//		QemuAudioDeviceIntelHDA
+//	)
+type QemuAudioDevice string
+
+const (
+	QemuAudioDeviceErrorInvalid = "invalid value for QemuAudioDevice"
+)
+
+const (
+	QemuAudioDeviceAC97         QemuAudioDevice = "AC97"
+	QemuAudioDeviceIch9IntelHDA QemuAudioDevice = "ich9-intel-hda"
+	QemuAudioDeviceIntelHDA     QemuAudioDevice = "intel-hda"
+)
+
+func (device QemuAudioDevice) String() string { return string(device) } // String is for fmt.Stringer.
+
+func (device QemuAudioDevice) Validate() error {
+	switch device {
+	case QemuAudioDeviceAC97, QemuAudioDeviceIch9IntelHDA, QemuAudioDeviceIntelHDA:
+		return nil
+	}
+	return errors.New(QemuAudioDeviceErrorInvalid)
+}
+
+// Enum
+//
+//	const (
+//		QemuAudioDriverNone
+//		QemuAudioDriverSpice
+//	)
+type QemuAudioDriver string
+
+const (
+	QemuAudioDriverErrorInvalid = "invalid value for QemuAudioDriver"
+)
+
+const (
+	QemuAudioDriverNone  QemuAudioDriver = "none"
+	QemuAudioDriverSpice QemuAudioDriver = "spice"
+)
+
+func (driver QemuAudioDriver) String() string { return string(driver) } // String is for fmt.Stringer.
+
+func (driver QemuAudioDriver) Validate() error {
+	switch driver {
+	case QemuAudioDriverNone, QemuAudioDriverSpice:
+		return nil
+	}
+	return errors.New(QemuAudioDriverErrorInvalid)
+}
+
+func (raw *rawConfigQemu) GetAudio() *QemuAudio {
+	if v, isSet := raw.a[qemuApiKeyAudio]; isSet {
+		settings := splitStringOfSettings(v.(string))
+		config := QemuAudio{
+			Device: new(QemuAudioDevice(settings[qemuAPISettingAudioDevice])),
+			Driver: new(QemuAudioDriverSpice)}
+		if v, isSet := settings[qemuAPISettingAudioDriver]; isSet {
+			*config.Driver = QemuAudioDriver(v)
+		}
+		return &config
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package proxmox
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// QemuBootOrder is the order of the devices the guest tries to boot from.
+// An empty order removes the boot order, after which Proxmox VE falls back to its default.
+// Devices other than disks and network interfaces, like hostpci and usb devices, are returned in QemuBootDevice.Other.
+// The legacy format of older guests is returned as the order Proxmox VE boots them in.
+type QemuBootOrder []QemuBootDevice
+
+const (
+	QemuBootOrder_Error_DeviceNotFound = "boot order references a device that does not exist"
+	QemuBootOrder_Error_DeviceRemoved  = "boot order references a device that is being removed"
+	QemuBootOrder_Error_Duplicate      = "boot order may not contain the same device more than once"
+)
+
+func (order QemuBootOrder) mapToApi() string {
+	if len(order) == 0 {
+		return ""
+	}
+	devices := make([]string, len(order))
+	for i := range order {
+		devices[i] = order[i].String()
+	}
+	return "order" + equal + strings.Join(devices, semicolon)
+}
+
+func (order QemuBootOrder) mapToApiCreate(builder *strings.Builder) {
+	if v := order.mapToApi(); v != "" {
+		builder.WriteString("&" + qemuApiKeyBoot + "=")
+		builder.WriteString(v)
+	}
+}
+
+func (order QemuBootOrder) mapToApiUpdate(current QemuBootOrder, builder, delete *strings.Builder) {
+	configStr := order.mapToApi()
+	if configStr == current.mapToApi() {
+		return
+	}
+	if configStr == "" {
+		delete.WriteString("," + qemuApiKeyBoot)
+		return
+	}
+	builder.WriteString("&" + qemuApiKeyBoot + "=")
+	builder.WriteString(configStr)
+}
+
+// prune returns the order without the devices the config removes from the guest.
+// A removed disk whose volume the config attaches in another slot, like an ISO moved from ide2 to sata0, is replaced by that slot.
+// Volumes that can't be identified, like a newly created disk, are dropped, set the boot order in the same update to keep them.
+func (order QemuBootOrder) prune(config, current *ConfigQemu) QemuBootOrder {
+	pruned := make(QemuBootOrder, 0, len(order))
+	for i := range order {
+		if _, removed := order[i].state(config, current); !removed {
+			pruned = append(pruned, order[i])
+			continue
+		}
+		if id := order[i].moved(config, current, order); id != nil {
+			pruned = append(pruned, QemuBootDevice{Disk: id})
+		}
+	}
+	return pruned
+}
+
+func (order QemuBootOrder) Validate() error {
+	devices := make(map[string]struct{}, len(order))
+	for i := range order {
+		if err := order[i].Validate(); err != nil {
+			return err
+		}
+		if _, duplicate := devices[order[i].String()]; duplicate {
+			return errors.New(QemuBootOrder_Error_Duplicate)
+		}
+		devices[order[i].String()] = struct{}{}
+	}
+	return nil
+}
+
+// validate checks that every device in the order exists after the config is applied on top of current.
+// current is nil when the guest is created.
+func (order QemuBootOrder) validate(config, current *ConfigQemu) error {
+	if err := order.Validate(); err != nil {
+		return err
+	}
+	for i := range order {
+		exists, removed := order[i].state(config, current)
+		if removed {
+			return errors.New(QemuBootOrder_Error_DeviceRemoved)
+		}
+		if !exists {
+			return errors.New(QemuBootOrder_Error_DeviceNotFound)
+		}
+	}
+	return nil
+}
+
+func (raw *rawConfigQemu) GetBoot() *QemuBootOrder {
+	v, isSet := raw.a[qemuApiKeyBoot]
+	if !isSet {
+		return nil
+	}
+	order := QemuBootOrder{}
+	settings := splitStringOfSettings(v.(string))
+	devices, ok := settings["order"]
+	if !ok {
+		return raw.bootLegacy(settings)
+	}
+	if devices == "" {
+		return &order
+	}
+	for _, e := range strings.Split(devices, ";") {
+		if id, isNetwork := strings.CutPrefix(e, qemuPrefixApiKeyNetwork); isNetwork {
+			if tmp, err := strconv.ParseUint(id, 10, 8); err == nil {
+				order = append(order, QemuBootDevice{Network: new(QemuNetworkInterfaceID(tmp))})
+				continue
+			}
+		}
+		if id := QemuDiskId(e); id.Validate() == nil {
+			order = append(order, QemuBootDevice{Disk: &id})
+			continue
+		}
+		if e != "" {
+			order = append(order, QemuBootDevice{Other: new(e)})
+		}
+	}
+	return &order
+}
+
+// bootLegacy converts the legacy boot format, e.g. `cdn` together with `bootdisk`, into the order Proxmox VE boots the guest in.
+// c is the boot disk, d every CD-ROM and n every network interface, floppies (a) are not supported by Proxmox VE and skipped.
+func (raw *rawConfigQemu) bootLegacy(settings map[string]string) *QemuBootOrder {
+	order := QemuBootOrder{}
+	legacy, isSet := settings["legacy"]
+	if !isSet {
+		for key := range settings {
+			if key != "" && strings.Trim(key, "acdn") == "" {
+				legacy = key
+			}
+		}
+	}
+	disks, _ := raw.GetDisks()
+	for _, device := range strings.Split(legacy, "") {
+		switch device {
+		case "c":
+			if v, isSet := raw.a["bootdisk"]; isSet {
+				id := QemuDiskId(v.(string))
+				if storage := disks.storage(id); id.Validate() == nil && storage != nil && storage.CdRom == nil {
+					order = append(order, QemuBootDevice{Disk: &id})
+				}
+			}
+		case "d":
+			for _, id := range qemuDiskIDs() {
+				if storage := disks.storage(id); storage != nil && storage.CdRom != nil {
+					order = append(order, QemuBootDevice{Disk: &id})
+				}
+			}
+		case "n":
+			for id := QemuNetworkInterfaceID(0); id.Validate() == nil; id++ {
+				if _, isSet := raw.a[qemuPrefixApiKeyNetwork+id.String()]; isSet {
+					order = append(order, QemuBootDevice{Network: new(id)})
+				}
+			}
+		}
+	}
+	return &order
+}
+
+// qemuDiskIDs returns the ID of every disk slot in the order Proxmox VE iterates them.
+func qemuDiskIDs() []QemuDiskId {
+	ids := make([]QemuDiskId, 0)
+	for _, prefix := range []string{qemuPrefixApiKeyDiskIde, qemuPrefixApiKeyDiskSata, qemuPrefixApiKeyDiskSCSI, qemuPrefixApiKeyDiskVirtIO} {
+		for i := 0; ; i++ {
+			id := QemuDiskId(prefix + strconv.Itoa(i))
+			if id.Validate() != nil {
+				break
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// QemuBootDevice is a device the guest can boot from, exactly one of the fields has to be set.
+type QemuBootDevice struct {
+	Disk    *QemuDiskId             `json:"disk,omitempty"`
+	Network *QemuNetworkInterfaceID `json:"network,omitempty"`
+	Other   *string                 `json:"other,omitempty"` // Any other device, e.g. `hostpci0` or `usb0`, passed to Proxmox VE as is
+}
+
+const (
+	QemuBootDevice_Error_Empty             = "boot device requires either a disk, a network interface or another device"
+	QemuBootDevice_Error_MutuallyExclusive = "boot device may only have one of a disk, a network interface or another device"
+	QemuBootDevice_Error_OtherInvalid      = "other boot device may not be empty or contain ';', ',' or '='"
+)
+
+// state returns whether the device exists after the config is applied on top of current,
+// and whether the config removes it from the guest.
+func (device QemuBootDevice) state(config, current *ConfigQemu) (exists, removed bool) {
+	var currentExists bool
+	if device.Disk != nil {
+		if current != nil {
+			if storage := current.Disks.storage(*device.Disk); storage != nil && !storage.delete {
+				currentExists = true
+			}
+		}
+		storage := config.Disks.storage(*device.Disk)
+		if storage == nil {
+			if config.Iso != nil && *device.Disk == qemuPrefixApiKeyDiskIde+"2" { // Iso is attached as ide2
+				return true, false
+			}
+			return currentExists, false
+		}
+		if storage.delete {
+			return false, currentExists
+		}
+		return true, false
+	}
+	if device.Network != nil {
+		if current != nil {
+			if v, ok := current.Networks[*device.Network]; ok && !v.Delete {
+				currentExists = true
+			}
+		}
+		v, ok := config.Networks[*device.Network]
+		if !ok {
+			return currentExists, false
+		}
+		if v.Delete {
+			return false, currentExists
+		}
+		return true, false
+	}
+	if device.Other != nil { // not managed by the config, so never removed
+		return true, false
+	}
+	return false, false
+}
+
+// moved returns the slot the config attaches the volume of the removed disk to, nil when it is not attached elsewhere.
+// Slots that already held the volume or are already in the order are skipped.
+func (device QemuBootDevice) moved(config, current *ConfigQemu, order QemuBootOrder) *QemuDiskId {
+	if device.Disk == nil || current == nil {
+		return nil
+	}
+	volume := current.Disks.storage(*device.Disk).volume()
+	if volume == "" {
+		return nil
+	}
+	for _, id := range qemuDiskIDs() {
+		storage := config.Disks.storage(id)
+		if storage == nil || storage.delete || storage.volume() != volume ||
+			current.Disks.storage(id).volume() == volume ||
+			slices.ContainsFunc(order, func(e QemuBootDevice) bool { return e.Disk != nil && *e.Disk == id }) {
+			continue
+		}
+		return &id
+	}
+	return nil
+}
+
+func (device QemuBootDevice) String() string { // String is for fmt.Stringer.
+	if device.Disk != nil {
+		return device.Disk.String()
+	}
+	if device.Network != nil {
+		return qemuPrefixApiKeyNetwork + device.Network.String()
+	}
+	if device.Other != nil {
+		return *device.Other
+	}
+	return ""
+}
+
+func (device QemuBootDevice) Validate() error {
+	var set int
+	for _, isSet := range []bool{device.Disk != nil, device.Network != nil, device.Other != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set > 1 {
+		return errors.New(QemuBootDevice_Error_MutuallyExclusive)
+	}
+	if device.Disk != nil {
+		return device.Disk.Validate()
+	}
+	if device.Network != nil {
+		return device.Network.Validate()
+	}
+	if device.Other != nil {
+		if *device.Other == "" || strings.ContainsAny(*device.Other, ";,=") {
+			return errors.New(QemuBootDevice_Error_OtherInvalid)
+		}
+		return nil
+	}
+	return errors.New(QemuBootDevice_Error_Empty)
+}
+
+// volume identifies the volume of the storage, empty when it can't be identified, like a disk that is not created yet.
+func (storage *qemuStorage) volume() string {
+	switch {
+	case storage == nil:
+	case storage.CdRom != nil && storage.CdRom.Iso != nil:
+		return storage.CdRom.Iso.Storage + ":iso/" + storage.CdRom.Iso.File
+	case storage.CdRom != nil && storage.CdRom.Passthrough:
+		return "cdrom"
+	case storage.Passthrough != nil:
+		return storage.Passthrough.File
+	case storage.Disk != nil && storage.Disk.VolumePath != "":
+		return storage.Disk.Storage + ":" + storage.Disk.VolumePath
+	}
+	return ""
+}
+
+// storage returns the storage in the slot of the id, nil when the slot is empty.
+func (storages *QemuStorages) storage(id QemuDiskId) *qemuStorage {
+	if storages == nil {
+		return nil
+	}
+	slot := func(prefix string) (uint8, bool) {
+		number, isPrefix := strings.CutPrefix(id.String(), prefix)
+		if !isPrefix {
+			return 0, false
+		}
+		tmp, err := strconv.ParseUint(number, 10, 8)
+		return uint8(tmp), err == nil
+	}
+	if i, ok := slot(qemuPrefixApiKeyDiskIde); ok && storages.Ide != nil {
+		return storages.Ide.mapToIntMap()[i].convertDataStructure()
+	}
+	if i, ok := slot(qemuPrefixApiKeyDiskSata); ok && storages.Sata != nil {
+		return storages.Sata.mapToIntMap()[i].convertDataStructure()
+	}
+	if i, ok := slot(qemuPrefixApiKeyDiskSCSI); ok && storages.Scsi != nil {
+		return storages.Scsi.mapToIntMap()[i].convertDataStructure()
+	}
+	if i, ok := slot(qemuPrefixApiKeyDiskVirtIO); ok && storages.VirtIO != nil {
+		return storages.VirtIO.mapToIntMap()[i].convertDataStructure()
+	}
+	return nil
+}
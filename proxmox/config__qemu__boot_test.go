@@ -0,0 +1,306 @@
+package proxmox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testData_ConfigQemu_Boot_Api() qemuTestsApiFunc {
+	disk := func(id QemuDiskId) QemuBootDevice { return QemuBootDevice{Disk: &id} }
+	network := func(id QemuNetworkInterfaceID) QemuBootDevice { return QemuBootDevice{Network: &id} }
+	other := func(device string) QemuBootDevice { return QemuBootDevice{Other: &device} }
+	iso := func() *QemuCdRom { return &QemuCdRom{Iso: &IsoFile{Storage: "local", File: "debian.iso"}} }
+	return qemuTestsApiFunc(func() qemuTestsAPI {
+		return qemuTestsAPI{
+			create: []qemuTestCaseAPI{
+				{name: `empty`,
+					config: &ConfigQemu{Boot: &QemuBootOrder{}}},
+				{name: `set`,
+					config: &ConfigQemu{Boot: &QemuBootOrder{disk("scsi0"), disk("ide2"), network(1)}},
+					body:   map[string]string{"boot": "order%3Dscsi0%3Bide2%3Bnet1"}}, // "order=scsi0;ide2;net1"
+				{name: `other devices`,
+					config: &ConfigQemu{Boot: &QemuBootOrder{other("hostpci0"), disk("scsi0"), other("usb1")}},
+					body:   map[string]string{"boot": "order%3Dhostpci0%3Bscsi0%3Busb1"}}}, // "order=hostpci0;scsi0;usb1"
+			update: []qemuTestCaseAPI{
+				{name: `change`,
+					config:        &ConfigQemu{Boot: &QemuBootOrder{network(0), disk("virtio3")}},
+					currentLegacy: ConfigQemu{Boot: &QemuBootOrder{disk("virtio3"), network(0)}},
+					body:          map[string]string{"boot": "order%3Dnet0%3Bvirtio3"}}, // "order=net0;virtio3"
+				{name: `create`,
+					config: &ConfigQemu{Boot: &QemuBootOrder{disk("sata1")}},
+					body:   map[string]string{"boot": "order%3Dsata1"}}, // "order=sata1"
+				{name: `delete`,
+					config:        &ConfigQemu{Boot: &QemuBootOrder{}},
+					currentLegacy: ConfigQemu{Boot: &QemuBootOrder{disk("scsi0")}},
+					body:          map[string]string{"delete": "boot"}},
+				{name: `same no effect`,
+					config:        &ConfigQemu{Boot: &QemuBootOrder{disk("scsi0"), network(0)}},
+					currentLegacy: ConfigQemu{Boot: &QemuBootOrder{disk("scsi0"), network(0)}}},
+				{name: `removed devices pruned`,
+					config: &ConfigQemu{
+						Disks:    &QemuStorages{Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{Delete: true}}},
+						Networks: QemuNetworkInterfaces{QemuNetworkInterfaceID0: QemuNetworkInterface{Delete: true}}},
+					currentLegacy: ConfigQemu{
+						Boot:  &QemuBootOrder{disk("scsi0"), disk("ide2"), network(0)},
+						Disks: &QemuStorages{Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{CdRom: &QemuCdRom{}}}},
+						Networks: QemuNetworkInterfaces{QemuNetworkInterfaceID0: QemuNetworkInterface{
+							Bridge: new("vmbr0"),
+							Model:  new(QemuNetworkModelVirtIO)}}},
+					currentUpdate: configQemuUpdate{disks: &QemuStorages{Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{CdRom: &QemuCdRom{}}}}},
+					body:          map[string]string{"boot": "order%3Dide2"}}, // "order=ide2"
+				{name: `removed devices pruned all`,
+					config: &ConfigQemu{
+						Disks: &QemuStorages{Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{Delete: true}}}},
+					currentLegacy: ConfigQemu{
+						Boot:  &QemuBootOrder{disk("scsi0")},
+						Disks: &QemuStorages{Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{CdRom: &QemuCdRom{}}}}},
+					currentUpdate: configQemuUpdate{disks: &QemuStorages{Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{CdRom: &QemuCdRom{}}}}},
+					body:          map[string]string{"delete": "boot,scsi0"}},
+				{name: `removed devices pruned other devices kept`,
+					config: &ConfigQemu{
+						Disks: &QemuStorages{Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{Delete: true}}}},
+					currentLegacy: ConfigQemu{
+						Boot:  &QemuBootOrder{other("hostpci0"), disk("scsi0"), other("usb1")},
+						Disks: &QemuStorages{Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{CdRom: &QemuCdRom{}}}}},
+					currentUpdate: configQemuUpdate{disks: &QemuStorages{Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{CdRom: &QemuCdRom{}}}}},
+					body: map[string]string{
+						"boot":   "order%3Dhostpci0%3Busb1", // "order=hostpci0;usb1"
+						"delete": "scsi0"}},
+				{name: `removed devices moved volume followed`,
+					config: &ConfigQemu{
+						Disks: &QemuStorages{
+							Ide:  &QemuIdeDisks{Disk_2: &QemuIdeStorage{Delete: true}},
+							Sata: &QemuSataDisks{Disk_0: &QemuSataStorage{CdRom: iso()}}}},
+					currentLegacy: ConfigQemu{
+						Boot:  &QemuBootOrder{disk("scsi0"), disk("ide2")},
+						Disks: &QemuStorages{Ide: &QemuIdeDisks{Disk_2: &QemuIdeStorage{CdRom: iso()}}}},
+					currentUpdate: configQemuUpdate{disks: &QemuStorages{Ide: &QemuIdeDisks{Disk_2: &QemuIdeStorage{CdRom: iso()}}}},
+					body: map[string]string{
+						"boot":   "order%3Dscsi0%3Bsata0", // "order=scsi0;sata0"
+						"delete": "ide2"}},
+				{name: `removed devices moved volume not identified`,
+					config: &ConfigQemu{
+						Disks: &QemuStorages{
+							Ide:  &QemuIdeDisks{Disk_2: &QemuIdeStorage{Delete: true}},
+							Sata: &QemuSataDisks{Disk_0: &QemuSataStorage{CdRom: &QemuCdRom{}}}}},
+					currentLegacy: ConfigQemu{
+						Boot:  &QemuBootOrder{disk("scsi0"), disk("ide2")},
+						Disks: &QemuStorages{Ide: &QemuIdeDisks{Disk_2: &QemuIdeStorage{CdRom: &QemuCdRom{}}}}},
+					currentUpdate: configQemuUpdate{disks: &QemuStorages{Ide: &QemuIdeDisks{Disk_2: &QemuIdeStorage{CdRom: &QemuCdRom{}}}}},
+					body: map[string]string{
+						"boot":   "order%3Dscsi0", // "order=scsi0"
+						"delete": "ide2"}},
+				{name: `unrelated change no effect`,
+					config: &ConfigQemu{
+						Disks: &QemuStorages{Scsi: &QemuScsiDisks{Disk_1: &QemuScsiStorage{Delete: true}}}},
+					currentLegacy: ConfigQemu{
+						Boot:  &QemuBootOrder{disk("scsi0")},
+						Disks: &QemuStorages{Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{CdRom: &QemuCdRom{}}}}}}}}
+	})
+}
+
+func testData_ConfigQemu_Boot_Validate() qemuTestTypeValidateFunc {
+	disk := func(id QemuDiskId) QemuBootDevice { return QemuBootDevice{Disk: &id} }
+	network := func(id QemuNetworkInterfaceID) QemuBootDevice { return QemuBootDevice{Network: &id} }
+	cdRom := func() *QemuStorages {
+		return &QemuStorages{Ide: &QemuIdeDisks{Disk_2: &QemuIdeStorage{CdRom: &QemuCdRom{}}}}
+	}
+	networks := func() QemuNetworkInterfaces {
+		return QemuNetworkInterfaces{QemuNetworkInterfaceID0: QemuNetworkInterface{
+			Bridge: new("vmbr0"),
+			Model:  new(QemuNetworkModelVirtIO)}}
+	}
+	return qemuTestTypeValidateFunc(func() (qemuTestTypeInvalid, qemuTestTypeValid) {
+		invalid := qemuTestTypeInvalid{
+			createUpdate: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuBootDevice_Error_Empty)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Boot: &QemuBootOrder{{}}}),
+					err:   errors.New(QemuBootDevice_Error_Empty)},
+				{name: `errors.New(QemuBootDevice_Error_MutuallyExclusive)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Boot: &QemuBootOrder{{
+						Disk:    new(QemuDiskId("ide2")),
+						Network: new(QemuNetworkInterfaceID0)}}}),
+					err: errors.New(QemuBootDevice_Error_MutuallyExclusive)},
+				{name: `errors.New(QemuBootDevice_Error_MutuallyExclusive) other`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Boot: &QemuBootOrder{{
+						Network: new(QemuNetworkInterfaceID0),
+						Other:   new("hostpci0")}}}),
+					err: errors.New(QemuBootDevice_Error_MutuallyExclusive)},
+				{name: `errors.New(QemuBootDevice_Error_OtherInvalid) empty`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Boot: &QemuBootOrder{{Other: new("")}}}),
+					err:   errors.New(QemuBootDevice_Error_OtherInvalid)},
+				{name: `errors.New(QemuBootDevice_Error_OtherInvalid) separator`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Boot: &QemuBootOrder{{Other: new("hostpci0;net0")}}}),
+					err:   errors.New(QemuBootDevice_Error_OtherInvalid)},
+				{name: `errors.New(ERROR_QemuDiskId_Invalid)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Boot: &QemuBootOrder{disk("ide4")}}),
+					err:   errors.New(ERROR_QemuDiskId_Invalid)},
+				{name: `errors.New(QemuNetworkInterfaceID_Error_Invalid)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Boot: &QemuBootOrder{network(32)}}),
+					err:   errors.New(QemuNetworkInterfaceID_Error_Invalid)},
+				{name: `errors.New(QemuBootOrder_Error_Duplicate)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{
+						Boot:  &QemuBootOrder{disk("ide2"), disk("ide2")},
+						Disks: cdRom()}),
+					err: errors.New(QemuBootOrder_Error_Duplicate)},
+				{name: `errors.New(QemuBootOrder_Error_DeviceNotFound) disk`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{
+						Boot:  &QemuBootOrder{disk("ide2"), disk("ide3")},
+						Disks: cdRom()}),
+					err: errors.New(QemuBootOrder_Error_DeviceNotFound)},
+				{name: `errors.New(QemuBootOrder_Error_DeviceNotFound) network`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{
+						Boot:     &QemuBootOrder{network(1)},
+						Networks: networks()}),
+					err: errors.New(QemuBootOrder_Error_DeviceNotFound)}},
+			update: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuBootOrder_Error_DeviceRemoved) disk`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{
+						Boot:  &QemuBootOrder{disk("ide2")},
+						Disks: &QemuStorages{Ide: &QemuIdeDisks{Disk_2: &QemuIdeStorage{Delete: true}}}}),
+					current: &ConfigQemu{Disks: cdRom()},
+					err:     errors.New(QemuBootOrder_Error_DeviceRemoved)},
+				{name: `errors.New(QemuBootOrder_Error_DeviceRemoved) network`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{
+						Boot:     &QemuBootOrder{network(0)},
+						Networks: QemuNetworkInterfaces{QemuNetworkInterfaceID0: QemuNetworkInterface{Delete: true}}}),
+					current: &ConfigQemu{Networks: networks()},
+					err:     errors.New(QemuBootOrder_Error_DeviceRemoved)}}}
+		valid := qemuTestTypeValid{
+			createUpdate: []qemuTestCaseValid{
+				{name: `empty`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Boot: &QemuBootOrder{}})},
+				{name: `devices in config`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{
+						Boot:     &QemuBootOrder{disk("ide2"), network(0)},
+						Disks:    cdRom(),
+						Networks: networks()})},
+				{name: `iso`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{
+						Boot: &QemuBootOrder{disk("ide2")},
+						Iso:  &IsoFile{Storage: "local", File: "debian.iso"}})},
+				{name: `other devices`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Boot: &QemuBootOrder{{Other: new("hostpci0")}, {Other: new("usb1")}}})}},
+			update: []qemuTestCaseValid{
+				{name: `devices in current`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Boot: &QemuBootOrder{network(0), disk("ide2")}}),
+					current: &ConfigQemu{
+						Disks:    cdRom(),
+						Networks: networks()}},
+				{name: `device moved between buses`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{
+						Boot: &QemuBootOrder{disk("sata0")},
+						Disks: &QemuStorages{
+							Ide:  &QemuIdeDisks{Disk_2: &QemuIdeStorage{Delete: true}},
+							Sata: &QemuSataDisks{Disk_0: &QemuSataStorage{CdRom: &QemuCdRom{}}}}}),
+					current: &ConfigQemu{Disks: cdRom()}},
+				{name: `removed device not in order`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{
+						Boot:     &QemuBootOrder{disk("ide2")},
+						Networks: QemuNetworkInterfaces{QemuNetworkInterfaceID0: QemuNetworkInterface{Delete: true}}}),
+					current: &ConfigQemu{
+						Disks:    cdRom(),
+						Networks: networks()}}}}
+		return invalid, valid
+	})
+}
+
+func Test_ConfigQemu_Boot_Api(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_Boot_Api().Test(t)
+}
+
+func Test_ConfigQemu_Boot_Validate(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_Boot_Validate().Test(t)
+}
+
+func Test_QemuBootOrder_Validate(t *testing.T) {
+	t.Parallel()
+	validate := func(t *testing.T, config ConfigQemu, current *ConfigQemu, version Version, expectedErr error, valid bool) {
+		t.Helper()
+		err := config.Boot.validate(&config, current)
+		if valid {
+			require.NoError(t, err)
+		} else {
+			require.Error(t, err)
+			if expectedErr != nil {
+				require.Equal(t, expectedErr, err)
+			}
+		}
+	}
+	testData_ConfigQemu_Boot_Validate().Inject(t, validate)
+}
+
+func testData_ConfigQemu_Boot_Get() []qemuTestCaseGet {
+	return []qemuTestCaseGet{
+		{name: `empty`,
+			input:  map[string]any{"boot": string(" ")},
+			output: testQemuBaseConfig_get(ConfigQemu{Boot: &QemuBootOrder{}})},
+		{name: `legacy`,
+			input:  map[string]any{"boot": string("cdn")},
+			output: testQemuBaseConfig_get(ConfigQemu{Boot: &QemuBootOrder{}})},
+		{name: `order`,
+			input: map[string]any{"boot": string("order=virtio0;hostpci0;ide2;usb1;net3")},
+			output: testQemuBaseConfig_get(ConfigQemu{Boot: &QemuBootOrder{
+				{Disk: new(QemuDiskId("virtio0"))},
+				{Other: new("hostpci0")},
+				{Disk: new(QemuDiskId("ide2"))},
+				{Other: new("usb1")},
+				{Network: new(QemuNetworkInterfaceID3)}}})},
+	}
+}
+
+func Test_rawConfigQemu_GetBoot(t *testing.T) {
+	t.Parallel()
+	disk := func(id QemuDiskId) QemuBootDevice { return QemuBootDevice{Disk: &id} }
+	network := func(id QemuNetworkInterfaceID) QemuBootDevice { return QemuBootDevice{Network: &id} }
+	devices := func(settings map[string]any) map[string]any {
+		raw := map[string]any{
+			"bootdisk": "scsi0",
+			"ide2":     "none,media=cdrom",
+			"net0":     "virtio=BC:24:11:E2:20:5B,bridge=vmbr0",
+			"net3":     "virtio=BC:24:11:E2:20:5C,bridge=vmbr1",
+			"sata1":    "local:iso/debian.iso,media=cdrom,size=377M",
+			"scsi0":    "local-lvm:vm-100-disk-0,size=8G",
+			"scsi1":    "local-lvm:vm-100-disk-1,size=8G"}
+		for k, v := range settings {
+			raw[k] = v
+		}
+		return raw
+	}
+	tests := []struct {
+		name   string
+		input  map[string]any
+		output *QemuBootOrder
+	}{
+		{name: `not set`,
+			input: devices(nil)},
+		{name: `legacy`,
+			input:  devices(map[string]any{"boot": "cdn"}),
+			output: &QemuBootOrder{disk("scsi0"), disk("ide2"), disk("sata1"), network(0), network(3)}},
+		{name: `legacy key`,
+			input:  devices(map[string]any{"boot": "legacy=nd"}),
+			output: &QemuBootOrder{network(0), network(3), disk("ide2"), disk("sata1")}},
+		{name: `legacy floppy`,
+			input:  devices(map[string]any{"boot": "ac"}),
+			output: &QemuBootOrder{disk("scsi0")}},
+		{name: `legacy no bootdisk`,
+			input:  devices(map[string]any{"boot": "c", "bootdisk": "scsi5"}),
+			output: &QemuBootOrder{}},
+		{name: `legacy bootdisk cdrom`,
+			input:  devices(map[string]any{"boot": "c", "bootdisk": "ide2"}),
+			output: &QemuBootOrder{}},
+		{name: `order`,
+			input:  devices(map[string]any{"boot": "order=net3;scsi1"}),
+			output: &QemuBootOrder{network(3), disk("scsi1")}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.output, (&rawConfigQemu{a: test.input}).GetBoot())
+		})
+	}
+}
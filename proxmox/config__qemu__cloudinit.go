@@ -19,6 +19,7 @@ type CloudInit struct {
 	Username          *string                    `json:"username,omitempty"`     // TODO custom type
 }
 
+// Deprecated: the error is now returned by CapabilityCloudInitUpgradePackages.
 const CloudInit_Error_UpgradePackagesPre8 = "upgradePackages is only available in version 8 and above"
 
 func (config CloudInit) mapToAPI(current *CloudInit, params map[string]any, version Version) (delete string) {
@@ -75,7 +76,7 @@ func (config CloudInit) mapToAPI(current *CloudInit, params map[string]any, vers
 		}
 	}
 	// Shared
-	if config.UpgradePackages != nil && version.Major >= 8 {
+	if config.UpgradePackages != nil && CapabilityCloudInitUpgradePackages.SupportedBy(version) {
 		params[qemuApiKeyCloudInitUpgrade] = Btoi(*config.UpgradePackages)
 	}
 	if config.UserPassword != nil && *config.UserPassword != "" {
@@ -127,8 +128,10 @@ func (ci CloudInit) Validate(version Version) error {
 			return err
 		}
 	}
-	if ci.UpgradePackages != nil && *ci.UpgradePackages && version.Encode() < version_8_0_0 {
-		return errors.New(CloudInit_Error_UpgradePackagesPre8)
+	if ci.UpgradePackages != nil && *ci.UpgradePackages {
+		if err := CapabilityCloudInitUpgradePackages.check(version); err != nil {
+			return err
+		}
 	}
 	return ci.NetworkInterfaces.Validate()
 }
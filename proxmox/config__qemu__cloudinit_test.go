@@ -83,10 +83,10 @@ func Test_CloudInit_Validate(t *testing.T) {
 		{name: `Valid CloudInit UpgradePackages v8`,
 			version: Version{Major: 8},
 			input:   CloudInit{UpgradePackages: util.Pointer(true)}},
-		{name: `Invalid errors.New(CloudInit_Error_UpgradePackagesPre8)`,
+		{name: `Invalid CapabilityCloudInitUpgradePackages`,
 			version: Version{Major: 7, Minor: 255, Patch: 255},
 			input:   CloudInit{UpgradePackages: util.Pointer(true)},
-			output:  errors.New(CloudInit_Error_UpgradePackagesPre8)},
+			output:  CapabilityCloudInitUpgradePackages.error(Version{Major: 7, Minor: 255, Patch: 255})},
 		{name: `Invalid errors.New(CloudInitSnippetPath_Error_InvalidCharacters)`,
 			input: CloudInit{Custom: &CloudInitCustom{User: &CloudInitSnippet{
 				FilePath: CloudInitSnippetPath(test_data_qemu.CloudInitSnippetPath_Character_Illegal()[0])}}},
@@ -1026,6 +1026,7 @@ type QemuStorages struct {
 	Sata   *QemuSataDisks   `json:"sata,omitempty"`
 	Scsi   *QemuScsiDisks   `json:"scsi,omitempty"`
 	VirtIO *QemuVirtIODisks `json:"virtio,omitempty"`
+	Unused QemuUnusedDisks  `json:"unused,omitempty"`
 }
 
 // Return the cloud init disk that should be removed.
@@ -1078,6 +1079,9 @@ func (storages QemuStorages) mapToApiValues(currentStorages QemuStorages, params
 	if storages.VirtIO != nil {
 		storages.VirtIO.mapToApiValues(currentStorages.VirtIO, params, delete)
 	}
+	if storages.Unused != nil {
+		storages.Unused.mapToApiValues(currentStorages.Unused, params, delete)
+	}
 }
 
 func (raw *rawConfigQemu) GetDisks() (disks *QemuStorages, linkedID *GuestID) {
@@ -1087,11 +1091,12 @@ func (raw *rawConfigQemu) GetDisks() (disks *QemuStorages, linkedID *GuestID) {
 		Sata:   raw.disksSata(tmpLinkedID),
 		Scsi:   raw.disksSCSI(tmpLinkedID),
 		VirtIO: raw.disksVirtIO(tmpLinkedID),
+		Unused: raw.disksUnused(),
 	}
 	if *tmpLinkedID != 0 {
 		linkedID = tmpLinkedID
 	}
-	if storage.Ide != nil || storage.Sata != nil || storage.Scsi != nil || storage.VirtIO != nil {
+	if storage.Ide != nil || storage.Sata != nil || storage.Scsi != nil || storage.VirtIO != nil || storage.Unused != nil {
 		return &storage, linkedID
 	}
 	return nil, nil
@@ -0,0 +1,141 @@
+package proxmox
+
+import (
+	"errors"
+	"strings"
+)
+
+// QemuHotplug is the set of device types that may be changed while the guest is running.
+type QemuHotplug struct {
+	CPU       *bool `json:"cpu,omitempty"`       // Never nil when returned
+	CloudInit *bool `json:"cloudinit,omitempty"` // Never nil when returned
+	Disk      *bool `json:"disk,omitempty"`      // Never nil when returned
+	Memory    *bool `json:"memory,omitempty"`    // Never nil when returned, requires NUMA to be enabled
+	Network   *bool `json:"network,omitempty"`   // Never nil when returned
+	USB       *bool `json:"usb,omitempty"`       // Never nil when returned
+}
+
+const QemuHotplug_Error_MemoryRequiresNuma = "memory hotplug requires numa to be enabled"
+
+const (
+	qemuApiSettingHotplugCPU       = "cpu"
+	qemuApiSettingHotplugCloudInit = "cloudinit"
+	qemuApiSettingHotplugDisk      = "disk"
+	qemuApiSettingHotplugMemory    = "memory"
+	qemuApiSettingHotplugNetwork   = "network"
+	qemuApiSettingHotplugUSB       = "usb"
+)
+
+// qemuHotplugDefault is the hotplug setting of a guest when it was never configured.
+func qemuHotplugDefault() QemuHotplug {
+	return QemuHotplug{
+		CPU:       new(false),
+		CloudInit: new(false),
+		Disk:      new(true),
+		Memory:    new(false),
+		Network:   new(true),
+		USB:       new(true)}
+}
+
+func (config QemuHotplug) combine(current QemuHotplug) QemuHotplug {
+	if config.CPU != nil {
+		current.CPU = config.CPU
+	}
+	if config.CloudInit != nil {
+		current.CloudInit = config.CloudInit
+	}
+	if config.Disk != nil {
+		current.Disk = config.Disk
+	}
+	if config.Memory != nil {
+		current.Memory = config.Memory
+	}
+	if config.Network != nil {
+		current.Network = config.Network
+	}
+	if config.USB != nil {
+		current.USB = config.USB
+	}
+	return current
+}
+
+func (config QemuHotplug) mapToApi() string {
+	settings := make([]string, 0, 6)
+	add := func(enabled *bool, setting string) {
+		if enabled != nil && *enabled {
+			settings = append(settings, setting)
+		}
+	}
+	add(config.Network, qemuApiSettingHotplugNetwork)
+	add(config.Disk, qemuApiSettingHotplugDisk)
+	add(config.CPU, qemuApiSettingHotplugCPU)
+	add(config.Memory, qemuApiSettingHotplugMemory)
+	add(config.USB, qemuApiSettingHotplugUSB)
+	add(config.CloudInit, qemuApiSettingHotplugCloudInit)
+	if len(settings) == 0 {
+		return "0"
+	}
+	return strings.Join(settings, comma)
+}
+
+func (config QemuHotplug) mapToApiCreate(builder *strings.Builder) {
+	builder.WriteString("&" + qemuApiKeyHotplug + "=")
+	builder.WriteString(config.combine(qemuHotplugDefault()).mapToApi())
+}
+
+func (config QemuHotplug) mapToApiUpdate(current QemuHotplug, builder *strings.Builder) {
+	currentStr := current.mapToApi()
+	if configStr := config.combine(current).mapToApi(); configStr != currentStr {
+		builder.WriteString("&" + qemuApiKeyHotplug + "=")
+		builder.WriteString(configStr)
+	}
+}
+
+// Validate checks the hotplug setting the guest will have after the config is applied on top of current.
+// current is nil when the guest is created, numa is whether the guest will have NUMA enabled.
+func (config QemuHotplug) Validate(current *QemuHotplug, numa bool) error {
+	hotplug := qemuHotplugDefault()
+	if current != nil {
+		hotplug = current.combine(hotplug)
+	}
+	hotplug = config.combine(hotplug)
+	if *hotplug.Memory && !numa {
+		return errors.New(QemuHotplug_Error_MemoryRequiresNuma)
+	}
+	return nil
+}
+
+func (raw *rawConfigQemu) GetHotplug() QemuHotplug {
+	v, isSet := raw.a[qemuApiKeyHotplug]
+	if !isSet {
+		return qemuHotplugDefault()
+	}
+	switch v.(string) {
+	case "", "1":
+		return qemuHotplugDefault()
+	}
+	hotplug := QemuHotplug{
+		CPU:       new(false),
+		CloudInit: new(false),
+		Disk:      new(false),
+		Memory:    new(false),
+		Network:   new(false),
+		USB:       new(false)}
+	for _, e := range strings.Split(v.(string), ",") {
+		switch e {
+		case qemuApiSettingHotplugCPU:
+			*hotplug.CPU = true
+		case qemuApiSettingHotplugCloudInit:
+			*hotplug.CloudInit = true
+		case qemuApiSettingHotplugDisk:
+			*hotplug.Disk = true
+		case qemuApiSettingHotplugMemory:
+			*hotplug.Memory = true
+		case qemuApiSettingHotplugNetwork:
+			*hotplug.Network = true
+		case qemuApiSettingHotplugUSB:
+			*hotplug.USB = true
+		}
+	}
+	return hotplug
+}
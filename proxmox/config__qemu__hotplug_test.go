@@ -0,0 +1,147 @@
+package proxmox
+
+import (
+	"errors"
+	"testing"
+)
+
+func testData_ConfigQemu_Hotplug_Api() qemuTestsApiFunc {
+	return qemuTestsApiFunc(func() qemuTestsAPI {
+		return qemuTestsAPI{
+			create: []qemuTestCaseAPI{
+				{name: `empty`,
+					config: &ConfigQemu{Hotplug: &QemuHotplug{}},
+					body:   map[string]string{"hotplug": "network%2Cdisk%2Cusb"}}, // "network,disk,usb"
+				{name: `all`,
+					config: &ConfigQemu{Hotplug: &QemuHotplug{
+						CPU:       new(true),
+						CloudInit: new(true),
+						Memory:    new(true)}},
+					body: map[string]string{"hotplug": "network%2Cdisk%2Ccpu%2Cmemory%2Cusb%2Ccloudinit"}}, // "network,disk,cpu,memory,usb,cloudinit"
+				{name: `none`,
+					config: &ConfigQemu{Hotplug: &QemuHotplug{
+						Disk:    new(false),
+						Network: new(false),
+						USB:     new(false)}},
+					body: map[string]string{"hotplug": "0"}}},
+			update: []qemuTestCaseAPI{
+				{name: `change`,
+					config: &ConfigQemu{Hotplug: &QemuHotplug{
+						CPU: new(true),
+						USB: new(false)}},
+					currentLegacy: ConfigQemu{Hotplug: new(qemuHotplugDefault())},
+					body:          map[string]string{"hotplug": "network%2Cdisk%2Ccpu"}}, // "network,disk,cpu"
+				{name: `change to none`,
+					config: &ConfigQemu{Hotplug: &QemuHotplug{
+						Disk: new(false)}},
+					currentLegacy: ConfigQemu{Hotplug: &QemuHotplug{
+						CPU:       new(false),
+						CloudInit: new(false),
+						Disk:      new(true),
+						Memory:    new(false),
+						Network:   new(false),
+						USB:       new(false)}},
+					body: map[string]string{"hotplug": "0"}},
+				{name: `current nil uses default`,
+					config: &ConfigQemu{Hotplug: &QemuHotplug{
+						Network: new(false)}},
+					body: map[string]string{"hotplug": "disk%2Cusb"}}, // "disk,usb"
+				{name: `same no effect`,
+					config: &ConfigQemu{Hotplug: &QemuHotplug{
+						Disk:    new(true),
+						Network: new(true)}},
+					currentLegacy: ConfigQemu{Hotplug: new(qemuHotplugDefault())}}}}
+	})
+}
+
+func testData_ConfigQemu_Hotplug_Validate() qemuTestTypeValidateFunc {
+	return qemuTestTypeValidateFunc(func() (qemuTestTypeInvalid, qemuTestTypeValid) {
+		invalid := qemuTestTypeInvalid{
+			createUpdate: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuHotplug_Error_MemoryRequiresNuma)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Hotplug: &QemuHotplug{
+						Memory: new(true)}}),
+					err: errors.New(QemuHotplug_Error_MemoryRequiresNuma)}},
+			update: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuHotplug_Error_MemoryRequiresNuma) current numa`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Hotplug: &QemuHotplug{
+						Memory: new(true)}}),
+					current: &ConfigQemu{CPU: &QemuCPU{Numa: new(false)}},
+					err:     errors.New(QemuHotplug_Error_MemoryRequiresNuma)},
+				{name: `errors.New(QemuHotplug_Error_MemoryRequiresNuma) disable numa`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{CPU: &QemuCPU{
+						Cores: new(QemuCpuCores(1)),
+						Numa:  new(false)}}),
+					current: &ConfigQemu{
+						CPU:     &QemuCPU{Numa: new(true)},
+						Hotplug: &QemuHotplug{Memory: new(true)}},
+					err: errors.New(QemuHotplug_Error_MemoryRequiresNuma)}}}
+		valid := qemuTestTypeValid{
+			createUpdate: []qemuTestCaseValid{
+				{name: `empty`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Hotplug: &QemuHotplug{}})},
+				{name: `memory with numa`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{
+						CPU: &QemuCPU{
+							Cores: new(QemuCpuCores(1)),
+							Numa:  new(true)},
+						Hotplug: &QemuHotplug{Memory: new(true)}})}},
+			update: []qemuTestCaseValid{
+				{name: `memory with current numa`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Hotplug: &QemuHotplug{
+						Memory: new(true)}}),
+					current: &ConfigQemu{CPU: &QemuCPU{Numa: new(true)}}},
+				{name: `disable memory and numa`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{
+						CPU: &QemuCPU{
+							Cores: new(QemuCpuCores(1)),
+							Numa:  new(false)},
+						Hotplug: &QemuHotplug{Memory: new(false)}}),
+					current: &ConfigQemu{
+						CPU:     &QemuCPU{Numa: new(true)},
+						Hotplug: &QemuHotplug{Memory: new(true)}}}}}
+		return invalid, valid
+	})
+}
+
+func Test_ConfigQemu_Hotplug_Api(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_Hotplug_Api().Test(t)
+}
+
+func Test_ConfigQemu_Hotplug_Validate(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_Hotplug_Validate().Test(t)
+}
+
+func testData_ConfigQemu_Hotplug_Get() []qemuTestCaseGet {
+	all := func(enabled bool) *QemuHotplug {
+		return &QemuHotplug{
+			CPU:       new(enabled),
+			CloudInit: new(enabled),
+			Disk:      new(enabled),
+			Memory:    new(enabled),
+			Network:   new(enabled),
+			USB:       new(enabled)}
+	}
+	return []qemuTestCaseGet{
+		{name: `all`,
+			input:  map[string]any{"hotplug": string("network,disk,cpu,memory,usb,cloudinit")},
+			output: testQemuBaseConfig_get(ConfigQemu{Hotplug: all(true)})},
+		{name: `disabled`,
+			input:  map[string]any{"hotplug": string("0")},
+			output: testQemuBaseConfig_get(ConfigQemu{Hotplug: all(false)})},
+		{name: `enabled`,
+			input:  map[string]any{"hotplug": string("1")},
+			output: testQemuBaseConfig_get(ConfigQemu{Hotplug: new(qemuHotplugDefault())})},
+		{name: `some`,
+			input: map[string]any{"hotplug": string("cpu,usb")},
+			output: testQemuBaseConfig_get(ConfigQemu{Hotplug: &QemuHotplug{
+				CPU:       new(true),
+				CloudInit: new(false),
+				Disk:      new(false),
+				Memory:    new(false),
+				Network:   new(false),
+				USB:       new(true)}})},
+	}
+}
@@ -0,0 +1,248 @@
+package proxmox
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+type QemuMachine struct {
+	Type    *QemuMachineType    `json:"type,omitempty"`    // Never nil when returned, types this SDK does not know (e.g. `virt`) are returned as is
+	Version *QemuMachineVersion `json:"version,omitempty"` // Empty uses the latest version available on the node
+	VIOMMU  *QemuMachineVIOMMU  `json:"viommu,omitempty"`  // Requires Proxmox VE 8.1 or later
+}
+
+const (
+	QemuMachine_Error_TypeRequired    = "machine type is required during create"
+	QemuMachine_Error_VIOMMUIntelType = "the intel viommu requires the q35 machine type"
+)
+
+const (
+	qemuApiMachinePrefixI440fx = "pc-i440fx-"
+	qemuApiMachinePrefixQ35    = "pc-q35-"
+	qemuApiMachineI440fx       = "pc"
+	qemuApiMachineQ35          = "q35"
+)
+
+func (config QemuMachine) combine(current QemuMachine) QemuMachine {
+	if config.Type != nil {
+		current.Type = config.Type
+	}
+	if config.Version != nil {
+		current.Version = config.Version
+	}
+	if config.VIOMMU != nil {
+		current.VIOMMU = config.VIOMMU
+	}
+	return current
+}
+
+func (config QemuMachine) mapToApi(version Version) string {
+	var machine string
+	var pinned string
+	if config.Version != nil {
+		pinned = config.Version.String()
+	}
+	if config.Type != nil {
+		switch *config.Type {
+		case QemuMachineTypeI440fx:
+			machine = qemuApiMachineI440fx
+			if pinned != "" {
+				machine = qemuApiMachinePrefixI440fx + pinned
+			}
+		case QemuMachineTypeQ35:
+			machine = qemuApiMachineQ35
+			if pinned != "" {
+				machine = qemuApiMachinePrefixQ35 + pinned
+			}
+		default: // unknown types are written back as they were read
+			machine = config.Type.String()
+		}
+	}
+	if config.VIOMMU == nil || *config.VIOMMU == QemuMachineVIOMMUNone || !CapabilityMachineVIOMMU.SupportedBy(version) {
+		return machine
+	}
+	if machine != "" {
+		machine = "type" + equal + machine + comma
+	}
+	return machine + "viommu" + equal + config.VIOMMU.String()
+}
+
+func (config QemuMachine) mapToApiCreate(version Version, builder *strings.Builder) {
+	if v := config.mapToApi(version); v != "" {
+		builder.WriteString("&" + qemuApiKeyMachine + "=")
+		builder.WriteString(v)
+	}
+}
+
+func (config QemuMachine) mapToApiUpdate(current QemuMachine, version Version, builder, delete *strings.Builder) {
+	currentStr := current.mapToApi(version)
+	configStr := config.combine(current).mapToApi(version)
+	if currentStr == configStr {
+		return
+	}
+	if configStr == "" {
+		delete.WriteString("," + qemuApiKeyMachine)
+		return
+	}
+	builder.WriteString("&" + qemuApiKeyMachine + "=")
+	builder.WriteString(configStr)
+}
+
+// Validate checks the machine the guest will have after the config is applied on top of current.
+// current is nil when the guest is created.
+func (config QemuMachine) Validate(current *QemuMachine, version Version) error {
+	if current == nil { // create
+		return config.validateCreate(version)
+	}
+	return config.validateUpdate(current, version)
+}
+
+func (config QemuMachine) validate(machine QemuMachine, version Version) error {
+	if config.Type != nil {
+		if err := config.Type.Validate(); err != nil {
+			return err
+		}
+	}
+	if config.Version != nil {
+		if err := config.Version.Validate(); err != nil {
+			return err
+		}
+	}
+	if config.VIOMMU != nil {
+		if err := config.VIOMMU.Validate(); err != nil {
+			return err
+		}
+		if *config.VIOMMU != QemuMachineVIOMMUNone {
+			if err := CapabilityMachineVIOMMU.check(version); err != nil {
+				return err
+			}
+		}
+	}
+	if machine.VIOMMU != nil && *machine.VIOMMU == QemuMachineVIOMMUIntel && machine.Type != nil && *machine.Type != QemuMachineTypeQ35 {
+		return errors.New(QemuMachine_Error_VIOMMUIntelType)
+	}
+	return nil
+}
+
+func (config QemuMachine) validateCreate(version Version) error {
+	if config.Type == nil {
+		return errors.New(QemuMachine_Error_TypeRequired)
+	}
+	return config.validate(config, version)
+}
+
+// current is nil when the guest has no machine set, Proxmox VE then uses i440fx.
+func (config QemuMachine) validateUpdate(current *QemuMachine, version Version) error {
+	machine := QemuMachine{Type: new(QemuMachineTypeI440fx)}
+	if current != nil {
+		machine = *current
+		if config.Type != nil && current.Type != nil && *config.Type == *current.Type { // types this SDK does not know may be sent back unchanged
+			config.Type = nil
+		}
+	}
+	return config.validate(config.combine(machine), version)
+}
+
+func (raw *rawConfigQemu) GetMachine() *QemuMachine {
+	v, isSet := raw.a[qemuApiKeyMachine]
+	if !isSet {
+		return nil
+	}
+	machine := QemuMachine{Type: new(QemuMachineTypeI440fx)}
+	for key, value := range splitStringOfSettings(v.(string)) {
+		switch key {
+		case "type":
+			machine.setType(value)
+		case "viommu":
+			machine.VIOMMU = new(QemuMachineVIOMMU(value))
+		default:
+			if value == "" && key != "" { // the type is the default key
+				machine.setType(key)
+			}
+		}
+	}
+	return &machine
+}
+
+func (machine *QemuMachine) setType(raw string) {
+	switch {
+	case raw == qemuApiMachineQ35:
+		*machine.Type = QemuMachineTypeQ35
+	case strings.HasPrefix(raw, qemuApiMachinePrefixQ35):
+		*machine.Type = QemuMachineTypeQ35
+		machine.Version = new(QemuMachineVersion(strings.TrimPrefix(raw, qemuApiMachinePrefixQ35)))
+	case strings.HasPrefix(raw, qemuApiMachinePrefixI440fx):
+		machine.Version = new(QemuMachineVersion(strings.TrimPrefix(raw, qemuApiMachinePrefixI440fx)))
+	case raw != qemuApiMachineI440fx: // keep types like `virt` so an update does not replace them with i440fx
+		*machine.Type = QemuMachineType(raw)
+	}
+}
+
+// Enum
+//
+//	const (
+//		QemuMachineTypeI440fx
+//		QemuMachineTypeQ35
+//	)
+type QemuMachineType string
+
+const (
+	QemuMachineTypeI440fx QemuMachineType = "i440fx"
+	QemuMachineTypeQ35    QemuMachineType = "q35"
+)
+
+const QemuMachineType_Error = "invalid machine type"
+
+func (machineType QemuMachineType) String() string { return string(machineType) } // String is for fmt.Stringer.
+
+func (machineType QemuMachineType) Validate() error {
+	switch machineType {
+	case QemuMachineTypeI440fx, QemuMachineTypeQ35:
+		return nil
+	}
+	return errors.New(QemuMachineType_Error)
+}
+
+// QemuMachineVersion pins the guest to a QEMU machine version, e.g. `8.1` or `8.1+pve0`.
+type QemuMachineVersion string
+
+const QemuMachineVersion_Error = "invalid machine version"
+
+var regexQemuMachineVersion = regexp.MustCompile(`^\d+\.\d+(\+pve\d+)?$`)
+
+func (machineVersion QemuMachineVersion) String() string { return string(machineVersion) } // String is for fmt.Stringer.
+
+func (machineVersion QemuMachineVersion) Validate() error {
+	if machineVersion == "" || regexQemuMachineVersion.MatchString(string(machineVersion)) {
+		return nil
+	}
+	return errors.New(QemuMachineVersion_Error)
+}
+
+// Enum
+//
+//	const (
+//		QemuMachineVIOMMUIntel
+//		QemuMachineVIOMMUNone
+//		QemuMachineVIOMMUVirtio
+//	)
+type QemuMachineVIOMMU string
+
+const (
+	QemuMachineVIOMMUIntel  QemuMachineVIOMMU = "intel"
+	QemuMachineVIOMMUNone   QemuMachineVIOMMU = ""
+	QemuMachineVIOMMUVirtio QemuMachineVIOMMU = "virtio"
+)
+
+const QemuMachineVIOMMU_Error = "invalid machine viommu"
+
+func (viommu QemuMachineVIOMMU) String() string { return string(viommu) } // String is for fmt.Stringer.
+
+func (viommu QemuMachineVIOMMU) Validate() error {
+	switch viommu {
+	case QemuMachineVIOMMUIntel, QemuMachineVIOMMUNone, QemuMachineVIOMMUVirtio:
+		return nil
+	}
+	return errors.New(QemuMachineVIOMMU_Error)
+}
@@ -0,0 +1,252 @@
+package proxmox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testData_ConfigQemu_Machine_Api() qemuTestsApiFunc {
+	return qemuTestsApiFunc(func() qemuTestsAPI {
+		return qemuTestsAPI{
+			createUpdate: []qemuTestCaseAPI{
+				{name: `i440fx`,
+					config: &ConfigQemu{Machine: &QemuMachine{
+						Type: new(QemuMachineTypeI440fx)}},
+					body: map[string]string{"machine": "pc"}},
+				{name: `i440fx version`,
+					config: &ConfigQemu{Machine: &QemuMachine{
+						Type:    new(QemuMachineTypeI440fx),
+						Version: new(QemuMachineVersion("8.1"))}},
+					body: map[string]string{"machine": "pc-i440fx-8.1"}},
+				{name: `q35`,
+					config: &ConfigQemu{Machine: &QemuMachine{
+						Type: new(QemuMachineTypeQ35)}},
+					body: map[string]string{"machine": "q35"}},
+				{name: `q35 version viommu`,
+					config: &ConfigQemu{Machine: &QemuMachine{
+						Type:    new(QemuMachineTypeQ35),
+						Version: new(QemuMachineVersion("9.0+pve1")),
+						VIOMMU:  new(QemuMachineVIOMMUIntel)}},
+					version: Version{Major: 8, Minor: 1},
+					body:    map[string]string{"machine": "type%3Dpc-q35-9.0+pve1%2Cviommu%3Dintel"}}, // "type=pc-q35-9.0+pve1,viommu=intel"
+				{name: `viommu unsupported version`,
+					config: &ConfigQemu{Machine: &QemuMachine{
+						Type:   new(QemuMachineTypeQ35),
+						VIOMMU: new(QemuMachineVIOMMUVirtio)}},
+					version: Version{Major: 8},
+					body:    map[string]string{"machine": "q35"}}},
+			update: []qemuTestCaseAPI{
+				{name: `change Type`,
+					config: &ConfigQemu{Machine: &QemuMachine{
+						Type: new(QemuMachineTypeQ35)}},
+					currentLegacy: ConfigQemu{Machine: &QemuMachine{
+						Type:    new(QemuMachineTypeI440fx),
+						Version: new(QemuMachineVersion("8.1"))}},
+					body: map[string]string{"machine": "pc-q35-8.1"}},
+				{name: `change Version latest`,
+					config: &ConfigQemu{Machine: &QemuMachine{
+						Version: new(QemuMachineVersion(""))}},
+					currentLegacy: ConfigQemu{Machine: &QemuMachine{
+						Type:    new(QemuMachineTypeQ35),
+						Version: new(QemuMachineVersion("8.1"))}},
+					body: map[string]string{"machine": "q35"}},
+				{name: `change VIOMMU none`,
+					config: &ConfigQemu{Machine: &QemuMachine{
+						VIOMMU: new(QemuMachineVIOMMUNone)}},
+					currentLegacy: ConfigQemu{Machine: &QemuMachine{
+						Type:   new(QemuMachineTypeQ35),
+						VIOMMU: new(QemuMachineVIOMMUVirtio)}},
+					version: Version{Major: 8, Minor: 1},
+					body:    map[string]string{"machine": "q35"}},
+				{name: `unknown type kept`,
+					config: &ConfigQemu{Machine: &QemuMachine{
+						VIOMMU: new(QemuMachineVIOMMUNone)}},
+					currentLegacy: ConfigQemu{Machine: &QemuMachine{
+						Type:   new(QemuMachineType("virt")),
+						VIOMMU: new(QemuMachineVIOMMUVirtio)}},
+					version: Version{Major: 8, Minor: 1},
+					body:    map[string]string{"machine": "virt"}},
+				{name: `same no effect`,
+					config: &ConfigQemu{Machine: &QemuMachine{
+						Type:    new(QemuMachineTypeI440fx),
+						Version: new(QemuMachineVersion("8.1"))}},
+					currentLegacy: ConfigQemu{Machine: &QemuMachine{
+						Type:    new(QemuMachineTypeI440fx),
+						Version: new(QemuMachineVersion("8.1"))}}}}}
+	})
+}
+
+func testData_ConfigQemu_Machine_Validate() qemuTestTypeValidateFunc {
+	return qemuTestTypeValidateFunc(func() (qemuTestTypeInvalid, qemuTestTypeValid) {
+		invalid := qemuTestTypeInvalid{
+			createUpdate: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuMachineType_Error)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Type: new(QemuMachineType("invalid"))}}),
+					err: errors.New(QemuMachineType_Error)},
+				{name: `errors.New(QemuMachineVersion_Error)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Type:    new(QemuMachineTypeQ35),
+						Version: new(QemuMachineVersion("8.1.0"))}}),
+					err: errors.New(QemuMachineVersion_Error)},
+				{name: `errors.New(QemuMachineVIOMMU_Error)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Type:   new(QemuMachineTypeQ35),
+						VIOMMU: new(QemuMachineVIOMMU("invalid"))}}),
+					err: errors.New(QemuMachineVIOMMU_Error)},
+				{name: `errors.New(QemuMachine_Error_VIOMMUIntelType)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Type:   new(QemuMachineTypeI440fx),
+						VIOMMU: new(QemuMachineVIOMMUIntel)}}),
+					version: Version{Major: 8, Minor: 1},
+					err:     errors.New(QemuMachine_Error_VIOMMUIntelType)},
+				{name: `viommu version`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Type:   new(QemuMachineTypeQ35),
+						VIOMMU: new(QemuMachineVIOMMUVirtio)}}),
+					version: Version{Major: 8},
+					err:     CapabilityMachineVIOMMU.error(Version{Major: 8})}},
+			create: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuMachine_Error_TypeRequired)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Version: new(QemuMachineVersion("8.1"))}}),
+					err: errors.New(QemuMachine_Error_TypeRequired)}},
+			update: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuMachine_Error_VIOMMUIntelType) current type`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						VIOMMU: new(QemuMachineVIOMMUIntel)}}),
+					current: &ConfigQemu{Machine: &QemuMachine{
+						Type: new(QemuMachineTypeI440fx)}},
+					version: Version{Major: 8, Minor: 1},
+					err:     errors.New(QemuMachine_Error_VIOMMUIntelType)},
+				{name: `errors.New(QemuMachine_Error_VIOMMUIntelType) current nil`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						VIOMMU: new(QemuMachineVIOMMUIntel)}}),
+					current: &ConfigQemu{},
+					version: Version{Major: 8, Minor: 1},
+					err:     errors.New(QemuMachine_Error_VIOMMUIntelType)},
+				{name: `errors.New(QemuMachineType_Error) unknown type changed`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Type: new(QemuMachineType("virt"))}}),
+					current: &ConfigQemu{Machine: &QemuMachine{
+						Type: new(QemuMachineTypeQ35)}},
+					err: errors.New(QemuMachineType_Error)}}}
+		valid := qemuTestTypeValid{
+			createUpdate: []qemuTestCaseValid{
+				{name: `i440fx`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Type:    new(QemuMachineTypeI440fx),
+						Version: new(QemuMachineVersion("7.2")),
+						VIOMMU:  new(QemuMachineVIOMMUVirtio)}}),
+					version: Version{Major: 8, Minor: 1}},
+				{name: `q35`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Type:    new(QemuMachineTypeQ35),
+						Version: new(QemuMachineVersion("9.0+pve1")),
+						VIOMMU:  new(QemuMachineVIOMMUIntel)}}),
+					version: Version{Major: 8, Minor: 1}},
+				{name: `viommu none old version`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Type:   new(QemuMachineTypeQ35),
+						VIOMMU: new(QemuMachineVIOMMUNone)}}),
+					version: Version{Major: 8}}},
+			update: []qemuTestCaseValid{
+				{name: `only Version`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Version: new(QemuMachineVersion("8.0"))}}),
+					current: &ConfigQemu{Machine: &QemuMachine{
+						Type: new(QemuMachineTypeQ35)}}},
+				{name: `current nil`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Version: new(QemuMachineVersion("8.0"))}}),
+					current: &ConfigQemu{}},
+				{name: `current unknown type`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						VIOMMU: new(QemuMachineVIOMMUVirtio)}}),
+					current: &ConfigQemu{Machine: &QemuMachine{
+						Type: new(QemuMachineType("virt"))}},
+					version: Version{Major: 8, Minor: 1}},
+				{name: `current unknown type unchanged`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Type:    new(QemuMachineType("virt")),
+						Version: new(QemuMachineVersion("8.0"))}}),
+					current: &ConfigQemu{Machine: &QemuMachine{
+						Type: new(QemuMachineType("virt"))}}},
+				{name: `change type and viommu`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Machine: &QemuMachine{
+						Type:   new(QemuMachineTypeI440fx),
+						VIOMMU: new(QemuMachineVIOMMUVirtio)}}),
+					current: &ConfigQemu{Machine: &QemuMachine{
+						Type:   new(QemuMachineTypeQ35),
+						VIOMMU: new(QemuMachineVIOMMUIntel)}},
+					version: Version{Major: 8, Minor: 1}}}}
+		return invalid, valid
+	})
+}
+
+func Test_ConfigQemu_Machine_Api(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_Machine_Api().Test(t)
+}
+
+func Test_ConfigQemu_Machine_Validate(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_Machine_Validate().Test(t)
+}
+
+func Test_QemuMachine_Validate(t *testing.T) {
+	t.Parallel()
+	validate := func(t *testing.T, config ConfigQemu, current *ConfigQemu, version Version, expectedErr error, valid bool) {
+		t.Helper()
+		var err error
+		if current != nil {
+			err = config.Machine.validateUpdate(current.Machine, version)
+		} else {
+			err = config.Machine.Validate(nil, version)
+		}
+		if valid {
+			require.NoError(t, err)
+		} else {
+			require.Error(t, err)
+			if expectedErr != nil {
+				require.Equal(t, expectedErr, err)
+			}
+		}
+	}
+	testData_ConfigQemu_Machine_Validate().Inject(t, validate)
+}
+
+func testData_ConfigQemu_Machine_Get() []qemuTestCaseGet {
+	return []qemuTestCaseGet{
+		{name: `i440fx`,
+			input: map[string]any{"machine": string("pc")},
+			output: testQemuBaseConfig_get(ConfigQemu{Machine: &QemuMachine{
+				Type: new(QemuMachineTypeI440fx)}})},
+		{name: `i440fx version`,
+			input: map[string]any{"machine": string("pc-i440fx-8.1")},
+			output: testQemuBaseConfig_get(ConfigQemu{Machine: &QemuMachine{
+				Type:    new(QemuMachineTypeI440fx),
+				Version: new(QemuMachineVersion("8.1"))}})},
+		{name: `q35`,
+			input: map[string]any{"machine": string("q35")},
+			output: testQemuBaseConfig_get(ConfigQemu{Machine: &QemuMachine{
+				Type: new(QemuMachineTypeQ35)}})},
+		{name: `q35 version viommu`,
+			input: map[string]any{"machine": string("type=pc-q35-9.0+pve1,viommu=virtio")},
+			output: testQemuBaseConfig_get(ConfigQemu{Machine: &QemuMachine{
+				Type:    new(QemuMachineTypeQ35),
+				Version: new(QemuMachineVersion("9.0+pve1")),
+				VIOMMU:  new(QemuMachineVIOMMUVirtio)}})},
+		{name: `unknown type`,
+			input: map[string]any{"machine": string("virt")},
+			output: testQemuBaseConfig_get(ConfigQemu{Machine: &QemuMachine{
+				Type: new(QemuMachineType("virt"))}})},
+		{name: `viommu only`,
+			input: map[string]any{"machine": string("viommu=virtio")},
+			output: testQemuBaseConfig_get(ConfigQemu{Machine: &QemuMachine{
+				Type:   new(QemuMachineTypeI440fx),
+				VIOMMU: new(QemuMachineVIOMMUVirtio)}})},
+	}
+}
@@ -0,0 +1,291 @@
+package proxmox
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Telmate/proxmox-api-go/internal/util"
+)
+
+// QemuUpdatePlan describes what QemuGuestInterface.Update would change, nothing is applied when the plan is made.
+type QemuUpdatePlan struct {
+	Changes          []GuestConfigChange  `json:"changes"`
+	Deletes          []string             `json:"deletes"`
+	DiskMoves        []QemuPlanDiskMove   `json:"disk_moves"`
+	DiskResizes      []QemuPlanDiskResize `json:"disk_resizes"`
+	Migrate          *NodeName            `json:"migrate,omitempty"` // The node the guest will be migrated to.
+	Pool             *PoolName            `json:"pool,omitempty"`    // The pool the guest will be moved to, empty when it is removed from its pool.
+	State            *PowerState          `json:"state,omitempty"`   // The power state the guest will be put in.
+	PendingConflicts []string             `json:"pending_conflicts"` // Keys that are changed by the plan and already have a pending change.
+	// True when the guest is running and some changes only take effect after a reboot.
+	// This is an estimate based on the hotplug setting of the guest, Proxmox VE decides when the update is applied.
+	RebootRequired bool `json:"reboot_required"`
+	// True when the guest is running and has to be shut down before the update can be applied.
+	ShutdownRequired bool `json:"shutdown_required"`
+}
+
+// HasChanges returns true when applying the plan would change the guest.
+func (plan QemuUpdatePlan) HasChanges() bool {
+	return len(plan.Changes) != 0 || len(plan.Deletes) != 0 || len(plan.DiskMoves) != 0 || len(plan.DiskResizes) != 0 ||
+		plan.Migrate != nil || plan.Pool != nil || plan.State != nil
+}
+
+// GuestConfigChange is a single setting of the guest config that will be set.
+type GuestConfigChange struct {
+	Key string `json:"key"`
+	Old string `json:"old,omitempty"` // Empty when the setting is added.
+	New string `json:"new"`
+}
+
+type QemuPlanDiskMove struct {
+	ID      QemuDiskId      `json:"id"`
+	Storage string          `json:"storage"`
+	Format  *QemuDiskFormat `json:"format,omitempty"`
+}
+
+type QemuPlanDiskResize struct {
+	ID              QemuDiskId   `json:"id"`
+	SizeInKibibytes QemuDiskSize `json:"size"`
+}
+
+// markUpdate returns the disks that have to be moved or resized before the config can be updated,
+// and writes the settings that have to be deleted before the update to delete.
+// The EFI disk is returned separately as moving it requires the guest to be shut down.
+func (config ConfigQemu) markUpdate(currentLegacy *ConfigQemu, update *configQemuUpdate, delete *strings.Builder) (changes qemuUpdateChanges, efiDisk *qemuDiskMove) {
+	if config.Disks != nil {
+		update.disks, _ = update.raw.GetDisks()
+		if update.disks != nil {
+			changes = *config.Disks.markDiskChanges(*update.disks)
+			config.Disks.cloudInitRemove(*update.disks, delete)
+		}
+	}
+	if config.TPM != nil && currentLegacy.TPM != nil {
+		if disk := config.TPM.markChanges(*currentLegacy.TPM, delete); disk != nil {
+			changes.Move = append(changes.Move, *disk)
+		}
+	}
+	if config.EfiDisk != nil {
+		update.efiDisk = update.raw.GetEfiDisk()
+		if update.efiDisk != nil {
+			efiDisk = config.EfiDisk.markChangesUnsafe(update.efiDisk)
+		}
+	}
+	return
+}
+
+// plan compares the config with the current config of the guest.
+// state is the current power state of the guest and pending the raw list of its pending changes.
+func (config ConfigQemu) plan(currentLegacy *ConfigQemu, update configQemuUpdate, version Version, state PowerState, pending []any) QemuUpdatePlan {
+	plan := QemuUpdatePlan{
+		Changes:          []GuestConfigChange{},
+		Deletes:          []string{},
+		DiskMoves:        []QemuPlanDiskMove{},
+		DiskResizes:      []QemuPlanDiskResize{},
+		PendingConflicts: []string{}}
+	running := state == PowerStateRunning
+	hotplug := func(key string) bool {
+		return qemuHotplug(key, guestConfigValue(update.raw.a[key]), guestConfigValue(update.raw.a[qemuApiKeyHotplug]))
+	}
+	preDelete := &strings.Builder{}
+	changes, efiDisk := config.markUpdate(currentLegacy, &update, preDelete)
+	for _, e := range changes.Move {
+		plan.DiskMoves = append(plan.DiskMoves, QemuPlanDiskMove{ID: e.Id, Storage: e.Storage, Format: e.Format})
+	}
+	if efiDisk != nil {
+		plan.DiskMoves = append(plan.DiskMoves, QemuPlanDiskMove{ID: efiDisk.Id, Storage: efiDisk.Storage, Format: efiDisk.Format})
+		plan.ShutdownRequired = running
+	}
+	for _, e := range changes.Resize {
+		plan.DiskResizes = append(plan.DiskResizes, QemuPlanDiskResize{ID: e.Id, SizeInKibibytes: e.SizeInKibibytes})
+	}
+	if preDelete.Len() > 0 {
+		for _, key := range strings.Split(preDelete.String()[len(comma):], comma) {
+			plan.Deletes = append(plan.Deletes, key)
+			plan.ShutdownRequired = plan.ShutdownRequired || (running && !hotplug(key))
+		}
+	}
+	params, body := config.mapToApiUpdate(currentLegacy, update, version)
+	settings, deletes := guestApiSettings(combineParamsAndBody(params, body))
+	for _, key := range deletes {
+		if _, ok := update.raw.a[key]; !ok || slices.Contains(plan.Deletes, key) {
+			continue
+		}
+		plan.Deletes = append(plan.Deletes, key)
+		plan.RebootRequired = plan.RebootRequired || (running && !hotplug(key))
+	}
+	for key, value := range settings {
+		old := guestConfigValue(update.raw.a[key])
+		if old == value && !slices.Contains(plan.Deletes, key) {
+			continue
+		}
+		plan.Changes = append(plan.Changes, GuestConfigChange{Key: key, Old: old, New: value})
+		plan.RebootRequired = plan.RebootRequired || (running && !qemuHotplug(key, value, guestConfigValue(update.raw.a[qemuApiKeyHotplug])))
+	}
+	slices.SortFunc(plan.Changes, func(a, b GuestConfigChange) int { return strings.Compare(a.Key, b.Key) })
+	slices.Sort(plan.Deletes)
+	for _, item := range pending {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		_, isPending := m["pending"]
+		_, isDelete := m["delete"]
+		if !isPending && !isDelete {
+			continue
+		}
+		plan.RebootRequired = plan.RebootRequired || running
+		key, _ := m[pendingApiKeyKey].(string)
+		if slices.Contains(plan.Deletes, key) || slices.ContainsFunc(plan.Changes, func(e GuestConfigChange) bool { return e.Key == key }) {
+			plan.PendingConflicts = append(plan.PendingConflicts, key)
+		}
+	}
+	slices.Sort(plan.PendingConflicts)
+	if config.Node != nil && currentLegacy.Node != nil && *config.Node != *currentLegacy.Node {
+		plan.Migrate = util.Pointer(*config.Node)
+	}
+	if config.Pool != nil {
+		var pool PoolName
+		if currentLegacy.Pool != nil {
+			pool = *currentLegacy.Pool
+		}
+		if *config.Pool != pool {
+			plan.Pool = util.Pointer(*config.Pool)
+		}
+	}
+	if config.State != nil && *config.State != state {
+		plan.State = util.Pointer(*config.State)
+	}
+	return plan
+}
+
+// guestApiSettings returns the settings and deleted keys in the body of a config update.
+func guestApiSettings(body *[]byte) (map[string]string, []string) {
+	settings := map[string]string{}
+	deletes := []string{}
+	if body == nil {
+		return settings, deletes
+	}
+	values, err := url.ParseQuery(string(*body))
+	if err != nil {
+		return settings, deletes
+	}
+	for key, value := range values {
+		switch key {
+		case "delete":
+			for _, e := range strings.Split(value[0], ",") {
+				if e != "" && !slices.Contains(deletes, e) {
+					deletes = append(deletes, e)
+				}
+			}
+		case "digest":
+		default:
+			settings[key] = value[0]
+		}
+	}
+	return settings, deletes
+}
+
+// guestConfigValue returns a value of the raw guest config the way it is sent to the API.
+func guestConfigValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	}
+	return ""
+}
+
+// qemuHotplug returns true when Proxmox VE applies the setting to a running guest,
+// otherwise the change stays pending until the guest is rebooted.
+func qemuHotplug(key, value, hotplug string) bool {
+	switch hotplug {
+	case "", "1":
+		hotplug = "network,disk,usb"
+	case "0":
+		hotplug = ""
+	}
+	enabled := func(feature string) bool { return slices.Contains(strings.Split(hotplug, ","), feature) }
+	switch setting := strings.TrimRightFunc(key, unicode.IsDigit); setting {
+	case "balloon", "cpulimit", "cpuunits", "description", "hookscript", "lock", "migrate_downtime", "migrate_speed",
+		"name", "onboot", "protection", "shares", "startup", "tags", "unused", "vmstatestorage":
+		return true
+	case "net":
+		return enabled("network")
+	case "tablet", "usb":
+		return enabled("usb")
+	case "vcpus":
+		return enabled("cpu")
+	case "memory":
+		return enabled("memory")
+	case "ide", "sata", "scsi", "virtio":
+		if strings.Contains(value, "media=cdrom") {
+			return true
+		}
+		return (setting == "scsi" || setting == "virtio") && enabled("disk")
+	}
+	return false
+}
+
+func (c *qemuGuestClient) Plan(ctx context.Context, vmr VmRef, config ConfigQemu) (QemuUpdatePlan, error) {
+	return c.plan(ctx, vmr, config, true)
+}
+
+func (c *qemuGuestClient) PlanNoCheck(ctx context.Context, vmr VmRef, config ConfigQemu) (QemuUpdatePlan, error) {
+	return c.plan(ctx, vmr, config, false)
+}
+
+func (c *qemuGuestClient) plan(ctx context.Context, vmr VmRef, config ConfigQemu, validate bool) (QemuUpdatePlan, error) {
+	rawConfig, currentLegacy, version, err := c.current(ctx, &vmr, config, validate)
+	if err != nil {
+		return QemuUpdatePlan{}, err
+	}
+	status, err := vmr.getRawGuestStatus_Unsafe(ctx, c.oldClient)
+	if err != nil {
+		return QemuUpdatePlan{}, err
+	}
+	pending, err := c.api.getGuestPendingChanges(ctx, &vmr)
+	if err != nil {
+		return QemuUpdatePlan{}, err
+	}
+	return config.plan(currentLegacy, configQemuUpdate{raw: rawConfig}, version, status.GetState(), pending), nil
+}
+
+// current returns the current config of the guest, optionally validating the new config against it.
+func (c *qemuGuestClient) current(ctx context.Context, vmr *VmRef, config ConfigQemu, validate bool) (*rawConfigQemu, *ConfigQemu, Version, error) {
+	if err := config.setVmr(vmr); err != nil {
+		return nil, nil, Version{}, err
+	}
+	client := c.oldClient
+	rawConfig, err := guestGetQemuConfig(ctx, vmr, client)
+	if err != nil {
+		return nil, nil, Version{}, err
+	}
+	currentLegacy, err := rawConfig.Get(*vmr)
+	if err != nil {
+		return nil, nil, Version{}, err
+	}
+	version, err := client.Version(ctx)
+	if err != nil {
+		return nil, nil, Version{}, err
+	}
+	if validate {
+		if err = config.Validate(currentLegacy, version); err != nil {
+			return nil, nil, Version{}, err
+		}
+	}
+	return rawConfig, currentLegacy, version, nil
+}
@@ -0,0 +1,181 @@
+package proxmox
+
+import (
+	"testing"
+
+	"github.com/Telmate/proxmox-api-go/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ConfigQemu_plan(t *testing.T) {
+	t.Parallel()
+	current := func() map[string]any {
+		return map[string]any{
+			"cores":       float64(1),
+			"description": "test",
+			"memory":      "1024",
+			"name":        "test",
+			"scsi0":       "local-lvm:vm-100-disk-0,size=10G",
+			"tags":        "a;b"}
+	}
+	pending := func(key string, value any, change string) any {
+		m := map[string]any{pendingApiKeyKey: key, pendingApiValueKey: value}
+		if change != "" {
+			m[change] = change
+		}
+		return m
+	}
+	type testInput struct {
+		config  ConfigQemu
+		state   PowerState
+		pending []any
+	}
+	tests := []struct {
+		name   string
+		input  testInput
+		output QemuUpdatePlan
+	}{
+		{name: `No changes`,
+			input: testInput{
+				config: ConfigQemu{
+					CPU:  &QemuCPU{Cores: util.Pointer(QemuCpuCores(1))},
+					Name: util.Pointer(GuestName("test"))},
+				state:   PowerStateRunning,
+				pending: []any{pending("cores", float64(1), "")}},
+			output: QemuUpdatePlan{
+				Changes:          []GuestConfigChange{},
+				Deletes:          []string{},
+				DiskMoves:        []QemuPlanDiskMove{},
+				DiskResizes:      []QemuPlanDiskResize{},
+				PendingConflicts: []string{}}},
+		{name: `Hotplug running`,
+			input: testInput{
+				config: ConfigQemu{
+					Description: util.Pointer(""),
+					Name:        util.Pointer(GuestName("new")),
+					Tags:        util.Pointer(Tags{"a"})},
+				state: PowerStateRunning},
+			output: QemuUpdatePlan{
+				Changes: []GuestConfigChange{
+					{Key: "description", Old: "test", New: ""},
+					{Key: "name", Old: "test", New: "new"},
+					{Key: "tags", Old: "a;b", New: "a"}},
+				Deletes:          []string{},
+				DiskMoves:        []QemuPlanDiskMove{},
+				DiskResizes:      []QemuPlanDiskResize{},
+				PendingConflicts: []string{}}},
+		{name: `Reboot running`,
+			input: testInput{
+				config: ConfigQemu{
+					CPU: &QemuCPU{Cores: util.Pointer(QemuCpuCores(2))}},
+				state:   PowerStateRunning,
+				pending: []any{pending("cores", float64(1), "pending")}},
+			output: QemuUpdatePlan{
+				Changes:          []GuestConfigChange{{Key: "cores", Old: "1", New: "2"}},
+				Deletes:          []string{},
+				DiskMoves:        []QemuPlanDiskMove{},
+				DiskResizes:      []QemuPlanDiskResize{},
+				PendingConflicts: []string{"cores"},
+				RebootRequired:   true}},
+		{name: `Reboot stopped`,
+			input: testInput{
+				config: ConfigQemu{
+					CPU:    &QemuCPU{Cores: util.Pointer(QemuCpuCores(2))},
+					Memory: &QemuMemory{CapacityMiB: util.Pointer(QemuMemoryCapacity(2048))}},
+				state: PowerStateStopped},
+			output: QemuUpdatePlan{
+				Changes: []GuestConfigChange{
+					{Key: "cores", Old: "1", New: "2"},
+					{Key: "memory", Old: "1024", New: "2048"}},
+				Deletes:          []string{},
+				DiskMoves:        []QemuPlanDiskMove{},
+				DiskResizes:      []QemuPlanDiskResize{},
+				PendingConflicts: []string{}}},
+		{name: `Disks`,
+			input: testInput{
+				config: ConfigQemu{Disks: &QemuStorages{Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{Disk: &QemuScsiDisk{
+					Backup:          true,
+					Format:          QemuDiskFormat_Raw,
+					Replicate:       true,
+					SizeInKibibytes: 20 * gibibyte,
+					Storage:         "local-zfs"}}}}},
+				state: PowerStateRunning},
+			output: QemuUpdatePlan{
+				Changes:          []GuestConfigChange{{Key: "scsi0", Old: "local-lvm:vm-100-disk-0,size=10G", New: "local-zfs:vm-100-disk-0"}},
+				Deletes:          []string{},
+				DiskMoves:        []QemuPlanDiskMove{{ID: "scsi0", Storage: "local-zfs"}},
+				DiskResizes:      []QemuPlanDiskResize{{ID: "scsi0", SizeInKibibytes: 20 * gibibyte}},
+				PendingConflicts: []string{}}},
+		{name: `Migrate pool state`,
+			input: testInput{
+				config: ConfigQemu{
+					Node:  util.Pointer(NodeName("pve2")),
+					Pool:  util.Pointer(PoolName("pool")),
+					State: util.Pointer(PowerStateRunning)},
+				state: PowerStateStopped},
+			output: QemuUpdatePlan{
+				Changes:          []GuestConfigChange{},
+				Deletes:          []string{},
+				DiskMoves:        []QemuPlanDiskMove{},
+				DiskResizes:      []QemuPlanDiskResize{},
+				Migrate:          util.Pointer(NodeName("pve2")),
+				Pool:             util.Pointer(PoolName("pool")),
+				State:            util.Pointer(PowerStateRunning),
+				PendingConflicts: []string{}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			raw := &rawConfigQemu{a: current(), id: 100, node: "pve1"}
+			currentLegacy, err := raw.Get(VmRef{vmId: 100, node: "pve1"})
+			require.NoError(t, err)
+			plan := test.input.config.plan(currentLegacy, configQemuUpdate{raw: raw}, Version{Major: 8}, test.input.state, test.input.pending)
+			require.Equal(t, test.output, plan)
+			require.Equal(t, test.output.HasChanges(), plan.HasChanges())
+		})
+	}
+}
+
+func Test_qemuHotplug(t *testing.T) {
+	t.Parallel()
+	type testInput struct {
+		key     string
+		value   string
+		hotplug string
+	}
+	tests := []struct {
+		name   string
+		input  testInput
+		output bool
+	}{
+		{name: `Always`,
+			input:  testInput{key: "name", hotplug: "0"},
+			output: true},
+		{name: `Never`,
+			input: testInput{key: "cores", hotplug: "network,disk,usb,memory,cpu"}},
+		{name: `Default network`,
+			input:  testInput{key: "net0"},
+			output: true},
+		{name: `Default memory`,
+			input: testInput{key: "memory"}},
+		{name: `Memory`,
+			input:  testInput{key: "memory", hotplug: "memory"},
+			output: true},
+		{name: `Disabled network`,
+			input: testInput{key: "net1", hotplug: "0"}},
+		{name: `Disk scsi`,
+			input:  testInput{key: "scsi3", value: "local-lvm:32", hotplug: "1"},
+			output: true},
+		{name: `Disk ide`,
+			input: testInput{key: "ide1", value: "local-lvm:32"}},
+		{name: `CD-ROM`,
+			input:  testInput{key: "ide2", value: "none,media=cdrom", hotplug: "0"},
+			output: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.output, qemuHotplug(test.input.key, test.input.value, test.input.hotplug))
+		})
+	}
+}
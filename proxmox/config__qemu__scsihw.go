@@ -0,0 +1,58 @@
+package proxmox
+
+import (
+	"errors"
+	"strings"
+)
+
+// Enum
+//
+//	const (
+//		QemuScsiControllerLsi
+//		QemuScsiControllerLsi53c810
+//		QemuScsiControllerMegasas
+//		QemuScsiControllerPvscsi
+//		QemuScsiControllerVirtio
+//		QemuScsiControllerVirtioSingle
+//	)
+type QemuScsiController string
+
+const (
+	QemuScsiControllerLsi          QemuScsiController = "lsi"
+	QemuScsiControllerLsi53c810    QemuScsiController = "lsi53c810"
+	QemuScsiControllerMegasas      QemuScsiController = "megasas"
+	QemuScsiControllerPvscsi       QemuScsiController = "pvscsi"
+	QemuScsiControllerVirtio       QemuScsiController = "virtio-scsi-pci"
+	QemuScsiControllerVirtioSingle QemuScsiController = "virtio-scsi-single"
+)
+
+const QemuScsiController_Error = "invalid scsi controller"
+
+func (controller QemuScsiController) mapToApiCreate(builder *strings.Builder) {
+	builder.WriteString("&" + qemuApiKeyScsiController + "=")
+	builder.WriteString(controller.String())
+}
+
+func (controller QemuScsiController) mapToApiUpdate(current QemuScsiController, builder *strings.Builder) {
+	if controller != current {
+		controller.mapToApiCreate(builder)
+	}
+}
+
+func (controller QemuScsiController) String() string { return string(controller) } // String is for fmt.Stringer.
+
+func (controller QemuScsiController) Validate() error {
+	switch controller {
+	case QemuScsiControllerLsi, QemuScsiControllerLsi53c810, QemuScsiControllerMegasas, QemuScsiControllerPvscsi,
+		QemuScsiControllerVirtio, QemuScsiControllerVirtioSingle:
+		return nil
+	}
+	return errors.New(QemuScsiController_Error)
+}
+
+func (raw *rawConfigQemu) GetScsiController() QemuScsiController {
+	if v, isSet := raw.a[qemuApiKeyScsiController]; isSet {
+		return QemuScsiController(v.(string))
+	}
+	return QemuScsiControllerLsi
+}
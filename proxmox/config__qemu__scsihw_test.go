@@ -0,0 +1,67 @@
+package proxmox
+
+import (
+	"errors"
+	"testing"
+)
+
+func testData_ConfigQemu_ScsiController_Api() qemuTestsApiFunc {
+	return qemuTestsApiFunc(func() qemuTestsAPI {
+		return qemuTestsAPI{
+			createUpdate: []qemuTestCaseAPI{
+				{name: `set`,
+					config: &ConfigQemu{ScsiController: new(QemuScsiControllerVirtioSingle)},
+					body:   map[string]string{"scsihw": "virtio-scsi-single"}}},
+			update: []qemuTestCaseAPI{
+				{name: `change`,
+					config:        &ConfigQemu{ScsiController: new(QemuScsiControllerPvscsi)},
+					currentLegacy: ConfigQemu{ScsiController: new(QemuScsiControllerLsi)},
+					body:          map[string]string{"scsihw": "pvscsi"}},
+				{name: `same no effect`,
+					config:        &ConfigQemu{ScsiController: new(QemuScsiControllerMegasas)},
+					currentLegacy: ConfigQemu{ScsiController: new(QemuScsiControllerMegasas)}}}}
+	})
+}
+
+func testData_ConfigQemu_ScsiController_Validate() qemuTestTypeValidateFunc {
+	return qemuTestTypeValidateFunc(func() (qemuTestTypeInvalid, qemuTestTypeValid) {
+		invalid := qemuTestTypeInvalid{
+			createUpdate: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuScsiController_Error)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{ScsiController: new(QemuScsiController("invalid"))}),
+					err:   errors.New(QemuScsiController_Error)}}}
+		valid := qemuTestTypeValid{
+			createUpdate: []qemuTestCaseValid{
+				{name: `lsi`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{ScsiController: new(QemuScsiControllerLsi)})},
+				{name: `lsi53c810`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{ScsiController: new(QemuScsiControllerLsi53c810)})},
+				{name: `megasas`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{ScsiController: new(QemuScsiControllerMegasas)})},
+				{name: `pvscsi`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{ScsiController: new(QemuScsiControllerPvscsi)})},
+				{name: `virtio-scsi-pci`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{ScsiController: new(QemuScsiControllerVirtio)})},
+				{name: `virtio-scsi-single`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{ScsiController: new(QemuScsiControllerVirtioSingle)})}}}
+		return invalid, valid
+	})
+}
+
+func Test_ConfigQemu_ScsiController_Api(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_ScsiController_Api().Test(t)
+}
+
+func Test_ConfigQemu_ScsiController_Validate(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_ScsiController_Validate().Test(t)
+}
+
+func testData_ConfigQemu_ScsiController_Get() []qemuTestCaseGet {
+	return []qemuTestCaseGet{
+		{name: `set`,
+			input:  map[string]any{"scsihw": string("virtio-scsi-pci")},
+			output: testQemuBaseConfig_get(ConfigQemu{ScsiController: new(QemuScsiControllerVirtio)})},
+	}
+}
@@ -0,0 +1,193 @@
+package proxmox
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Telmate/proxmox-api-go/internal/body"
+)
+
+// QemuSmbios is the SMBIOS type 1 (system information) of the guest.
+// The text fields are base64 encoded when sent to the API, so they may contain any character.
+type QemuSmbios struct {
+	Family       *string         `json:"family,omitempty"`
+	Manufacturer *string         `json:"manufacturer,omitempty"`
+	Product      *string         `json:"product,omitempty"`
+	Serial       *string         `json:"serial,omitempty"`
+	SKU          *string         `json:"sku,omitempty"`
+	UUID         *QemuSmbiosUUID `json:"uuid,omitempty"` // A random UUID is generated during create when nil
+	Version      *string         `json:"version,omitempty"`
+}
+
+const QemuSmbios_Error_MaxLength = "the encoded smbios settings may not exceed 512 characters"
+
+const (
+	qemuApiSettingSmbiosBase64       = "base64"
+	qemuApiSettingSmbiosFamily       = "family"
+	qemuApiSettingSmbiosManufacturer = "manufacturer"
+	qemuApiSettingSmbiosProduct      = "product"
+	qemuApiSettingSmbiosSerial       = "serial"
+	qemuApiSettingSmbiosSKU          = "sku"
+	qemuApiSettingSmbiosUUID         = "uuid"
+	qemuApiSettingSmbiosVersion      = "version"
+	qemuSmbiosMaxLength              = 512
+)
+
+func (config QemuSmbios) combine(current QemuSmbios) QemuSmbios {
+	if config.Family != nil {
+		current.Family = config.Family
+	}
+	if config.Manufacturer != nil {
+		current.Manufacturer = config.Manufacturer
+	}
+	if config.Product != nil {
+		current.Product = config.Product
+	}
+	if config.Serial != nil {
+		current.Serial = config.Serial
+	}
+	if config.SKU != nil {
+		current.SKU = config.SKU
+	}
+	if config.UUID != nil {
+		current.UUID = config.UUID
+	}
+	if config.Version != nil {
+		current.Version = config.Version
+	}
+	return current
+}
+
+// mapToApi returns the unescaped setting as Proxmox VE stores it.
+func (config QemuSmbios) mapToApi() string {
+	settings := make([]string, 0, 8)
+	if config.UUID != nil && *config.UUID != "" {
+		settings = append(settings, qemuApiSettingSmbiosUUID+"="+config.UUID.String())
+	}
+	var encoded bool
+	add := func(key string, value *string) {
+		if value != nil && *value != "" {
+			settings = append(settings, key+"="+base64.StdEncoding.EncodeToString([]byte(*value)))
+			encoded = true
+		}
+	}
+	add(qemuApiSettingSmbiosManufacturer, config.Manufacturer)
+	add(qemuApiSettingSmbiosProduct, config.Product)
+	add(qemuApiSettingSmbiosVersion, config.Version)
+	add(qemuApiSettingSmbiosSerial, config.Serial)
+	add(qemuApiSettingSmbiosSKU, config.SKU)
+	add(qemuApiSettingSmbiosFamily, config.Family)
+	if encoded {
+		settings = append(settings, qemuApiSettingSmbiosBase64+"=1")
+	}
+	return strings.Join(settings, ",")
+}
+
+func (config QemuSmbios) mapToApiCreate(builder *strings.Builder) {
+	if config.UUID == nil {
+		config.UUID = new(newQemuSmbiosUUID())
+	}
+	if v := config.mapToApi(); v != "" {
+		builder.WriteString("&" + qemuApiKeySmbios + "=")
+		builder.WriteString(body.Escape(v))
+	}
+}
+
+func (config QemuSmbios) mapToApiUpdate(current QemuSmbios, builder, delete *strings.Builder) {
+	currentStr := current.mapToApi()
+	configStr := config.combine(current).mapToApi()
+	if currentStr == configStr {
+		return
+	}
+	if configStr == "" {
+		delete.WriteString("," + qemuApiKeySmbios)
+		return
+	}
+	builder.WriteString("&" + qemuApiKeySmbios + "=")
+	builder.WriteString(body.Escape(configStr))
+}
+
+func (QemuSmbios) mapToSDK(raw string) *QemuSmbios {
+	settings := splitStringOfSettings(raw)
+	_, encoded := settings[qemuApiSettingSmbiosBase64]
+	decode := func(key string) *string {
+		v, isSet := settings[key]
+		if !isSet {
+			return nil
+		}
+		if encoded {
+			if tmp, err := base64.StdEncoding.DecodeString(v); err == nil {
+				return new(string(tmp))
+			}
+		}
+		return &v
+	}
+	config := QemuSmbios{
+		Family:       decode(qemuApiSettingSmbiosFamily),
+		Manufacturer: decode(qemuApiSettingSmbiosManufacturer),
+		Product:      decode(qemuApiSettingSmbiosProduct),
+		Serial:       decode(qemuApiSettingSmbiosSerial),
+		SKU:          decode(qemuApiSettingSmbiosSKU),
+		Version:      decode(qemuApiSettingSmbiosVersion)}
+	if v, isSet := settings[qemuApiSettingSmbiosUUID]; isSet {
+		config.UUID = new(QemuSmbiosUUID(v))
+	}
+	return &config
+}
+
+// Validate checks the smbios settings the guest will have after the config is applied on top of current.
+// current is nil when the guest is created.
+func (config QemuSmbios) Validate(current *QemuSmbios) error {
+	if config.UUID != nil {
+		if err := config.UUID.Validate(); err != nil {
+			return err
+		}
+	}
+	smbios := config
+	if current != nil {
+		smbios = config.combine(*current)
+	} else if smbios.UUID == nil { // account for the uuid generated during create
+		smbios.UUID = new(QemuSmbiosUUID("00000000-0000-0000-0000-000000000000"))
+	}
+	if len(smbios.mapToApi()) > qemuSmbiosMaxLength {
+		return errors.New(QemuSmbios_Error_MaxLength)
+	}
+	return nil
+}
+
+func (raw *rawConfigQemu) GetSmbios() *QemuSmbios {
+	if v, isSet := raw.a[qemuApiKeySmbios]; isSet {
+		return QemuSmbios{}.mapToSDK(v.(string))
+	}
+	return nil
+}
+
+// QemuSmbiosUUID is the UUID of the guest, e.g. `3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b`.
+// An empty UUID removes it from the guest.
+type QemuSmbiosUUID string
+
+const QemuSmbiosUUID_Error_Invalid = "invalid smbios uuid"
+
+var regexQemuSmbiosUUID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// newQemuSmbiosUUID returns a random (version 4) UUID.
+func newQemuSmbiosUUID() QemuSmbiosUUID {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return QemuSmbiosUUID(fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]))
+}
+
+func (uuid QemuSmbiosUUID) String() string { return string(uuid) } // String is for fmt.Stringer.
+
+func (uuid QemuSmbiosUUID) Validate() error {
+	if uuid == "" || regexQemuSmbiosUUID.MatchString(string(uuid)) {
+		return nil
+	}
+	return errors.New(QemuSmbiosUUID_Error_Invalid)
+}
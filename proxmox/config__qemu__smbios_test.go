@@ -0,0 +1,163 @@
+package proxmox
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testData_ConfigQemu_Smbios_Api() qemuTestsApiFunc {
+	const uuid = QemuSmbiosUUID("3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b")
+	return qemuTestsApiFunc(func() qemuTestsAPI {
+		return qemuTestsAPI{
+			create: []qemuTestCaseAPI{
+				{name: `full`,
+					config: &ConfigQemu{Smbios: &QemuSmbios{
+						Family:       new("Virtual"),
+						Manufacturer: new("Acme Inc."),
+						Product:      new("Appliance"),
+						Serial:       new("SN-0001"),
+						SKU:          new("A/B+C"),
+						UUID:         new(uuid),
+						Version:      new("2.1")}},
+					// "uuid=3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b,manufacturer=QWNtZSBJbmMu,product=QXBwbGlhbmNl,version=Mi4x,serial=U04tMDAwMQ==,sku=QS9CK0M=,family=VmlydHVhbA==,base64=1"
+					body: map[string]string{"smbios1": "uuid%3D3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b%2Cmanufacturer%3DQWNtZSBJbmMu%2Cproduct%3DQXBwbGlhbmNl%2Cversion%3DMi4x%2Cserial%3DU04tMDAwMQ%3D%3D%2Csku%3DQS9CK0M%3D%2Cfamily%3DVmlydHVhbA%3D%3D%2Cbase64%3D1"}},
+				{name: `only UUID`,
+					config: &ConfigQemu{Smbios: &QemuSmbios{UUID: new(uuid)}},
+					body:   map[string]string{"smbios1": "uuid%3D3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b"}}, // "uuid=3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b"
+				{name: `UUID empty`,
+					config: &ConfigQemu{Smbios: &QemuSmbios{
+						Serial: new("SN-0002"),
+						UUID:   new(QemuSmbiosUUID(""))}},
+					body: map[string]string{"smbios1": "serial%3DU04tMDAwMg%3D%3D%2Cbase64%3D1"}}}, // "serial=U04tMDAwMg==,base64=1"
+			update: []qemuTestCaseAPI{
+				{name: `add field`,
+					config:        &ConfigQemu{Smbios: &QemuSmbios{Serial: new("SN-0002")}},
+					currentLegacy: ConfigQemu{Smbios: &QemuSmbios{UUID: new(uuid)}},
+					body:          map[string]string{"smbios1": "uuid%3D3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b%2Cserial%3DU04tMDAwMg%3D%3D%2Cbase64%3D1"}}, // "uuid=3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b,serial=U04tMDAwMg==,base64=1"
+				{name: `remove field`,
+					config: &ConfigQemu{Smbios: &QemuSmbios{Serial: new("")}},
+					currentLegacy: ConfigQemu{Smbios: &QemuSmbios{
+						Product: new("Appliance"),
+						Serial:  new("SN-0001"),
+						UUID:    new(uuid)}},
+					body: map[string]string{"smbios1": "uuid%3D3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b%2Cproduct%3DQXBwbGlhbmNl%2Cbase64%3D1"}}, // "uuid=3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b,product=QXBwbGlhbmNl,base64=1"
+				{name: `delete`,
+					config: &ConfigQemu{Smbios: &QemuSmbios{
+						Serial: new(""),
+						UUID:   new(QemuSmbiosUUID(""))}},
+					currentLegacy: ConfigQemu{Smbios: &QemuSmbios{
+						Serial: new("SN-0001"),
+						UUID:   new(uuid)}},
+					body: map[string]string{"delete": "smbios1"}},
+				{name: `same no effect`,
+					config: &ConfigQemu{Smbios: &QemuSmbios{
+						Manufacturer: new("Acme Inc."),
+						UUID:         new(uuid)}},
+					currentLegacy: ConfigQemu{Smbios: &QemuSmbios{
+						Manufacturer: new("Acme Inc."),
+						UUID:         new(uuid)}}}}}
+	})
+}
+
+func testData_ConfigQemu_Smbios_Validate() qemuTestTypeValidateFunc {
+	return qemuTestTypeValidateFunc(func() (qemuTestTypeInvalid, qemuTestTypeValid) {
+		invalid := qemuTestTypeInvalid{
+			createUpdate: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuSmbiosUUID_Error_Invalid)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Smbios: &QemuSmbios{
+						UUID: new(QemuSmbiosUUID("3f2b8c1e-9a4d-4e6f-8b7a"))}}),
+					err: errors.New(QemuSmbiosUUID_Error_Invalid)},
+				{name: `errors.New(QemuSmbios_Error_MaxLength)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Smbios: &QemuSmbios{
+						Product: new(strings.Repeat("a", 384))}}),
+					err: errors.New(QemuSmbios_Error_MaxLength)}},
+			create: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuSmbios_Error_MaxLength) generated UUID`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Smbios: &QemuSmbios{
+						Product: new(strings.Repeat("a", 340))}}),
+					err: errors.New(QemuSmbios_Error_MaxLength)}},
+			update: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuSmbios_Error_MaxLength) current`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Smbios: &QemuSmbios{
+						Product: new(strings.Repeat("a", 200))}}),
+					current: &ConfigQemu{Smbios: &QemuSmbios{
+						Manufacturer: new(strings.Repeat("b", 200))}},
+					err: errors.New(QemuSmbios_Error_MaxLength)}}}
+		valid := qemuTestTypeValid{
+			createUpdate: []qemuTestCaseValid{
+				{name: `empty`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Smbios: &QemuSmbios{}})},
+				{name: `full`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Smbios: &QemuSmbios{
+						Family:       new("Virtual"),
+						Manufacturer: new("Acme, Inc."),
+						Product:      new("Appliance=1"),
+						Serial:       new("SN-0001"),
+						SKU:          new("A/B+C"),
+						UUID:         new(QemuSmbiosUUID("3F2B8C1E-9A4D-4E6F-8B7A-1C2D3E4F5A6B")),
+						Version:      new("2.1")}})},
+				{name: `UUID empty`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Smbios: &QemuSmbios{
+						UUID: new(QemuSmbiosUUID(""))}})}},
+			update: []qemuTestCaseValid{
+				{name: `UUID unchanged`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Smbios: &QemuSmbios{
+						Product: new(strings.Repeat("a", 340))}}),
+					current: &ConfigQemu{Smbios: &QemuSmbios{}}}}}
+		return invalid, valid
+	})
+}
+
+func Test_ConfigQemu_Smbios_Api(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_Smbios_Api().Test(t)
+}
+
+func Test_ConfigQemu_Smbios_Validate(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_Smbios_Validate().Test(t)
+}
+
+func Test_QemuSmbios_mapToApiCreate(t *testing.T) {
+	t.Parallel()
+	t.Run(`UUID generated`, func(t *testing.T) {
+		t.Parallel()
+		var builder strings.Builder
+		QemuSmbios{Serial: new("SN-0001")}.mapToApiCreate(&builder)
+		raw, err := url.QueryUnescape(strings.TrimPrefix(builder.String(), "&smbios1="))
+		require.NoError(t, err)
+		smbios := QemuSmbios{}.mapToSDK(raw)
+		require.NotNil(t, smbios.UUID)
+		require.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, smbios.UUID.String())
+		require.Equal(t, "SN-0001", *smbios.Serial)
+	})
+	t.Run(`UUID unique`, func(t *testing.T) {
+		t.Parallel()
+		require.NotEqual(t, newQemuSmbiosUUID(), newQemuSmbiosUUID())
+	})
+}
+
+func testData_ConfigQemu_Smbios_Get() []qemuTestCaseGet {
+	return []qemuTestCaseGet{
+		{name: `base64`,
+			input: map[string]any{"smbios1": string("uuid=3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b,manufacturer=QWNtZSBJbmMu,sku=QS9CK0M=,base64=1")},
+			output: testQemuBaseConfig_get(ConfigQemu{Smbios: &QemuSmbios{
+				Manufacturer: new("Acme Inc."),
+				SKU:          new("A/B+C"),
+				UUID:         new(QemuSmbiosUUID("3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b"))}})},
+		{name: `plain`,
+			input: map[string]any{"smbios1": string("uuid=3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b,family=Virtual,serial=SN-0001")},
+			output: testQemuBaseConfig_get(ConfigQemu{Smbios: &QemuSmbios{
+				Family: new("Virtual"),
+				Serial: new("SN-0001"),
+				UUID:   new(QemuSmbiosUUID("3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b"))}})},
+		{name: `UUID only`,
+			input: map[string]any{"smbios1": string("uuid=3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b")},
+			output: testQemuBaseConfig_get(ConfigQemu{Smbios: &QemuSmbios{
+				UUID: new(QemuSmbiosUUID("3f2b8c1e-9a4d-4e6f-8b7a-1c2d3e4f5a6b"))}})},
+	}
+}
@@ -0,0 +1,187 @@
+package proxmox
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// QemuUnusedDisks are the volumes that belong to the guest but are not attached to it.
+// Proxmox VE keeps a volume as unused after it has been detached from the guest.
+type QemuUnusedDisks map[QemuUnusedDiskID]QemuUnusedDisk
+
+const (
+	QemuUnusedDisks_Error_NotFound  string = "unused disk does not exist"
+	QemuUnusedDisks_Error_SlotInUse string = "the disk id to reattach the unused disk to is already in use"
+)
+
+func (raw *rawConfigQemu) disksUnused() QemuUnusedDisks {
+	disks := make(QemuUnusedDisks)
+	for key, v := range raw.a {
+		number, isPrefix := strings.CutPrefix(key, qemuPrefixApiKeyUnused)
+		if !isPrefix {
+			continue
+		}
+		id, err := strconv.ParseUint(number, 10, 8)
+		if err != nil {
+			continue
+		}
+		disks[QemuUnusedDiskID(id)] = QemuUnusedDisk{}.mapToSDK(v.(string))
+	}
+	if len(disks) > 0 {
+		return disks
+	}
+	return nil
+}
+
+// Unused disks can only be reattached or deleted, Validate rejects entries that do not exist in current.
+func (disks QemuUnusedDisks) mapToApiValues(current QemuUnusedDisks, params map[string]any, delete *strings.Builder) {
+	for id, disk := range disks {
+		currentDisk, isSet := current[id]
+		if !isSet {
+			continue
+		}
+		if disk.Delete { // deleting the unused entry permanently removes the volume
+			delete.WriteRune(',')
+			delete.WriteString(qemuPrefixApiKeyUnused + id.String())
+			continue
+		}
+		if disk.Reattach != nil { // Proxmox VE removes the unused entry when the volume is attached
+			params[disk.Reattach.ID.String()] = disk.Reattach.mapToApi(currentDisk)
+		}
+	}
+}
+
+// Validate checks the unused disks against the disks the guest will have after the update.
+// storages are the disks of the new config, current is nil when the guest is created.
+func (disks QemuUnusedDisks) Validate(storages, current *QemuStorages) error {
+	var currentUnused QemuUnusedDisks
+	if current != nil {
+		currentUnused = current.Unused
+	}
+	targets := make(map[QemuDiskId]struct{})
+	for id, disk := range disks {
+		if err := disk.Validate(); err != nil {
+			return err
+		}
+		if disk.Reattach == nil && !disk.Delete {
+			continue
+		}
+		if _, isSet := currentUnused[id]; !isSet {
+			return errors.New(QemuUnusedDisks_Error_NotFound)
+		}
+		if disk.Reattach == nil {
+			continue
+		}
+		if _, isSet := targets[disk.Reattach.ID]; isSet {
+			return errors.New(QemuUnusedDisks_Error_SlotInUse)
+		}
+		targets[disk.Reattach.ID] = struct{}{}
+		if storages.storage(disk.Reattach.ID) != nil || current.storage(disk.Reattach.ID) != nil {
+			return errors.New(QemuUnusedDisks_Error_SlotInUse)
+		}
+	}
+	return nil
+}
+
+// QemuUnusedDiskID is the number of the unused disk, in the range 0-255.
+type QemuUnusedDiskID uint8
+
+func (id QemuUnusedDiskID) String() string { return strconv.Itoa(int(id)) } // String is for fmt.Stringer.
+
+type QemuUnusedDisk struct {
+	Format          QemuDiskFormat          `json:"format"`             // Only returned, derived from the volume
+	SizeInKibibytes QemuDiskSize            `json:"size,omitempty"`     // Only returned, zero when Proxmox VE did not store the size
+	Storage         string                  `json:"storage"`            // Only returned
+	Volume          string                  `json:"volume"`             // Only returned, the volume path on the storage
+	Reattach        *QemuUnusedDiskReattach `json:"reattach,omitempty"` // Attaches the volume to the guest again
+	Delete          bool                    `json:"delete,omitempty"`   // Permanently deletes the volume
+}
+
+const QemuUnusedDisk_Error_MutuallyExclusive string = "reattach and delete of an unused disk are mutually exclusive"
+
+// mapToSDK parses the value of an unused disk, e.g. `local:100/vm-100-disk-1.qcow2`.
+func (QemuUnusedDisk) mapToSDK(raw string) QemuUnusedDisk {
+	var disk QemuUnusedDisk
+	volume, options, _ := strings.Cut(raw, ",")
+	disk.Storage, disk.Volume, _ = strings.Cut(volume, ":")
+	disk.Format.parse(disk.Volume)
+	if v, isSet := splitStringOfSettings(options)["size"]; isSet && v != "" {
+		disk.SizeInKibibytes = QemuDiskSize(0).parse(v)
+	}
+	return disk
+}
+
+func (disk QemuUnusedDisk) Validate() error {
+	if disk.Reattach == nil {
+		return nil
+	}
+	if disk.Delete {
+		return errors.New(QemuUnusedDisk_Error_MutuallyExclusive)
+	}
+	return disk.Reattach.Validate()
+}
+
+// QemuUnusedDiskReattach attaches the unused volume to the guest as the disk with the ID.
+type QemuUnusedDiskReattach struct {
+	ID            QemuDiskId        `json:"id"`
+	AsyncIO       QemuDiskAsyncIO   `json:"asyncio,omitempty"`
+	Bandwidth     QemuDiskBandwidth `json:"bandwidth,omitempty"`
+	Cache         QemuDiskCache     `json:"cache,omitempty"`
+	Serial        QemuDiskSerial    `json:"serial,omitempty"`
+	WorldWideName QemuWorldWideName `json:"wwn,omitempty"`
+	Backup        bool              `json:"backup"`
+	Discard       bool              `json:"discard"`
+	EmulateSSD    bool              `json:"emulatessd"` // Only for ide,sata,scsi
+	IOThread      bool              `json:"iothread"`   // Only for scsi,virtio
+	ReadOnly      bool              `json:"readonly"`   // Only for scsi,virtio
+	Replicate     bool              `json:"replicate"`
+}
+
+func (reattach QemuUnusedDiskReattach) mapToApi(unused QemuUnusedDisk) string {
+	disk := qemuDisk{
+		AsyncIO:       reattach.AsyncIO,
+		Backup:        reattach.Backup,
+		Bandwidth:     reattach.Bandwidth,
+		Cache:         reattach.Cache,
+		Discard:       reattach.Discard,
+		Disk:          true,
+		EmulateSSD:    reattach.EmulateSSD,
+		IOThread:      reattach.IOThread,
+		ReadOnly:      reattach.ReadOnly,
+		Replicate:     reattach.Replicate,
+		Serial:        reattach.Serial,
+		Storage:       unused.Storage,
+		VolumePath:    unused.Volume,
+		WorldWideName: reattach.WorldWideName}
+	switch id := reattach.ID.String(); {
+	case strings.HasPrefix(id, qemuPrefixApiKeyDiskSata):
+		disk.Type = sata
+	case strings.HasPrefix(id, qemuPrefixApiKeyDiskSCSI):
+		disk.Type = scsi
+	case strings.HasPrefix(id, qemuPrefixApiKeyDiskVirtIO):
+		disk.Type = virtIO
+	default:
+		disk.Type = ide
+	}
+	return disk.mapToApiValues(false)
+}
+
+func (reattach QemuUnusedDiskReattach) Validate() error {
+	if err := reattach.ID.Validate(); err != nil {
+		return err
+	}
+	if err := reattach.AsyncIO.Validate(); err != nil {
+		return err
+	}
+	if err := reattach.Bandwidth.Validate(); err != nil {
+		return err
+	}
+	if err := reattach.Cache.Validate(); err != nil {
+		return err
+	}
+	if err := reattach.Serial.Validate(); err != nil {
+		return err
+	}
+	return reattach.WorldWideName.Validate()
+}
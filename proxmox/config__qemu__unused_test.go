@@ -0,0 +1,160 @@
+package proxmox
+
+import (
+	"errors"
+	"testing"
+)
+
+func testData_ConfigQemu_Unused_Api() qemuTestsApiFunc {
+	return qemuTestsApiFunc(func() qemuTestsAPI {
+		current := func() configQemuUpdate {
+			return configQemuUpdate{disks: &QemuStorages{Unused: QemuUnusedDisks{
+				0: QemuUnusedDisk{Format: QemuDiskFormat_Raw, Storage: "local-lvm", Volume: "vm-100-disk-1"},
+				7: QemuUnusedDisk{Format: QemuDiskFormat_Qcow2, Storage: "local", Volume: "100/vm-100-disk-2.qcow2"}}}}
+		}
+		return qemuTestsAPI{
+			create: []qemuTestCaseAPI{
+				{name: `ignored`,
+					config: &ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Delete: true},
+						1: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "scsi0"}}}}}}},
+			update: []qemuTestCaseAPI{
+				{name: `delete`,
+					config:        &ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{7: QemuUnusedDisk{Delete: true}}}},
+					currentUpdate: current(),
+					body:          map[string]string{"delete": "unused7"}},
+				{name: `delete not existing`,
+					config:        &ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{3: QemuUnusedDisk{Delete: true}}}},
+					currentUpdate: current()},
+				{name: `reattach`,
+					config: &ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{
+							ID:        "virtio3",
+							Backup:    true,
+							Discard:   true,
+							IOThread:  true,
+							Replicate: true,
+							Serial:    "abc"}}}}},
+					currentUpdate: current(),
+					output:        map[string]any{"virtio3": "local-lvm:vm-100-disk-1,discard=on,iothread=1,serial=abc"}},
+				{name: `reattach defaults`,
+					config: &ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						7: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "sata2", EmulateSSD: true}}}}},
+					currentUpdate: current(),
+					output:        map[string]any{"sata2": "local:100/vm-100-disk-2.qcow2,backup=0,replicate=0,ssd=1"}},
+				{name: `nothing`,
+					config:        &ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{0: QemuUnusedDisk{}}}},
+					currentUpdate: current()}}}
+	})
+}
+
+func testData_ConfigQemu_Unused_Validate() qemuTestTypeValidateFunc {
+	return qemuTestTypeValidateFunc(func() (qemuTestTypeInvalid, qemuTestTypeValid) {
+		current := func() *ConfigQemu {
+			return &ConfigQemu{Disks: &QemuStorages{
+				Scsi: &QemuScsiDisks{Disk_0: &QemuScsiStorage{Disk: &QemuScsiDisk{
+					Format:          QemuDiskFormat_Raw,
+					SizeInKibibytes: gibibyte,
+					Storage:         "local-lvm"}}},
+				Unused: QemuUnusedDisks{
+					0: QemuUnusedDisk{Format: QemuDiskFormat_Raw, Storage: "local-lvm", Volume: "vm-100-disk-1"},
+					1: QemuUnusedDisk{Format: QemuDiskFormat_Raw, Storage: "local-lvm", Volume: "vm-100-disk-2"}}}}
+		}
+		invalid := qemuTestTypeInvalid{
+			createUpdate: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuUnusedDisk_Error_MutuallyExclusive)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Delete: true, Reattach: &QemuUnusedDiskReattach{ID: "scsi1"}}}}}),
+					current: current(),
+					err:     errors.New(QemuUnusedDisk_Error_MutuallyExclusive)},
+				{name: `errors.New(ERROR_QemuDiskId_Invalid)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "scsi31"}}}}}),
+					current: current(),
+					err:     errors.New(ERROR_QemuDiskId_Invalid)},
+				{name: `QemuDiskCache("").Error()`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "scsi1", Cache: "invalid"}}}}}),
+					current: current(),
+					err:     QemuDiskCache("").Error()}},
+			create: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuUnusedDisks_Error_NotFound)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "scsi1"}}}}}),
+					err: errors.New(QemuUnusedDisks_Error_NotFound)},
+				{name: `errors.New(QemuUnusedDisks_Error_NotFound) delete`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Delete: true}}}}),
+					err: errors.New(QemuUnusedDisks_Error_NotFound)}},
+			update: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuUnusedDisks_Error_NotFound)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						5: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "scsi1"}}}}}),
+					current: current(),
+					err:     errors.New(QemuUnusedDisks_Error_NotFound)},
+				{name: `errors.New(QemuUnusedDisks_Error_NotFound) delete`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Delete: true},
+						5: QemuUnusedDisk{Delete: true}}}}),
+					current: current(),
+					err:     errors.New(QemuUnusedDisks_Error_NotFound)},
+				{name: `errors.New(QemuUnusedDisks_Error_SlotInUse) current`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "scsi0"}}}}}),
+					current: current(),
+					err:     errors.New(QemuUnusedDisks_Error_SlotInUse)},
+				{name: `errors.New(QemuUnusedDisks_Error_SlotInUse) config`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{
+						Sata: &QemuSataDisks{Disk_1: &QemuSataStorage{CdRom: &QemuCdRom{}}},
+						Unused: QemuUnusedDisks{
+							0: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "sata1"}}}}}),
+					current: current(),
+					err:     errors.New(QemuUnusedDisks_Error_SlotInUse)},
+				{name: `errors.New(QemuUnusedDisks_Error_SlotInUse) duplicate`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "virtio0"}},
+						1: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "virtio0"}}}}}),
+					current: current(),
+					err:     errors.New(QemuUnusedDisks_Error_SlotInUse)}}}
+		valid := qemuTestTypeValid{
+			update: []qemuTestCaseValid{
+				{name: `delete`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Delete: true},
+						1: QemuUnusedDisk{Delete: true}}}}),
+					current: current()},
+				{name: `reattach`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+						0: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "scsi1", Cache: QemuDiskCache_WriteBack}},
+						1: QemuUnusedDisk{Reattach: &QemuUnusedDiskReattach{ID: "ide0"}}}}}),
+					current: current()}}}
+		return invalid, valid
+	})
+}
+
+func Test_ConfigQemu_Unused_Api(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_Unused_Api().Test(t)
+}
+
+func Test_ConfigQemu_Unused_Validate(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_Unused_Validate().Test(t)
+}
+
+func testData_ConfigQemu_Unused_Get() []qemuTestCaseGet {
+	return []qemuTestCaseGet{
+		{name: `all`,
+			input: map[string]any{
+				"unused0":   string("local-lvm:vm-100-disk-1"),
+				"unused12":  string("local:100/vm-100-disk-2.qcow2"),
+				"unused255": string("nfs:100/vm-100-disk-3.vmdk,size=2G")},
+			output: testQemuBaseConfig_get(ConfigQemu{Disks: &QemuStorages{Unused: QemuUnusedDisks{
+				0:   QemuUnusedDisk{Format: QemuDiskFormat_Raw, Storage: "local-lvm", Volume: "vm-100-disk-1"},
+				12:  QemuUnusedDisk{Format: QemuDiskFormat_Qcow2, Storage: "local", Volume: "100/vm-100-disk-2.qcow2"},
+				255: QemuUnusedDisk{Format: QemuDiskFormat_Vmdk, SizeInKibibytes: 2 * gibibyte, Storage: "nfs", Volume: "100/vm-100-disk-3.vmdk"}}}})},
+		{name: `invalid id ignored`,
+			input:  map[string]any{"unused256": string("local-lvm:vm-100-disk-1")},
+			output: testQemuBaseConfig_get(ConfigQemu{})},
+	}
+}
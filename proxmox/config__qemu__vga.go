@@ -0,0 +1,262 @@
+package proxmox
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+type QemuVGA struct {
+	Clipboard *QemuVgaClipboard `json:"clipboard,omitempty"` // Requires Proxmox VE 8.1 or later
+	Memory    *QemuVgaMemory    `json:"memory,omitempty"`    // 0 resets to the default of the vga type
+	Type      *QemuVgaType      `json:"type,omitempty"`      // Never nil when returned
+	Delete    bool
+}
+
+const (
+	QemuVGA_Error_ClipboardNotSupported = "vga clipboard is not supported by the vga type"
+	QemuVGA_Error_MemoryNotSupported    = "vga memory is not supported by the vga type"
+)
+
+func (config QemuVGA) mapToApi(version Version) string {
+	settings := make([]string, 0, 3)
+	if config.Type != nil {
+		settings = append(settings, "type"+equal+config.Type.String())
+	}
+	if config.Memory != nil && *config.Memory != 0 {
+		settings = append(settings, "memory"+equal+config.Memory.String())
+	}
+	if config.Clipboard != nil && *config.Clipboard != "" && CapabilityVgaClipboard.SupportedBy(version) {
+		settings = append(settings, "clipboard"+equal+config.Clipboard.String())
+	}
+	return strings.Join(settings, comma)
+}
+
+func (config QemuVGA) mapToApiCreate(version Version, builder *strings.Builder) {
+	if v := config.mapToApi(version); v != "" {
+		builder.WriteString("&" + qemuApiKeyVGA + "=")
+		builder.WriteString(v)
+	}
+}
+
+func (config QemuVGA) mapToApiUpdate(current QemuVGA, version Version, builder, delete *strings.Builder) {
+	if config.Delete {
+		delete.WriteString("," + qemuApiKeyVGA)
+		return
+	}
+	currentStr := current.mapToApi(version)
+	if config.Clipboard != nil {
+		current.Clipboard = config.Clipboard
+	}
+	if config.Memory != nil {
+		current.Memory = config.Memory
+	}
+	if config.Type != nil {
+		current.Type = config.Type
+	}
+	configStr := current.mapToApi(version)
+	if currentStr == configStr {
+		return
+	}
+	if configStr == "" {
+		delete.WriteString("," + qemuApiKeyVGA)
+		return
+	}
+	builder.WriteString("&" + qemuApiKeyVGA + "=")
+	builder.WriteString(configStr)
+}
+
+// Validate checks the settings the vga device will have after the config is applied on top of current.
+// current is nil when the vga device is created.
+func (config QemuVGA) Validate(current *QemuVGA, version Version) error {
+	if config.Delete {
+		return nil
+	}
+	if current == nil {
+		current = &QemuVGA{}
+	}
+	vgaType := QemuVgaTypeStd
+	if config.Type != nil {
+		if err := config.Type.Validate(); err != nil {
+			return err
+		}
+		vgaType = *config.Type
+	} else if current.Type != nil {
+		vgaType = *current.Type
+	}
+	memory := current.Memory
+	if config.Memory != nil {
+		memory = config.Memory
+	}
+	if memory != nil && *memory != 0 {
+		if !vgaType.display() {
+			return errors.New(QemuVGA_Error_MemoryNotSupported)
+		}
+		if err := memory.validate(vgaType); err != nil {
+			return err
+		}
+	}
+	clipboard := current.Clipboard
+	if config.Clipboard != nil {
+		clipboard = config.Clipboard
+	}
+	if clipboard != nil && *clipboard != "" {
+		if err := clipboard.Validate(); err != nil {
+			return err
+		}
+		if !vgaType.display() {
+			return errors.New(QemuVGA_Error_ClipboardNotSupported)
+		}
+		if config.Clipboard != nil {
+			return CapabilityVgaClipboard.check(version)
+		}
+	}
+	return nil
+}
+
+func (raw *rawConfigQemu) GetVGA() *QemuVGA {
+	v, isSet := raw.a[qemuApiKeyVGA]
+	if !isSet {
+		return nil
+	}
+	vga := QemuVGA{Type: new(QemuVgaTypeStd)}
+	for key, value := range splitStringOfSettings(v.(string)) {
+		switch key {
+		case "clipboard":
+			vga.Clipboard = new(QemuVgaClipboard(value))
+		case "memory":
+			if tmp, err := strconv.ParseUint(value, 10, 16); err == nil {
+				vga.Memory = new(QemuVgaMemory(tmp))
+			}
+		case "type":
+			*vga.Type = QemuVgaType(value)
+		default:
+			if value == "" && key != "" { // the type is the default key
+				*vga.Type = QemuVgaType(key)
+			}
+		}
+	}
+	return &vga
+}
+
+// Enum
+//
+//	const (
+//		QemuVgaClipboardNone
+//		QemuVgaClipboardVnc
+//	)
+type QemuVgaClipboard string
+
+const (
+	QemuVgaClipboardNone QemuVgaClipboard = ""
+	QemuVgaClipboardVnc  QemuVgaClipboard = "vnc"
+)
+
+const QemuVgaClipboard_Error = "invalid vga clipboard"
+
+func (clipboard QemuVgaClipboard) String() string { return string(clipboard) } // String is for fmt.Stringer.
+
+func (clipboard QemuVgaClipboard) Validate() error {
+	switch clipboard {
+	case QemuVgaClipboardNone, QemuVgaClipboardVnc:
+		return nil
+	}
+	return errors.New(QemuVgaClipboard_Error)
+}
+
+// QemuVgaMemory is the amount of video memory in MiB.
+type QemuVgaMemory uint16
+
+const (
+	QemuVgaMemoryMaximum QemuVgaMemory = 512
+	QemuVgaMemoryMinimum QemuVgaMemory = 4
+)
+
+const (
+	QemuVgaMemory_Error_Cirrus  = "vga memory of the cirrus type must be 4, 8 or 16 MiB"
+	QemuVgaMemory_Error_Maximum = "vga memory may not be more than 512 MiB"
+	QemuVgaMemory_Error_Minimum = "vga memory must be at least 4 MiB"
+)
+
+func (memory QemuVgaMemory) String() string { return strconv.Itoa(int(memory)) } // String is for fmt.Stringer.
+
+func (memory QemuVgaMemory) Validate() error {
+	if memory < QemuVgaMemoryMinimum {
+		return errors.New(QemuVgaMemory_Error_Minimum)
+	}
+	if memory > QemuVgaMemoryMaximum {
+		return errors.New(QemuVgaMemory_Error_Maximum)
+	}
+	return nil
+}
+
+func (memory QemuVgaMemory) validate(vgaType QemuVgaType) error {
+	if vgaType == QemuVgaTypeCirrus {
+		switch memory {
+		case 4, 8, 16:
+			return nil
+		}
+		return errors.New(QemuVgaMemory_Error_Cirrus)
+	}
+	return memory.Validate()
+}
+
+// Enum
+//
+//	const (
+//		QemuVgaTypeCirrus
+//		QemuVgaTypeNone
+//		QemuVgaTypeQxl
+//		QemuVgaTypeQxl2
+//		QemuVgaTypeQxl3
+//		QemuVgaTypeQxl4
+//		QemuVgaTypeSerial0
+//		QemuVgaTypeSerial1
+//		QemuVgaTypeSerial2
+//		QemuVgaTypeSerial3
+//		QemuVgaTypeStd
+//		QemuVgaTypeVirtio
+//		QemuVgaTypeVirtioGL
+//		QemuVgaTypeVmware
+//	)
+type QemuVgaType string
+
+const (
+	QemuVgaTypeCirrus   QemuVgaType = "cirrus"
+	QemuVgaTypeNone     QemuVgaType = "none"
+	QemuVgaTypeQxl      QemuVgaType = "qxl"
+	QemuVgaTypeQxl2     QemuVgaType = "qxl2"
+	QemuVgaTypeQxl3     QemuVgaType = "qxl3"
+	QemuVgaTypeQxl4     QemuVgaType = "qxl4"
+	QemuVgaTypeSerial0  QemuVgaType = "serial0"
+	QemuVgaTypeSerial1  QemuVgaType = "serial1"
+	QemuVgaTypeSerial2  QemuVgaType = "serial2"
+	QemuVgaTypeSerial3  QemuVgaType = "serial3"
+	QemuVgaTypeStd      QemuVgaType = "std"
+	QemuVgaTypeVirtio   QemuVgaType = "virtio"
+	QemuVgaTypeVirtioGL QemuVgaType = "virtio-gl"
+	QemuVgaTypeVmware   QemuVgaType = "vmware"
+)
+
+const QemuVgaType_Error = "invalid vga type"
+
+// display returns false when the type does not emulate a graphics card.
+func (vgaType QemuVgaType) display() bool {
+	switch vgaType {
+	case QemuVgaTypeNone, QemuVgaTypeSerial0, QemuVgaTypeSerial1, QemuVgaTypeSerial2, QemuVgaTypeSerial3:
+		return false
+	}
+	return true
+}
+
+func (vgaType QemuVgaType) String() string { return string(vgaType) } // String is for fmt.Stringer.
+
+func (vgaType QemuVgaType) Validate() error {
+	switch vgaType {
+	case QemuVgaTypeCirrus, QemuVgaTypeNone, QemuVgaTypeQxl, QemuVgaTypeQxl2, QemuVgaTypeQxl3, QemuVgaTypeQxl4,
+		QemuVgaTypeSerial0, QemuVgaTypeSerial1, QemuVgaTypeSerial2, QemuVgaTypeSerial3,
+		QemuVgaTypeStd, QemuVgaTypeVirtio, QemuVgaTypeVirtioGL, QemuVgaTypeVmware:
+		return nil
+	}
+	return errors.New(QemuVgaType_Error)
+}
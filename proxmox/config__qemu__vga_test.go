@@ -0,0 +1,231 @@
+package proxmox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testData_ConfigQemu_VGA_Api() qemuTestsApiFunc {
+	return qemuTestsApiFunc(func() qemuTestsAPI {
+		return qemuTestsAPI{
+			createUpdate: []qemuTestCaseAPI{
+				{name: `delete no effect`,
+					config: &ConfigQemu{VGA: &QemuVGA{
+						Delete: true}}},
+				{name: `full`,
+					config: &ConfigQemu{VGA: &QemuVGA{
+						Clipboard: new(QemuVgaClipboardVnc),
+						Memory:    new(QemuVgaMemory(32)),
+						Type:      new(QemuVgaTypeVirtio)}},
+					version: Version{Major: 8, Minor: 1},
+					body:    map[string]string{"vga": "type%3Dvirtio%2Cmemory%3D32%2Cclipboard%3Dvnc"}}, // "type=virtio,memory=32,clipboard=vnc"
+				{name: `clipboard unsupported version`,
+					config: &ConfigQemu{VGA: &QemuVGA{
+						Clipboard: new(QemuVgaClipboardVnc),
+						Type:      new(QemuVgaTypeStd)}},
+					version: Version{Major: 8},
+					body:    map[string]string{"vga": "type%3Dstd"}}, // "type=std"
+				{name: `only Memory`,
+					config: &ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(16))}},
+					body: map[string]string{"vga": "memory%3D16"}}, // "memory=16"
+				{name: `only Type`,
+					config: &ConfigQemu{VGA: &QemuVGA{
+						Type: new(QemuVgaTypeSerial0)}},
+					body: map[string]string{"vga": "type%3Dserial0"}}}, // "type=serial0"
+			create: []qemuTestCaseAPI{
+				{name: `empty`,
+					config: &ConfigQemu{VGA: &QemuVGA{}}}},
+			update: []qemuTestCaseAPI{
+				{name: `delete`,
+					config: &ConfigQemu{VGA: &QemuVGA{
+						Delete: true}},
+					currentLegacy: ConfigQemu{VGA: &QemuVGA{}},
+					body:          map[string]string{"delete": "vga"}},
+				{name: `change Clipboard`,
+					config: &ConfigQemu{VGA: &QemuVGA{
+						Clipboard: new(QemuVgaClipboardNone)}},
+					currentLegacy: ConfigQemu{VGA: &QemuVGA{
+						Clipboard: new(QemuVgaClipboardVnc),
+						Type:      new(QemuVgaTypeStd)}},
+					version: Version{Major: 8, Minor: 2},
+					body:    map[string]string{"vga": "type%3Dstd"}}, // "type=std"
+				{name: `change Memory`,
+					config: &ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(64))}},
+					currentLegacy: ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(32)),
+						Type:   new(QemuVgaTypeQxl)}},
+					body: map[string]string{"vga": "type%3Dqxl%2Cmemory%3D64"}}, // "type=qxl,memory=64"
+				{name: `change Memory reset`,
+					config: &ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(0))}},
+					currentLegacy: ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(32)),
+						Type:   new(QemuVgaTypeQxl)}},
+					body: map[string]string{"vga": "type%3Dqxl"}}, // "type=qxl"
+				{name: `change Type`,
+					config: &ConfigQemu{VGA: &QemuVGA{
+						Type: new(QemuVgaTypeVmware)}},
+					currentLegacy: ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(32)),
+						Type:   new(QemuVgaTypeStd)}},
+					body: map[string]string{"vga": "type%3Dvmware%2Cmemory%3D32"}}, // "type=vmware,memory=32"
+				{name: `change same no effect`,
+					config: &ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(32)),
+						Type:   new(QemuVgaTypeStd)}},
+					currentLegacy: ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(32)),
+						Type:   new(QemuVgaTypeStd)}}}}}
+	})
+}
+
+func testData_ConfigQemu_VGA_Validate() qemuTestTypeValidateFunc {
+	return qemuTestTypeValidateFunc(func() (qemuTestTypeInvalid, qemuTestTypeValid) {
+		invalid := qemuTestTypeInvalid{
+			createUpdate: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuVgaType_Error)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Type: new(QemuVgaType("invalid"))}}),
+					err: errors.New(QemuVgaType_Error)},
+				{name: `errors.New(QemuVgaMemory_Error_Minimum)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(3))}}),
+					err: errors.New(QemuVgaMemory_Error_Minimum)},
+				{name: `errors.New(QemuVgaMemory_Error_Maximum)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(513)),
+						Type:   new(QemuVgaTypeVirtioGL)}}),
+					err: errors.New(QemuVgaMemory_Error_Maximum)},
+				{name: `errors.New(QemuVgaMemory_Error_Cirrus)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(32)),
+						Type:   new(QemuVgaTypeCirrus)}}),
+					err: errors.New(QemuVgaMemory_Error_Cirrus)},
+				{name: `errors.New(QemuVGA_Error_MemoryNotSupported)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(16)),
+						Type:   new(QemuVgaTypeSerial1)}}),
+					err: errors.New(QemuVGA_Error_MemoryNotSupported)},
+				{name: `errors.New(QemuVgaClipboard_Error)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Clipboard: new(QemuVgaClipboard("invalid"))}}),
+					err: errors.New(QemuVgaClipboard_Error)},
+				{name: `errors.New(QemuVGA_Error_ClipboardNotSupported)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Clipboard: new(QemuVgaClipboardVnc),
+						Type:      new(QemuVgaTypeNone)}}),
+					version: Version{Major: 9},
+					err:     errors.New(QemuVGA_Error_ClipboardNotSupported)},
+				{name: `clipboard version`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Clipboard: new(QemuVgaClipboardVnc)}}),
+					version: Version{Major: 8},
+					err:     CapabilityVgaClipboard.error(Version{Major: 8})}},
+			update: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuVGA_Error_MemoryNotSupported) current memory`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Type: new(QemuVgaTypeSerial0)}}),
+					current: &ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(32)),
+						Type:   new(QemuVgaTypeStd)}},
+					err: errors.New(QemuVGA_Error_MemoryNotSupported)},
+				{name: `errors.New(QemuVgaMemory_Error_Cirrus) current type`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(32))}}),
+					current: &ConfigQemu{VGA: &QemuVGA{
+						Type: new(QemuVgaTypeCirrus)}},
+					err: errors.New(QemuVgaMemory_Error_Cirrus)}}}
+		valid := qemuTestTypeValid{
+			createUpdate: []qemuTestCaseValid{
+				{name: `delete no effect`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Delete: true,
+						Type:   new(QemuVgaType("invalid"))}})},
+				{name: `full`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Clipboard: new(QemuVgaClipboardVnc),
+						Memory:    new(QemuVgaMemory(512)),
+						Type:      new(QemuVgaTypeQxl4)}}),
+					version: Version{Major: 8, Minor: 1}},
+				{name: `cirrus`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(8)),
+						Type:   new(QemuVgaTypeCirrus)}})},
+				{name: `memory reset`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(0)),
+						Type:   new(QemuVgaTypeSerial3)}})}},
+			update: []qemuTestCaseValid{
+				{name: `current clipboard old version`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Type: new(QemuVgaTypeVirtio)}}),
+					current: &ConfigQemu{VGA: &QemuVGA{
+						Clipboard: new(QemuVgaClipboardVnc),
+						Type:      new(QemuVgaTypeStd)}},
+					version: Version{Major: 8}},
+				{name: `remove memory and change type`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(0)),
+						Type:   new(QemuVgaTypeNone)}}),
+					current: &ConfigQemu{VGA: &QemuVGA{
+						Memory: new(QemuVgaMemory(32)),
+						Type:   new(QemuVgaTypeStd)}}}}}
+		return invalid, valid
+	})
+}
+
+func Test_ConfigQemu_VGA_Api(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_VGA_Api().Test(t)
+}
+
+func Test_ConfigQemu_VGA_Validate(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_VGA_Validate().Test(t)
+}
+
+func Test_QemuVGA_Validate(t *testing.T) {
+	t.Parallel()
+	validate := func(t *testing.T, config ConfigQemu, current *ConfigQemu, version Version, expectedErr error, valid bool) {
+		t.Helper()
+		var currentVGA *QemuVGA
+		if current != nil {
+			currentVGA = current.VGA
+		}
+		err := config.VGA.Validate(currentVGA, version)
+		if valid {
+			require.NoError(t, err)
+		} else {
+			require.Error(t, err)
+			if expectedErr != nil {
+				require.Equal(t, expectedErr, err)
+			}
+		}
+	}
+	testData_ConfigQemu_VGA_Validate().Inject(t, validate)
+}
+
+func testData_ConfigQemu_VGA_Get() []qemuTestCaseGet {
+	return []qemuTestCaseGet{
+		{name: `default key`,
+			input: map[string]any{"vga": string("qxl2,memory=64")},
+			output: testQemuBaseConfig_get(ConfigQemu{VGA: &QemuVGA{
+				Memory: new(QemuVgaMemory(64)),
+				Type:   new(QemuVgaTypeQxl2)}})},
+		{name: `default type`,
+			input: map[string]any{"vga": string("clipboard=vnc")},
+			output: testQemuBaseConfig_get(ConfigQemu{VGA: &QemuVGA{
+				Clipboard: new(QemuVgaClipboardVnc),
+				Type:      new(QemuVgaTypeStd)}})},
+		{name: `full`,
+			input: map[string]any{"vga": string("type=virtio-gl,memory=256,clipboard=vnc")},
+			output: testQemuBaseConfig_get(ConfigQemu{VGA: &QemuVGA{
+				Clipboard: new(QemuVgaClipboardVnc),
+				Memory:    new(QemuVgaMemory(256)),
+				Type:      new(QemuVgaTypeVirtioGL)}})},
+	}
+}
@@ -0,0 +1,252 @@
+package proxmox
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// QemuVirtioFS are the host directories shared with the guest over virtiofs.
+// Requires Proxmox VE 8.4 or later.
+type QemuVirtioFS map[QemuVirtioFSID]QemuVirtioFSShare
+
+const QemuVirtioFSAmount = uint8(QemuVirtioFSIDMaximum) + 1
+
+const (
+	qemuApiSettingVirtioFSCache       = "cache"
+	qemuApiSettingVirtioFSDirectIO    = "direct-io"
+	qemuApiSettingVirtioFSExposeACL   = "expose-acl"
+	qemuApiSettingVirtioFSExposeXattr = "expose-xattr"
+	qemuApiSettingVirtioFSMappingID   = "dirid"
+)
+
+func (raw *rawConfigQemu) GetVirtioFS() QemuVirtioFS {
+	shares := make(QemuVirtioFS)
+	for i := range QemuVirtioFSID(QemuVirtioFSAmount) {
+		if v, isSet := raw.a[qemuPrefixApiKeyVirtioFS+i.String()]; isSet {
+			shares[i] = QemuVirtioFSShare{}.mapToSDK(v.(string))
+		}
+	}
+	if len(shares) > 0 {
+		return shares
+	}
+	return nil
+}
+
+func (config QemuVirtioFS) mapToApiCreate(builder *strings.Builder) {
+	for i, e := range config {
+		if e.Delete {
+			continue
+		}
+		builder.WriteString("&" + qemuPrefixApiKeyVirtioFS + i.String() + "=")
+		builder.WriteString(e.mapToApi())
+	}
+}
+
+func (config QemuVirtioFS) mapToApiUpdate(current QemuVirtioFS, builder, delete *strings.Builder) {
+	for i, e := range config {
+		if v, isSet := current[i]; isSet { // update / delete
+			if e.Delete {
+				delete.WriteString("," + qemuPrefixApiKeyVirtioFS + i.String())
+				continue
+			}
+			if configStr := e.combine(v).mapToApi(); configStr != v.mapToApi() {
+				builder.WriteString("&" + qemuPrefixApiKeyVirtioFS + i.String() + "=")
+				builder.WriteString(configStr)
+			}
+		} else if !e.Delete { // create
+			builder.WriteString("&" + qemuPrefixApiKeyVirtioFS + i.String() + "=")
+			builder.WriteString(e.mapToApi())
+		}
+	}
+}
+
+func (config QemuVirtioFS) Validate(current QemuVirtioFS, version Version) error {
+	var create bool
+	for i, e := range config {
+		if err := i.Validate(); err != nil {
+			return err
+		}
+		if e.Delete {
+			continue
+		}
+		create = true
+		var currentShare *QemuVirtioFSShare
+		if v, isSet := current[i]; isSet {
+			currentShare = &v
+		}
+		if err := e.Validate(currentShare); err != nil {
+			return err
+		}
+	}
+	if create {
+		return CapabilityVirtioFS.check(version)
+	}
+	return nil
+}
+
+// Enum
+//
+//	const (
+//		QemuVirtioFSID0
+//		...
+//		QemuVirtioFSID9
+//	)
+type QemuVirtioFSID uint8
+
+const (
+	QemuVirtioFSID_Error_Invalid string = "virtiofs id must be in the range 0-9"
+
+	QemuVirtioFSIDMaximum = QemuVirtioFSID9
+
+	QemuVirtioFSID0 QemuVirtioFSID = 0
+	QemuVirtioFSID1 QemuVirtioFSID = 1
+	QemuVirtioFSID2 QemuVirtioFSID = 2
+	QemuVirtioFSID3 QemuVirtioFSID = 3
+	QemuVirtioFSID4 QemuVirtioFSID = 4
+	QemuVirtioFSID5 QemuVirtioFSID = 5
+	QemuVirtioFSID6 QemuVirtioFSID = 6
+	QemuVirtioFSID7 QemuVirtioFSID = 7
+	QemuVirtioFSID8 QemuVirtioFSID = 8
+	QemuVirtioFSID9 QemuVirtioFSID = 9
+)
+
+func (id QemuVirtioFSID) String() string { return strconv.Itoa(int(id)) } // String is for fmt.Stringer.
+
+func (id QemuVirtioFSID) Validate() error {
+	if id > QemuVirtioFSIDMaximum {
+		return errors.New(QemuVirtioFSID_Error_Invalid)
+	}
+	return nil
+}
+
+type QemuVirtioFSShare struct {
+	Cache       *QemuVirtioFSCache    `json:"cache,omitempty"`        // Never nil when returned
+	DirectIO    *bool                 `json:"direct_io,omitempty"`    // Never nil when returned
+	ExposeACL   *bool                 `json:"expose_acl,omitempty"`   // Never nil when returned, enabling also exposes extended attributes
+	ExposeXattr *bool                 `json:"expose_xattr,omitempty"` // Never nil when returned
+	MappingID   *ResourceMappingDirID `json:"mapping_id,omitempty"`   // Never nil when returned
+	Delete      bool                  `json:"delete,omitempty"`
+}
+
+const (
+	QemuVirtioFSShare_Error_MappingID   string = "virtiofs mapping id is required during creation"
+	QemuVirtioFSShare_Error_ExposeXattr string = "virtiofs extended attributes can not be disabled while acls are exposed"
+)
+
+func (config QemuVirtioFSShare) combine(current QemuVirtioFSShare) QemuVirtioFSShare {
+	if config.Cache != nil {
+		current.Cache = config.Cache
+	}
+	if config.DirectIO != nil {
+		current.DirectIO = config.DirectIO
+	}
+	if config.ExposeACL != nil {
+		current.ExposeACL = config.ExposeACL
+	}
+	if config.ExposeXattr != nil {
+		current.ExposeXattr = config.ExposeXattr
+	}
+	if config.MappingID != nil {
+		current.MappingID = config.MappingID
+	}
+	return current
+}
+
+func (config QemuVirtioFSShare) mapToApi() string {
+	var settings string
+	if config.MappingID != nil {
+		settings = qemuApiSettingVirtioFSMappingID + equal + config.MappingID.String()
+	}
+	if config.Cache != nil && *config.Cache != QemuVirtioFSCacheAuto {
+		settings += comma + qemuApiSettingVirtioFSCache + equal + config.Cache.String()
+	}
+	if config.DirectIO != nil && *config.DirectIO {
+		settings += comma + qemuApiSettingVirtioFSDirectIO + equal + "1"
+	}
+	if config.ExposeACL != nil && *config.ExposeACL {
+		settings += comma + qemuApiSettingVirtioFSExposeACL + equal + "1"
+	}
+	if config.ExposeXattr != nil && *config.ExposeXattr {
+		settings += comma + qemuApiSettingVirtioFSExposeXattr + equal + "1"
+	}
+	return settings
+}
+
+func (QemuVirtioFSShare) mapToSDK(raw string) QemuVirtioFSShare {
+	settings := splitStringOfSettings(raw)
+	config := QemuVirtioFSShare{
+		Cache:       new(QemuVirtioFSCacheAuto),
+		DirectIO:    new(settings[qemuApiSettingVirtioFSDirectIO] == "1"),
+		ExposeACL:   new(settings[qemuApiSettingVirtioFSExposeACL] == "1"),
+		ExposeXattr: new(settings[qemuApiSettingVirtioFSExposeXattr] == "1" || settings[qemuApiSettingVirtioFSExposeACL] == "1"),
+		MappingID:   new(ResourceMappingDirID(settings[qemuApiSettingVirtioFSMappingID]))}
+	if v, isSet := settings[qemuApiSettingVirtioFSCache]; isSet {
+		*config.Cache = QemuVirtioFSCache(v)
+	}
+	if *config.MappingID == "" { // the mapping id is the default key
+		for key, value := range settings {
+			if value == "" && key != "" {
+				*config.MappingID = ResourceMappingDirID(key)
+			}
+		}
+	}
+	return config
+}
+
+// Validate checks the share the guest will have after the config is applied on top of current.
+// current is nil when the share is created.
+func (config QemuVirtioFSShare) Validate(current *QemuVirtioFSShare) error {
+	if config.Delete {
+		return nil
+	}
+	share := config
+	if current != nil {
+		share = config.combine(*current)
+	}
+	if config.MappingID != nil {
+		if err := config.MappingID.Validate(); err != nil {
+			return err
+		}
+	} else if share.MappingID == nil {
+		return errors.New(QemuVirtioFSShare_Error_MappingID)
+	}
+	if config.Cache != nil {
+		if err := config.Cache.Validate(); err != nil {
+			return err
+		}
+	}
+	if share.ExposeACL != nil && *share.ExposeACL && config.ExposeXattr != nil && !*config.ExposeXattr {
+		return errors.New(QemuVirtioFSShare_Error_ExposeXattr)
+	}
+	return nil
+}
+
+// Enum
+//
+//	const (
+//		QemuVirtioFSCacheAlways
+//		QemuVirtioFSCacheAuto
+//		QemuVirtioFSCacheMetadata
+//		QemuVirtioFSCacheNever
+//	)
+type QemuVirtioFSCache string
+
+const (
+	QemuVirtioFSCacheAlways   QemuVirtioFSCache = "always"
+	QemuVirtioFSCacheAuto     QemuVirtioFSCache = "auto"
+	QemuVirtioFSCacheMetadata QemuVirtioFSCache = "metadata"
+	QemuVirtioFSCacheNever    QemuVirtioFSCache = "never"
+)
+
+const QemuVirtioFSCache_Error_Invalid = "invalid virtiofs cache mode"
+
+func (cache QemuVirtioFSCache) String() string { return string(cache) } // String is for fmt.Stringer.
+
+func (cache QemuVirtioFSCache) Validate() error {
+	switch cache {
+	case QemuVirtioFSCacheAlways, QemuVirtioFSCacheAuto, QemuVirtioFSCacheMetadata, QemuVirtioFSCacheNever:
+		return nil
+	}
+	return errors.New(QemuVirtioFSCache_Error_Invalid)
+}
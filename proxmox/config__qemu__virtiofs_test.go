@@ -0,0 +1,227 @@
+package proxmox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testData_ConfigQemu_VirtioFS_Api() qemuTestsApiFunc {
+	return qemuTestsApiFunc(func() qemuTestsAPI {
+		return qemuTestsAPI{
+			createUpdate: []qemuTestCaseAPI{
+				{name: `create full`,
+					config: &ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID3: QemuVirtioFSShare{
+							Cache:       new(QemuVirtioFSCacheNever),
+							DirectIO:    new(true),
+							ExposeACL:   new(true),
+							ExposeXattr: new(true),
+							MappingID:   new(ResourceMappingDirID("share"))}}},
+					version: Version{Major: 8, Minor: 4},
+					body:    map[string]string{"virtiofs3": "dirid%3Dshare%2Ccache%3Dnever%2Cdirect-io%3D1%2Cexpose-acl%3D1%2Cexpose-xattr%3D1"}}, // "dirid=share,cache=never,direct-io=1,expose-acl=1,expose-xattr=1"
+				{name: `create minimal`,
+					config: &ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{
+							Cache:     new(QemuVirtioFSCacheAuto),
+							DirectIO:  new(false),
+							MappingID: new(ResourceMappingDirID("share"))}}},
+					version: Version{Major: 9},
+					body:    map[string]string{"virtiofs0": "dirid%3Dshare"}}, // "dirid=share"
+				{name: `delete non existing`,
+					config: &ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID1: QemuVirtioFSShare{Delete: true}}},
+					version: Version{Major: 8, Minor: 4}},
+				{name: `unsupported version`,
+					config: &ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{MappingID: new(ResourceMappingDirID("share"))}}},
+					version: Version{Major: 8, Minor: 3}}},
+			update: []qemuTestCaseAPI{
+				{name: `change`,
+					config: &ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID2: QemuVirtioFSShare{
+							Cache:    new(QemuVirtioFSCacheMetadata),
+							DirectIO: new(false)}}},
+					currentLegacy: ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID2: QemuVirtioFSShare{
+							Cache:       new(QemuVirtioFSCacheAuto),
+							DirectIO:    new(true),
+							ExposeACL:   new(false),
+							ExposeXattr: new(true),
+							MappingID:   new(ResourceMappingDirID("share"))}}},
+					version: Version{Major: 8, Minor: 4},
+					body:    map[string]string{"virtiofs2": "dirid%3Dshare%2Ccache%3Dmetadata%2Cexpose-xattr%3D1"}}, // "dirid=share,cache=metadata,expose-xattr=1"
+				{name: `delete`,
+					config: &ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID9: QemuVirtioFSShare{Delete: true}}},
+					currentLegacy: ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID9: QemuVirtioFSShare{MappingID: new(ResourceMappingDirID("share"))}}},
+					version: Version{Major: 8, Minor: 4},
+					body:    map[string]string{"delete": "virtiofs9"}},
+				{name: `same no effect`,
+					config: &ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{
+							Cache:     new(QemuVirtioFSCacheAlways),
+							MappingID: new(ResourceMappingDirID("share"))}}},
+					currentLegacy: ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{
+							Cache:     new(QemuVirtioFSCacheAlways),
+							MappingID: new(ResourceMappingDirID("share"))}}},
+					version: Version{Major: 8, Minor: 4}}}}
+	})
+}
+
+func testData_ConfigQemu_VirtioFS_Validate() qemuTestTypeValidateFunc {
+	return qemuTestTypeValidateFunc(func() (qemuTestTypeInvalid, qemuTestTypeValid) {
+		supported := Version{Major: 8, Minor: 4}
+		invalid := qemuTestTypeInvalid{
+			createUpdate: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuVirtioFSID_Error_Invalid)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						10: QemuVirtioFSShare{Delete: true}}}),
+					version: supported,
+					err:     errors.New(QemuVirtioFSID_Error_Invalid)},
+				{name: `errors.New(QemuVirtioFSShare_Error_MappingID)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{DirectIO: new(true)}}}),
+					version: supported,
+					err:     errors.New(QemuVirtioFSShare_Error_MappingID)},
+				{name: `errors.New(ResourceMappingDirID_Error_MinLength)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{MappingID: new(ResourceMappingDirID("a"))}}}),
+					version: supported,
+					err:     errors.New(ResourceMappingDirID_Error_MinLength)},
+				{name: `errors.New(ResourceMappingDirID_Error_Invalid)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{MappingID: new(ResourceMappingDirID("share,cache=always"))}}}),
+					version: supported,
+					err:     errors.New(ResourceMappingDirID_Error_Invalid)},
+				{name: `errors.New(QemuVirtioFSCache_Error_Invalid)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{
+							Cache:     new(QemuVirtioFSCache("invalid")),
+							MappingID: new(ResourceMappingDirID("share"))}}}),
+					version: supported,
+					err:     errors.New(QemuVirtioFSCache_Error_Invalid)},
+				{name: `errors.New(QemuVirtioFSShare_Error_ExposeXattr)`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{
+							ExposeACL:   new(true),
+							ExposeXattr: new(false),
+							MappingID:   new(ResourceMappingDirID("share"))}}}),
+					version: supported,
+					err:     errors.New(QemuVirtioFSShare_Error_ExposeXattr)},
+				{name: `version`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{MappingID: new(ResourceMappingDirID("share"))}}}),
+					version: Version{Major: 8, Minor: 3},
+					err:     CapabilityVirtioFS.error(Version{Major: 8, Minor: 3})}},
+			update: []qemuTestCaseInvalid{
+				{name: `errors.New(QemuVirtioFSShare_Error_ExposeXattr) current`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID4: QemuVirtioFSShare{ExposeXattr: new(false)}}}),
+					current: &ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID4: QemuVirtioFSShare{
+							ExposeACL:   new(true),
+							ExposeXattr: new(true),
+							MappingID:   new(ResourceMappingDirID("share"))}}},
+					version: supported,
+					err:     errors.New(QemuVirtioFSShare_Error_ExposeXattr)}}}
+		valid := qemuTestTypeValid{
+			createUpdate: []qemuTestCaseValid{
+				{name: `full`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{
+							Cache:       new(QemuVirtioFSCacheMetadata),
+							DirectIO:    new(true),
+							ExposeACL:   new(true),
+							ExposeXattr: new(true),
+							MappingID:   new(ResourceMappingDirID("share"))},
+						QemuVirtioFSID9: QemuVirtioFSShare{
+							MappingID: new(ResourceMappingDirID("other-share"))}}}),
+					version: supported},
+				{name: `delete old version`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{
+							Delete: true,
+							Cache:  new(QemuVirtioFSCache("invalid"))}}}),
+					version: Version{Major: 8}},
+				{name: `expose acl implies xattr`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID0: QemuVirtioFSShare{
+							ExposeACL: new(true),
+							MappingID: new(ResourceMappingDirID("share"))}}}),
+					version: supported}},
+			update: []qemuTestCaseValid{
+				{name: `only DirectIO`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID4: QemuVirtioFSShare{DirectIO: new(true)}}}),
+					current: &ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID4: QemuVirtioFSShare{MappingID: new(ResourceMappingDirID("share"))}}},
+					version: supported},
+				{name: `enable acl`,
+					input: testQemuBaseConfig_Validate(ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID4: QemuVirtioFSShare{ExposeACL: new(true)}}}),
+					current: &ConfigQemu{VirtioFS: QemuVirtioFS{
+						QemuVirtioFSID4: QemuVirtioFSShare{
+							ExposeACL:   new(false),
+							ExposeXattr: new(false),
+							MappingID:   new(ResourceMappingDirID("share"))}}},
+					version: supported}}}
+		return invalid, valid
+	})
+}
+
+func Test_ConfigQemu_VirtioFS_Api(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_VirtioFS_Api().Test(t)
+}
+
+func Test_ConfigQemu_VirtioFS_Validate(t *testing.T) {
+	t.Parallel()
+	testData_ConfigQemu_VirtioFS_Validate().Test(t)
+}
+
+func Test_QemuVirtioFS_Validate(t *testing.T) {
+	t.Parallel()
+	validate := func(t *testing.T, config ConfigQemu, current *ConfigQemu, version Version, expectedErr error, valid bool) {
+		t.Helper()
+		var currentVirtioFS QemuVirtioFS
+		if current != nil {
+			currentVirtioFS = current.VirtioFS
+		}
+		err := config.VirtioFS.Validate(currentVirtioFS, version)
+		if valid {
+			require.NoError(t, err)
+		} else {
+			require.Error(t, err)
+			if expectedErr != nil {
+				require.Equal(t, expectedErr, err)
+			}
+		}
+	}
+	testData_ConfigQemu_VirtioFS_Validate().Inject(t, validate)
+}
+
+func testData_ConfigQemu_VirtioFS_Get() []qemuTestCaseGet {
+	return []qemuTestCaseGet{
+		{name: `all`,
+			input: map[string]any{
+				"virtiofs0": string("dirid=share,cache=never,direct-io=1,expose-xattr=1"),
+				"virtiofs9": string("other-share,expose-acl=1")},
+			output: testQemuBaseConfig_get(ConfigQemu{VirtioFS: QemuVirtioFS{
+				QemuVirtioFSID0: QemuVirtioFSShare{
+					Cache:       new(QemuVirtioFSCacheNever),
+					DirectIO:    new(true),
+					ExposeACL:   new(false),
+					ExposeXattr: new(true),
+					MappingID:   new(ResourceMappingDirID("share"))},
+				QemuVirtioFSID9: QemuVirtioFSShare{
+					Cache:       new(QemuVirtioFSCacheAuto),
+					DirectIO:    new(false),
+					ExposeACL:   new(true),
+					ExposeXattr: new(true),
+					MappingID:   new(ResourceMappingDirID("other-share"))}}})},
+	}
+}
@@ -94,6 +94,9 @@ func testQemuBaseConfig_get(config ConfigQemu) *ConfigQemu {
 	if config.Description == nil {
 		config.Description = util.Pointer("")
 	}
+	if config.Hotplug == nil {
+		config.Hotplug = new(qemuHotplugDefault())
+	}
 	if config.ID == nil {
 		config.ID = new(GuestID(0))
 	}
@@ -109,6 +112,9 @@ func testQemuBaseConfig_get(config ConfigQemu) *ConfigQemu {
 	if config.Protection == nil {
 		config.Protection = util.Pointer(false)
 	}
+	if config.ScsiController == nil {
+		config.ScsiController = new(QemuScsiControllerLsi)
+	}
 	if config.Tablet == nil {
 		config.Tablet = util.Pointer(true)
 	}
@@ -329,6 +335,46 @@ func Test_ConfigQemu_mapToAPI(t *testing.T) {
 			update: []qemuTestCaseAPI{
 				{name: `ignored on update`,
 					config: &ConfigQemu{Architecture: new(QemuCpuArchitecture("x86_64"))}}}},
+		{category: `Audio`,
+			createUpdate: []qemuTestCaseAPI{
+				{name: `create Device`,
+					config: &ConfigQemu{Audio: &QemuAudio{Device: util.Pointer(QemuAudioDeviceIntelHDA)}},
+					output: map[string]any{"audio0": string("device=intel-hda")}},
+				{name: `create all`,
+					config: &ConfigQemu{Audio: &QemuAudio{
+						Device: util.Pointer(QemuAudioDeviceIch9IntelHDA),
+						Driver: util.Pointer(QemuAudioDriverSpice)}},
+					output: map[string]any{"audio0": string("device=ich9-intel-hda,driver=spice")}},
+				{name: `delete non existing`,
+					config: &ConfigQemu{Audio: &QemuAudio{Delete: true}}}},
+			update: []qemuTestCaseAPI{
+				{name: `replace Device`,
+					config: &ConfigQemu{Audio: &QemuAudio{Device: util.Pointer(QemuAudioDeviceAC97)}},
+					currentLegacy: ConfigQemu{Audio: &QemuAudio{
+						Device: util.Pointer(QemuAudioDeviceIntelHDA),
+						Driver: util.Pointer(QemuAudioDriverNone)}},
+					output: map[string]any{"audio0": string("device=AC97,driver=none")}},
+				{name: `replace Driver`,
+					config: &ConfigQemu{Audio: &QemuAudio{Driver: util.Pointer(QemuAudioDriverSpice)}},
+					currentLegacy: ConfigQemu{Audio: &QemuAudio{
+						Device: util.Pointer(QemuAudioDeviceIntelHDA),
+						Driver: util.Pointer(QemuAudioDriverNone)}},
+					output: map[string]any{"audio0": string("device=intel-hda,driver=spice")}},
+				{name: `set Driver without Device`,
+					config:        &ConfigQemu{Audio: &QemuAudio{Driver: util.Pointer(QemuAudioDriverSpice)}},
+					currentLegacy: ConfigQemu{Audio: &QemuAudio{}},
+					output:        map[string]any{"audio0": string("driver=spice")}},
+				{name: `delete existing`,
+					config:        &ConfigQemu{Audio: &QemuAudio{Delete: true}},
+					currentLegacy: ConfigQemu{Audio: &QemuAudio{}},
+					output:        map[string]any{"delete": string("audio0")}},
+				{name: `same`,
+					config: &ConfigQemu{Audio: &QemuAudio{
+						Device: util.Pointer(QemuAudioDeviceAC97),
+						Driver: util.Pointer(QemuAudioDriverSpice)}},
+					currentLegacy: ConfigQemu{Audio: &QemuAudio{
+						Device: util.Pointer(QemuAudioDeviceAC97),
+						Driver: util.Pointer(QemuAudioDriverSpice)}}}}},
 		{category: `CloudInit`, // Create CloudInit no need for update as update and create behave the same. will be changed in the future
 			createUpdate: []qemuTestCaseAPI{
 				{name: `CloudInit=nil`,
@@ -3273,6 +3319,20 @@ func Test_ConfigQemu_get(t *testing.T) {
 				{name: `x86_64`,
 					input:  map[string]any{"arch": string("x86_64")},
 					output: baseConfig(ConfigQemu{Architecture: new(QemuCpuArchitectureAmd64)})}}},
+		{category: `Audio`,
+			tests: []qemuTestCaseGet{
+				{name: `all`,
+					input: map[string]any{"audio0": "device=AC97,driver=none"},
+					output: baseConfig(ConfigQemu{Audio: &QemuAudio{
+						Device: util.Pointer(QemuAudioDeviceAC97),
+						Driver: util.Pointer(QemuAudioDriverNone)}})},
+				{name: `Driver default`,
+					input: map[string]any{"audio0": "device=ich9-intel-hda"},
+					output: baseConfig(ConfigQemu{Audio: &QemuAudio{
+						Device: util.Pointer(QemuAudioDeviceIch9IntelHDA),
+						Driver: util.Pointer(QemuAudioDriverSpice)}})}}},
+		{category: `Boot`,
+			tests: testData_ConfigQemu_Boot_Get()},
 		{category: `CPU`,
 			tests: testData_ConfigQemu_CPU_Get()},
 		{category: `CloudInit`,
@@ -5381,6 +5441,8 @@ func Test_ConfigQemu_get(t *testing.T) {
 						WorldWideName: "0x5008FA6500D9C8B3"}}}}})}}},
 		{category: `EfiDisk`,
 			tests: testDataEfiDiskGet()},
+		{category: `Hotplug`,
+			tests: testData_ConfigQemu_Hotplug_Get()},
 		{category: `Iso`,
 			tests: []qemuTestCaseGet{
 				{name: `All`,
@@ -5395,6 +5457,8 @@ func Test_ConfigQemu_get(t *testing.T) {
 								File:            "debian-11.0.0-amd64-netinst.iso",
 								Storage:         "local",
 								SizeInKibibytes: "377M"}}}}}})}}},
+		{category: `Machine`,
+			tests: testData_ConfigQemu_Machine_Get()},
 		{category: `Memory`,
 			tests: []qemuTestCaseGet{
 				{name: `All float64`,
@@ -5909,6 +5973,8 @@ func Test_ConfigQemu_get(t *testing.T) {
 						Source: util.Pointer(EntropySourceURandom),
 						Limit:  util.Pointer(uint(1000)),
 						Period: util.Pointer(time.Duration(1024) * time.Millisecond)}})}}},
+		{category: `ScsiController`,
+			tests: testData_ConfigQemu_ScsiController_Get()},
 		{category: `Serials`,
 			tests: []qemuTestCaseGet{
 				{name: `All`,
@@ -5928,6 +5994,8 @@ func Test_ConfigQemu_get(t *testing.T) {
 				{name: `single socket`,
 					input:  map[string]interface{}{"serial2": "socket"},
 					output: baseConfig(ConfigQemu{Serials: SerialInterfaces{SerialID2: SerialInterface{Socket: true}}})}}},
+		{category: `Smbios`,
+			tests: testData_ConfigQemu_Smbios_Get()},
 		{category: `StartAtNodeBoot`,
 			tests: []qemuTestCaseGet{
 				{name: `true`,
@@ -5988,8 +6056,14 @@ func Test_ConfigQemu_get(t *testing.T) {
 				{name: `All`,
 					input:  map[string]interface{}{"tpmstate0": string("local-lvm:vm-101-disk-0,size=4M,version=v2.0")},
 					output: baseConfig(ConfigQemu{TPM: &TpmState{Storage: "local-lvm", Version: util.Pointer(TpmVersion("v2.0"))}})}}},
+		{category: `Unused`,
+			tests: testData_ConfigQemu_Unused_Get()},
 		{category: `USBs`,
 			tests: testData_ConfigQemu_USB_Get()},
+		{category: `VGA`,
+			tests: testData_ConfigQemu_VGA_Get()},
+		{category: `VirtioFS`,
+			tests: testData_ConfigQemu_VirtioFS_Get()},
 		{category: `watchdog`,
 			tests: testData_ConfigQemu_Watchdog_Get()},
 		{category: `ID`,
@@ -6024,9 +6098,6 @@ func Test_ActiveRawConfigQemu_Get(t *testing.T) {
 		if config.Bios == "" {
 			config.Bios = ("seabios")
 		}
-		if config.Boot == "" {
-			config.Boot = "cdn"
-		}
 		if config.CPU == nil {
 			config.CPU = &QemuCPU{
 				Cores:   new(QemuCpuCores(1)),
@@ -6036,8 +6107,8 @@ func Test_ActiveRawConfigQemu_Get(t *testing.T) {
 		if config.Description == nil {
 			config.Description = util.Pointer("")
 		}
-		if config.Hotplug == "" {
-			config.Hotplug = "network,disk,usb"
+		if config.Hotplug == nil {
+			config.Hotplug = new(qemuHotplugDefault())
 		}
 		if config.ID == nil {
 			config.ID = new(GuestID(0))
@@ -6063,14 +6134,8 @@ func Test_ActiveRawConfigQemu_Get(t *testing.T) {
 		if config.QemuOs == "" {
 			config.QemuOs = "other"
 		}
-		if config.QemuUnusedDisks == nil {
-			config.QemuUnusedDisks = QemuDevices{}
-		}
-		if config.QemuVga == nil {
-			config.QemuVga = QemuDevice{}
-		}
-		if config.Scsihw == "" {
-			config.Scsihw = "lsi"
+		if config.ScsiController == nil {
+			config.ScsiController = new(QemuScsiControllerLsi)
 		}
 		if config.StartAtNodeBoot == nil {
 			config.StartAtNodeBoot = util.Pointer(false)
@@ -6287,6 +6352,45 @@ func Test_ConfigQemu_Validate(t *testing.T) {
 					{input: baseConfig(ConfigQemu{Agent: &QemuGuestAgent{Type: util.Pointer(QemuGuestAgentType("test"))}}),
 						current: &ConfigQemu{Agent: &QemuGuestAgent{Type: util.Pointer(QemuGuestAgentType_VirtIO)}},
 						err:     errors.New(QemuGuestAgentType_Error_Invalid)}}}},
+		{category: `Audio`,
+			valid: qemuTestTypeValidate{
+				createUpdate: []qemuTestCaseValidate{
+					{name: `all`,
+						input: baseConfig(ConfigQemu{Audio: &QemuAudio{
+							Device: util.Pointer(QemuAudioDeviceIch9IntelHDA),
+							Driver: util.Pointer(QemuAudioDriverSpice)}}),
+						current: &ConfigQemu{Audio: &QemuAudio{}}},
+					{name: `delete`,
+						input: baseConfig(ConfigQemu{Audio: &QemuAudio{
+							Delete: true,
+							Device: util.Pointer(QemuAudioDevice("invalid"))}}),
+						current: &ConfigQemu{Audio: &QemuAudio{}}}},
+				update: []qemuTestCaseValidate{
+					{name: `minimum`,
+						input:   baseConfig(ConfigQemu{Audio: &QemuAudio{}}),
+						current: &ConfigQemu{Audio: &QemuAudio{}}},
+					{name: `only Driver`,
+						input: baseConfig(ConfigQemu{Audio: &QemuAudio{
+							Driver: util.Pointer(QemuAudioDriverNone)}}),
+						current: &ConfigQemu{Audio: &QemuAudio{}}}}},
+			invalid: qemuTestTypeValidate{
+				create: []qemuTestCaseValidate{
+					{name: `errors.New(QemuAudioErrorDeviceNotSet)`,
+						input: baseConfig(ConfigQemu{Audio: &QemuAudio{
+							Driver: util.Pointer(QemuAudioDriverSpice)}}),
+						err: errors.New(QemuAudioErrorDeviceNotSet)}},
+				createUpdate: []qemuTestCaseValidate{
+					{name: `errors.New(QemuAudioDeviceErrorInvalid)`,
+						input: baseConfig(ConfigQemu{Audio: &QemuAudio{
+							Device: util.Pointer(QemuAudioDevice("ac97"))}}),
+						current: &ConfigQemu{Audio: &QemuAudio{}},
+						err:     errors.New(QemuAudioDeviceErrorInvalid)},
+					{name: `errors.New(QemuAudioDriverErrorInvalid)`,
+						input: baseConfig(ConfigQemu{Audio: &QemuAudio{
+							Device: util.Pointer(QemuAudioDeviceAC97),
+							Driver: util.Pointer(QemuAudioDriver("invalid"))}}),
+						current: &ConfigQemu{Audio: &QemuAudio{}},
+						err:     errors.New(QemuAudioDriverErrorInvalid)}}}},
 		{category: `CloudInit`,
 			valid: qemuTestTypeValidate{
 				createUpdate: []qemuTestCaseValidate{
@@ -6394,11 +6498,11 @@ func Test_ConfigQemu_Validate(t *testing.T) {
 						current: &ConfigQemu{CloudInit: &CloudInit{}}}}},
 			invalid: qemuTestTypeValidate{
 				createUpdate: []qemuTestCaseValidate{
-					{name: `errors.New(CloudInit_Error_UpgradePackagesPre8)`,
+					{name: `CapabilityCloudInitUpgradePackages`,
 						version: Version{Major: 7, Minor: 255, Patch: 255},
 						input:   baseConfig(ConfigQemu{CloudInit: &CloudInit{UpgradePackages: util.Pointer(true)}}),
 						current: &ConfigQemu{CloudInit: &CloudInit{}},
-						err:     errors.New(CloudInit_Error_UpgradePackagesPre8)},
+						err:     CapabilityCloudInitUpgradePackages.error(Version{Major: 7, Minor: 255, Patch: 255})},
 					{name: `errors.New(CloudInitSnippetPath_Error_InvalidCharacters)`,
 						input: baseConfig(ConfigQemu{CloudInit: &CloudInit{Custom: &CloudInitCustom{Meta: &CloudInitSnippet{
 							FilePath: CloudInitSnippetPath(test_data_qemu.CloudInitSnippetPath_Character_Illegal()[0])}}}}),
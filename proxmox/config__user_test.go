@@ -112,7 +112,7 @@ func Test_userClient_Delete(t *testing.T) {
 		{name: `API error`,
 			input:    UserID{Name: "test", Realm: "pve"},
 			requests: mockServer.RequestsError(path, mockServer.DELETE, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -151,7 +151,7 @@ func Test_userClient_Exists(t *testing.T) {
 		{name: `API error`,
 			input:    UserID{Name: "test", Realm: "pve"},
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -265,7 +265,7 @@ func Test_userClient_List(t *testing.T) {
 						}}}})},
 		{name: `API error`,
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -342,7 +342,7 @@ func Test_userClient_ListPartial(t *testing.T) {
 						"realm-type": "pam"}}})},
 		{name: `API error`,
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -392,7 +392,7 @@ func Test_userClient_Read(t *testing.T) {
 		{name: `API error`,
 			userID:   UserID{Name: "test", Realm: "pve"},
 			requests: mockServer.RequestsError(path, mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Validation error`,
 			userID: UserID{Name: "", Realm: "pve"},
 			err:    errors.New("no username is specified")},
@@ -495,7 +495,7 @@ func Test_userClient_Set(t *testing.T) {
 			input: ConfigUser{
 				User: UserID{Name: "test", Realm: "pve"}},
 			requests: mockServer.RequestsError(path+"/test@pve", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -574,7 +574,7 @@ func Test_userClient_Update(t *testing.T) {
 				Comment: util.Pointer("test comment"),
 				User:    UserID{Name: "test", Realm: "pve"}},
 			requests: mockServer.RequestsError(path, mockServer.PUT, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `API error password`,
 			input: ConfigUser{
 				User:     UserID{Name: "test", Realm: "pve"},
@@ -3,6 +3,9 @@ package proxmox
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -30,9 +33,66 @@ func (w *errorWrap) Error() string { return w.message + ": " + w.err.Error() }
 
 func (w *errorWrap) Unwrap() error { return w.err }
 
+// Is reports transport timeouts as Error.Timeout().
+func (w *errorWrap) Is(target error) bool {
+	if target != errTimeout {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(w.err, &netErr) && netErr.Timeout()
+}
+
+// errorCategory is an error with its own message that belongs to one of the error categories.
+type errorCategory struct {
+	category error
+	message  string
+}
+
+func (e *errorCategory) Error() string { return e.message }
+
+func (e *errorCategory) Unwrap() error { return e.category }
+
 type errorContext interface{ errorContext() string }
 
-var errGuestDoesNotExist = errors.New("guest does not exist")
+// Error categories, all errors returned by the API can be matched against them with errors.Is().
+var (
+	errAlreadyExists    = errors.New("already exists")
+	errGuestLocked      = errors.New("guest is locked")
+	errNotFound         = errors.New("not found")
+	errPermissionDenied = errors.New("permission error")
+	errTimeout          = errors.New("timeout")
+	errValidation       = errors.New("validation error")
+)
+
+// AlreadyExists matches errors caused by creating something that already exists.
+func (errorMsg) AlreadyExists() error { return errAlreadyExists }
+
+// GuestLocked matches errors caused by a guest being locked, e.g. by a backup or snapshot.
+func (errorMsg) GuestLocked() error { return errGuestLocked }
+
+// NotFound matches errors caused by something not existing.
+func (errorMsg) NotFound() error { return errNotFound }
+
+// PermissionDenied matches errors caused by failed authentication or missing privileges.
+func (errorMsg) PermissionDenied() error { return errPermissionDenied }
+
+// Timeout matches errors caused by something taking too long.
+func (errorMsg) Timeout() error { return errTimeout }
+
+// Validation matches errors caused by invalid parameters, use ApiError.ParameterErrors() for the details.
+func (errorMsg) Validation() error { return errValidation }
+
+func timeoutError(message string) error {
+	return &errorCategory{category: errTimeout, message: message}
+}
+
+var errLoginRequired error = &errorCategory{category: errPermissionDenied, message: "ticket expired, a new login is required"}
+
+// LoginRequired matches errors caused by a ticket that expired and can't be renewed without logging in again.
+// Also matches Error.PermissionDenied().
+func (errorMsg) LoginRequired() error { return errLoginRequired }
+
+var errGuestDoesNotExist error = &errorCategory{category: errNotFound, message: "guest does not exist"}
 
 func (msg errorMsg) GuestDoesNotExist() error { return errGuestDoesNotExist }
 
@@ -55,26 +115,21 @@ func (errorMsg) guestIsProtectedCantDelete(id GuestID) error {
 type functionalityVersionWrapper struct {
 	err           error
 	functionality string
+	minimum       Version
 	version       Version
 }
 
 func (w *functionalityVersionWrapper) Error() string {
-	return "functionality (" + w.functionality + ") not supported in version (" + w.version.String() + ")"
+	return "functionality (" + w.functionality + ") requires Proxmox VE >= " + strconv.Itoa(int(w.minimum.Major)) + "." + strconv.Itoa(int(w.minimum.Minor)) + ", got version (" + w.version.String() + ")"
 }
 
 func (w *functionalityVersionWrapper) Unwrap() error { return w.err }
 
-var errNotSupportedInVersion = errors.New("")
+var errNotSupportedInVersion = errors.New("not supported in version")
 
+// FunctionalityNotSupportedInVersion matches errors caused by the Proxmox VE version being too old.
 func (msg errorMsg) FunctionalityNotSupportedInVersion() error { return errNotSupportedInVersion }
 
-func functionalityNotSupportedInVersion(functionality string, version Version) error {
-	return &functionalityVersionWrapper{
-		err:           errNotSupportedInVersion,
-		functionality: functionality,
-		version:       version}
-}
-
 type ApiError struct {
 	Errors  map[string]any
 	Message string
@@ -104,6 +159,73 @@ func (e ApiError) Error() string {
 	return builder.String()
 }
 
+// Is matches the error against the error categories of the errorMsg namespace.
+func (e ApiError) Is(target error) bool {
+	switch target {
+	case errPermissionDenied:
+		if strings.HasPrefix(e.Message, "Permission check failed") {
+			return true
+		}
+	case errValidation:
+		return len(e.Errors) != 0 || strings.HasPrefix(e.Message, "Parameter verification failed")
+	}
+	code, _ := strconv.Atoi(e.Code)
+	return statusIs(code, target) || messageIs(e.Message, target)
+}
+
+// ParameterErrors returns the error message of each invalid parameter.
+func (e ApiError) ParameterErrors() map[string]string {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	params := make(map[string]string, len(e.Errors))
+	for k, v := range e.Errors {
+		params[k] = strings.TrimSuffix(fmt.Sprint(v), "\n")
+	}
+	return params
+}
+
+// statusIs matches the HTTP status code returned by Proxmox VE against the error categories.
+func statusIs(code int, target error) bool {
+	switch target {
+	case errNotFound:
+		return code == http.StatusNotFound
+	case errNotSupportedInVersion:
+		return code == http.StatusNotImplemented
+	case errPermissionDenied:
+		return code == http.StatusUnauthorized || code == http.StatusForbidden
+	case errTimeout:
+		return code == http.StatusGatewayTimeout || code == 596 // 596 is returned by pveproxy when the connection to the node timed out
+	}
+	return false
+}
+
+// StatusError is returned for error responses without a JSON body.
+type StatusError struct {
+	Code   int    // HTTP status code, e.g. 500
+	Status string // HTTP status, e.g. "500 Internal Server Error"
+}
+
+func (e *StatusError) Error() string { return e.Status }
+
+// Is matches the error against the error categories of the errorMsg namespace.
+func (e *StatusError) Is(target error) bool { return statusIs(e.Code, target) }
+
+// messageIs matches the error message returned by Proxmox VE against the error categories.
+func messageIs(message string, target error) bool {
+	switch target {
+	case errAlreadyExists:
+		return strings.Contains(message, "already exists") || strings.Contains(message, " already defined")
+	case errGuestLocked:
+		return strings.Contains(message, " is locked (")
+	case errNotFound:
+		return strings.HasPrefix(message, "no such ") || strings.Contains(message, " does not exist")
+	case errTimeout:
+		return strings.Contains(message, "got timeout") || strings.Contains(message, "timed out")
+	}
+	return false
+}
+
 type TaskError struct {
 	TaskID  string
 	Message string
@@ -112,3 +234,6 @@ type TaskError struct {
 func (e TaskError) Error() string {
 	return "task error: task id: " + e.TaskID + " message: " + e.Message
 }
+
+// Is matches the error against the error categories of the errorMsg namespace.
+func (e TaskError) Is(target error) bool { return messageIs(e.Message, target) }
@@ -1,7 +1,10 @@
 package proxmox
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -21,3 +24,130 @@ func Test_guestDoesNotExist(t *testing.T) {
 	err := errorMsg{}.guestDoesNotExist(id)
 	require.True(t, errors.Is(err, Error.GuestDoesNotExist()))
 }
+
+func Test_ApiError_Is(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  error
+		output []error
+	}{
+		{name: `AlreadyExists`,
+			input:  &ApiError{Code: "500", Message: "unable to create VM 100 - VM 100 already exists on node 'pve'"},
+			output: []error{Error.AlreadyExists()}},
+		{name: `AlreadyExists defined`,
+			input:  &ApiError{Code: "500", Message: "service 'vm:100' already defined"},
+			output: []error{Error.AlreadyExists()}},
+		{name: `GuestLocked`,
+			input:  &ApiError{Code: "500", Message: "VM is locked (backup)"},
+			output: []error{Error.GuestLocked()}},
+		{name: `NotFound code`,
+			input:  &ApiError{Code: "404", Message: "Not Found"},
+			output: []error{Error.NotFound()}},
+		{name: `NotFound no such`,
+			input:  &ApiError{Code: "500", Message: "no such user ('test@pve')"},
+			output: []error{Error.NotFound()}},
+		{name: `NotFound does not exist`,
+			input:  &ApiError{Code: "500", Message: "pool 'test' does not exist"},
+			output: []error{Error.NotFound()}},
+		{name: `PermissionDenied code`,
+			input:  &ApiError{Code: "401", Message: "authentication failure"},
+			output: []error{Error.PermissionDenied()}},
+		{name: `PermissionDenied message`,
+			input:  &ApiError{Code: "403", Message: "Permission check failed (/vms/100, VM.Audit)"},
+			output: []error{Error.PermissionDenied()}},
+		{name: `Timeout code`,
+			input:  &ApiError{Code: "596", Message: "Connection timed out"},
+			output: []error{Error.Timeout()}},
+		{name: `Timeout lock`,
+			input:  &ApiError{Code: "500", Message: "can't lock file '/var/lock/qemu-server/lock-100.conf' - got timeout"},
+			output: []error{Error.Timeout()}},
+		{name: `Validation`,
+			input:  &ApiError{Code: "400", Message: "Parameter verification failed.", Errors: map[string]any{"vmid": "invalid format\n"}},
+			output: []error{Error.Validation()}},
+		{name: `Version`,
+			input:  &ApiError{Code: "501", Message: "Method 'GET /cluster/ha/rules' not implemented"},
+			output: []error{Error.FunctionalityNotSupportedInVersion()}},
+		{name: `Status Version`,
+			input:  &StatusError{Code: 501, Status: "501 Not Implemented"},
+			output: []error{Error.FunctionalityNotSupportedInVersion()}},
+		{name: `Status NotFound`,
+			input:  &StatusError{Code: 404, Status: "404 Not Found"},
+			output: []error{Error.NotFound()}},
+		{name: `Status PermissionDenied`,
+			input:  &StatusError{Code: 401, Status: "401 Unauthorized"},
+			output: []error{Error.PermissionDenied()}},
+		{name: `Status Timeout`,
+			input:  &StatusError{Code: 596, Status: "596 Connection timed out"},
+			output: []error{Error.Timeout()}},
+		{name: `Status None`,
+			input: &StatusError{Code: 500, Status: "500 Internal Server Error"}},
+		{name: `Task NotFound`,
+			input:  &TaskError{TaskID: "UPID:pve:00000001:00000001:00000001:qmdelsnapshot:100:root@pam:", Message: "snapshot 'test' does not exist"},
+			output: []error{Error.NotFound()}},
+		{name: `Task GuestLocked`,
+			input:  &TaskError{Message: "CT is locked (snapshot)"},
+			output: []error{Error.GuestLocked()}},
+		{name: `Wrapped`,
+			input:  fmt.Errorf("reading guest: %w", &ApiError{Code: "500", Message: "Configuration file 'nodes/pve/qemu-server/100.conf' does not exist"}),
+			output: []error{Error.NotFound()}},
+		{name: `Permission`,
+			input:  Permission{Category: PermissionCategory_Root, Privileges: Privileges{PoolAllocate: true}}.error(),
+			output: []error{Error.PermissionDenied()}},
+		{name: `GuestDoesNotExist`,
+			input:  errorMsg{}.guestDoesNotExist(100),
+			output: []error{Error.NotFound(), Error.GuestDoesNotExist()}},
+		{name: `Wait timeout`,
+			input:  timeoutError("Wait timeout for:UPID:pve:00000001:00000001:00000001:qmstart:100:root@pam:"),
+			output: []error{Error.Timeout()}},
+		{name: `Transport timeout`,
+			input:  &errorWrap{err: context.DeadlineExceeded, message: "error performing http request"},
+			output: []error{Error.Timeout(), context.DeadlineExceeded}},
+		{name: `None`,
+			input: &ApiError{Code: "500", Message: "unable to parse value"}},
+	}
+	categories := []error{
+		Error.AlreadyExists(),
+		Error.FunctionalityNotSupportedInVersion(),
+		Error.GuestLocked(),
+		Error.NotFound(),
+		Error.PermissionDenied(),
+		Error.Timeout(),
+		Error.Validation()}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			for _, category := range categories {
+				require.Equal(t, slices.Contains(test.output, category), errors.Is(test.input, category), category.Error())
+			}
+			for _, err := range test.output {
+				require.ErrorIs(t, test.input, err)
+			}
+		})
+	}
+}
+
+func Test_ApiError_ParameterErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  ApiError
+		output map[string]string
+	}{
+		{name: `None`,
+			input: ApiError{Code: "500", Message: "error"}},
+		{name: `Multiple`,
+			input: ApiError{Code: "400", Errors: map[string]any{
+				"vmid":  "value must have a minimum value of 100\n",
+				"cores": "type check ('integer') failed - got 'a'"}},
+			output: map[string]string{
+				"vmid":  "value must have a minimum value of 100",
+				"cores": "type check ('integer') failed - got 'a'"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.output, test.input.ParameterErrors())
+		})
+	}
+}
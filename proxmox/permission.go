@@ -15,6 +15,7 @@ const (
 	key_Privileges_DatastoreAllocateTemplate string = "Datastore.AllocateTemplate"
 	key_Privileges_DatastoreAudit            string = "Datastore.Audit"
 	key_Privileges_GroupAllocate             string = "Group.Allocate"
+	key_Privileges_MappingUse                string = "Mapping.Use"
 	key_Privileges_PermissionsModify         string = "Permissions.Modify"
 	key_Privileges_PoolAllocate              string = "Pool.Allocate"
 	key_Privileges_PoolAudit                 string = "Pool.Audit"
@@ -22,6 +23,7 @@ const (
 	key_Privileges_RealmAllocateUser         string = "Realm.AllocateUser"
 	key_Privileges_SDNAllocate               string = "SDN.Allocate"
 	key_Privileges_SDNAudit                  string = "SDN.Audit"
+	key_Privileges_SDNUse                    string = "SDN.Use"
 	key_Privileges_SysAudit                  string = "Sys.Audit"
 	key_Privileges_SysConsole                string = "Sys.Console"
 	key_Privileges_SysIncoming               string = "Sys.Incoming"
@@ -96,7 +98,9 @@ func (p Permission) check(permissions map[permissionPath]privileges) error {
 }
 
 func (p Permission) error() error {
-	return errors.New(PermissionErrorPrefix + " the following privileges (" + p.Privileges.String() + ") are missing from path (" + string(p.Category.path().append(p.Item)) + ")")
+	return &errorCategory{
+		category: errPermissionDenied,
+		message:  PermissionErrorPrefix + " the following privileges (" + p.Privileges.String() + ") are missing from path (" + string(p.Category.path().append(p.Item)) + ")"}
 }
 
 func (p Permission) Validate() error {
@@ -118,6 +122,8 @@ const (
 	permissionCategory_NodePath    PermissionCategory = "/nodes"
 	PermissionCategory_Guest       PermissionCategory = "guest"
 	permissionCategory_GuestPath   PermissionCategory = "/vms"
+	PermissionCategory_Mapping     PermissionCategory = "mapping"
+	permissionCategory_MappingPath PermissionCategory = "/mapping"
 	PermissionCategory_Pool        PermissionCategory = "pool"
 	permissionCategory_PoolPath    PermissionCategory = "/pool"
 	PermissionCategory_Storage     PermissionCategory = "storage"
@@ -131,7 +137,7 @@ func (PermissionCategory) Error() error {
 }
 
 func (PermissionCategory) enumArray() []PermissionCategory {
-	return []PermissionCategory{PermissionCategory_Root, PermissionCategory_Access, PermissionCategory_Group, PermissionCategory_Realm, PermissionCategory_Node, PermissionCategory_Guest, PermissionCategory_Pool, PermissionCategory_Storage, PermissionCategory_Zone}
+	return []PermissionCategory{PermissionCategory_Root, PermissionCategory_Access, PermissionCategory_Group, PermissionCategory_Realm, PermissionCategory_Node, PermissionCategory_Guest, PermissionCategory_Pool, PermissionCategory_Storage, PermissionCategory_Zone, PermissionCategory_Mapping}
 }
 
 // returns the path for the category.
@@ -147,6 +153,8 @@ func (c PermissionCategory) path() permissionPath {
 		return "/access/groups"
 	case PermissionCategory_Guest:
 		return "/vms"
+	case PermissionCategory_Mapping:
+		return "/mapping"
 	case PermissionCategory_Node:
 		return "/nodes"
 	case PermissionCategory_Pool:
@@ -225,6 +233,7 @@ type Privileges struct {
 	DatastoreAllocateTemplate bool `json:"Datastore.AllocateTemplate,omitempty"`
 	DatastoreAudit            bool `json:"Datastore.Audit,omitempty"`
 	GroupAllocate             bool `json:"Group.Allocate,omitempty"`
+	MappingUse                bool `json:"Mapping.Use,omitempty"`
 	PermissionsModify         bool `json:"Permissions.Modify,omitempty"`
 	PoolAllocate              bool `json:"Pool.Allocate,omitempty"`
 	PoolAudit                 bool `json:"Pool.Audit,omitempty"`
@@ -232,6 +241,7 @@ type Privileges struct {
 	RealmAllocateUser         bool `json:"Realm.AllocateUser,omitempty"`
 	SDNAllocate               bool `json:"SDN.Allocate,omitempty"`
 	SDNAudit                  bool `json:"SDN.Audit,omitempty"`
+	SDNUse                    bool `json:"SDN.Use,omitempty"`
 	SysAudit                  bool `json:"Sys.Audit,omitempty"`
 	SysConsole                bool `json:"Sys.Console,omitempty"`
 	SysIncoming               bool `json:"Sys.Incoming,omitempty"`
@@ -275,6 +285,9 @@ func (p Privileges) String() (privileges string) { // String is for fmt.Stringer
 	if p.GroupAllocate {
 		privileges += key_Privileges_GroupAllocate + ", "
 	}
+	if p.MappingUse {
+		privileges += key_Privileges_MappingUse + ", "
+	}
 	if p.PermissionsModify {
 		privileges += key_Privileges_PermissionsModify + ", "
 	}
@@ -296,6 +309,9 @@ func (p Privileges) String() (privileges string) { // String is for fmt.Stringer
 	if p.SDNAudit {
 		privileges += key_Privileges_SDNAudit + ", "
 	}
+	if p.SDNUse {
+		privileges += key_Privileges_SDNUse + ", "
+	}
 	if p.SysAudit {
 		privileges += key_Privileges_SysAudit + ", "
 	}
@@ -384,6 +400,7 @@ type privileges struct {
 	DatastoreAllocateTemplate privilege
 	DatastoreAudit            privilege
 	GroupAllocate             privilege
+	MappingUse                privilege
 	PermissionsModify         privilege
 	PoolAllocate              privilege
 	PoolAudit                 privilege
@@ -391,6 +408,7 @@ type privileges struct {
 	RealmAllocateUser         privilege
 	SDNAllocate               privilege
 	SDNAudit                  privilege
+	SDNUse                    privilege
 	SysAudit                  privilege
 	SysConsole                privilege
 	SysIncoming               privilege
@@ -434,6 +452,9 @@ func (p privileges) includes(needed Privileges, number privilege) bool {
 	if needed.GroupAllocate && (p.GroupAllocate < number) {
 		return false
 	}
+	if needed.MappingUse && (p.MappingUse < number) {
+		return false
+	}
 	if needed.PermissionsModify && (p.PermissionsModify < number) {
 		return false
 	}
@@ -455,6 +476,9 @@ func (p privileges) includes(needed Privileges, number privilege) bool {
 	if needed.SDNAudit && (p.SDNAudit < number) {
 		return false
 	}
+	if needed.SDNUse && (p.SDNUse < number) {
+		return false
+	}
 	if needed.SysAudit && (p.SysAudit < number) {
 		return false
 	}
@@ -549,6 +573,9 @@ func (privileges) mapToSDK(params map[string]interface{}) (p privileges) {
 	if v, isSet := params[key_Privileges_GroupAllocate]; isSet {
 		p.GroupAllocate = privilege(0).extract(v)
 	}
+	if v, isSet := params[key_Privileges_MappingUse]; isSet {
+		p.MappingUse = privilege(0).extract(v)
+	}
 	if v, isSet := params[key_Privileges_PermissionsModify]; isSet {
 		p.PermissionsModify = privilege(0).extract(v)
 	}
@@ -570,6 +597,9 @@ func (privileges) mapToSDK(params map[string]interface{}) (p privileges) {
 	if v, isSet := params[key_Privileges_SDNAudit]; isSet {
 		p.SDNAudit = privilege(0).extract(v)
 	}
+	if v, isSet := params[key_Privileges_SDNUse]; isSet {
+		p.SDNUse = privilege(0).extract(v)
+	}
 	if v, isSet := params[key_Privileges_SysAudit]; isSet {
 		p.SysAudit = privilege(0).extract(v)
 	}
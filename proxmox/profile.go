@@ -0,0 +1,284 @@
+package proxmox
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	Profile_Error_ApiUrlEmpty     = "profile requires at least one api url"
+	Profile_Error_CaFile          = "no certificates found in ca file: "
+	Profile_Error_CommandFailed   = "credentials command failed: "
+	Profile_Error_CredentialsBoth = "profile credentials may only contain a user or a token, not both"
+	Profile_Error_CredentialsNone = "profile credentials require a user or a token"
+	Profile_Error_NameEmpty       = "profile name cannot be empty"
+	Profile_Error_NoCurrent       = "no profile specified and no current profile set"
+	Profile_Error_NotFound        = "profile does not exist: "
+	Profile_Error_SecretBoth      = "profile credentials may only contain a password or a command, not both"
+)
+
+// Environment variable that overrides the location of the profiles file.
+const ProfilesPathEnv = "PM_PROFILES"
+
+// Task timeout in seconds when a profile does not specify one.
+const profileDefaultTaskTimeout = 300
+
+// Profiles holds the connection settings of multiple clusters.
+// It is stored as JSON, by default in the user's config directory.
+type Profiles struct {
+	Current  string             `json:"current,omitempty"`
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+}
+
+// LoadProfiles reads the profiles file, an empty path reads the default location.
+// A file that does not exist yields no profiles.
+func LoadProfiles(path string) (*Profiles, error) {
+	if path == "" {
+		var err error
+		if path, err = ProfilesPath(); err != nil {
+			return nil, err
+		}
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &Profiles{}, nil
+		}
+		return nil, err
+	}
+	var profiles Profiles
+	if err = json.Unmarshal(raw, &profiles); err != nil {
+		return nil, err
+	}
+	return &profiles, nil
+}
+
+// ProfilesPath returns the default location of the profiles file.
+func ProfilesPath() (string, error) {
+	if path := os.Getenv(ProfilesPathEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "proxmox-api-go", "profiles.json"), nil
+}
+
+// NewClientFromProfile reads the profiles file and returns an authenticated client for the named profile.
+// An empty path reads the default location, an empty name uses the current profile.
+func NewClientFromProfile(ctx context.Context, path, name string) (*Client, error) {
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := profiles.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return profile.NewClient(ctx)
+}
+
+// Get returns the named profile, an empty name returns the current profile.
+func (p Profiles) Get(name string) (Profile, error) {
+	if name == "" {
+		if p.Current == "" {
+			return Profile{}, errors.New(Profile_Error_NoCurrent)
+		}
+		name = p.Current
+	}
+	profile, exists := p.Profiles[name]
+	if !exists {
+		return Profile{}, errors.New(Profile_Error_NotFound + name)
+	}
+	return profile, nil
+}
+
+// Names returns the names of all profiles in alphabetical order.
+func (p Profiles) Names() []string { return slices.Sorted(maps.Keys(p.Profiles)) }
+
+// Save writes the profiles file, an empty path writes the default location.
+// The file is only readable by the current user as it may contain credentials.
+func (p Profiles) Save(path string) error {
+	if path == "" {
+		var err error
+		if path, err = ProfilesPath(); err != nil {
+			return err
+		}
+	}
+	raw, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0600)
+}
+
+// Set adds or replaces the named profile, the first profile becomes the current profile.
+func (p *Profiles) Set(name string, profile Profile) error {
+	if name == "" {
+		return errors.New(Profile_Error_NameEmpty)
+	}
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+	if p.Profiles == nil {
+		p.Profiles = make(map[string]Profile)
+	}
+	p.Profiles[name] = profile
+	if p.Current == "" {
+		p.Current = name
+	}
+	return nil
+}
+
+// Use makes the named profile the current profile.
+func (p *Profiles) Use(name string) error {
+	if _, exists := p.Profiles[name]; !exists {
+		return errors.New(Profile_Error_NotFound + name)
+	}
+	p.Current = name
+	return nil
+}
+
+// Profile holds the settings to connect to a single cluster.
+type Profile struct {
+	// More than one url enables failover between the nodes of the cluster.
+	ApiUrls     []string           `json:"api_urls"`
+	Credentials ProfileCredentials `json:"credentials"`
+	HttpHeaders string             `json:"http_headers,omitempty"`
+	Proxy       string             `json:"proxy,omitempty"`
+	// In seconds, defaults to 300.
+	TaskTimeout int        `json:"task_timeout,omitempty"`
+	TLS         ProfileTLS `json:"tls,omitzero"`
+}
+
+// NewClient returns an authenticated client for the profile.
+func (p Profile) NewClient(ctx context.Context) (*Client, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := p.TLS.config()
+	if err != nil {
+		return nil, err
+	}
+	taskTimeout := p.TaskTimeout
+	if taskTimeout == 0 {
+		taskTimeout = profileDefaultTaskTimeout
+	}
+	var c *Client
+	if len(p.ApiUrls) == 1 {
+		c, err = NewClient(p.ApiUrls[0], nil, p.HttpHeaders, tlsConfig, p.Proxy, taskTimeout, false)
+	} else {
+		c, err = NewClientFailover(p.ApiUrls, nil, p.HttpHeaders, tlsConfig, p.Proxy, taskTimeout, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	secret, err := p.Credentials.secret(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if p.Credentials.Token != "" {
+		var id ApiTokenID
+		if err = id.Parse(p.Credentials.Token); err != nil {
+			return nil, err
+		}
+		c.SetAPIToken(ApiToken{ID: id, Secret: ApiTokenSecret(secret)})
+		// Tokens are not verified until used, so we verify it here.
+		if _, err = c.GetVersion(ctx); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	if err = c.Login(ctx, p.Credentials.User, secret, ""); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p Profile) Validate() error {
+	if len(p.ApiUrls) == 0 {
+		return errors.New(Profile_Error_ApiUrlEmpty)
+	}
+	return p.Credentials.Validate()
+}
+
+// ProfileCredentials authenticate with either a user and password or an API token and its secret.
+// The password or secret is either stored in the profile or printed by a command, e.g. a password manager.
+type ProfileCredentials struct {
+	Command  []string `json:"command,omitempty"`
+	Password string   `json:"password,omitempty"` // Password of the user or secret of the token.
+	Token    string   `json:"token,omitempty"`    // API token ID, e.g. user@pve!token
+	User     string   `json:"user,omitempty"`
+}
+
+// secret returns the password or token secret, running the command when one is configured.
+func (c ProfileCredentials) secret(ctx context.Context) (string, error) {
+	if len(c.Command) == 0 {
+		return c.Password, nil
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Command[0], c.Command[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.New(Profile_Error_CommandFailed + strings.TrimSpace(err.Error()+" "+stderr.String()))
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+func (c ProfileCredentials) Validate() error {
+	if c.User != "" && c.Token != "" {
+		return errors.New(Profile_Error_CredentialsBoth)
+	}
+	if c.User == "" && c.Token == "" {
+		return errors.New(Profile_Error_CredentialsNone)
+	}
+	if c.Password != "" && len(c.Command) != 0 {
+		return errors.New(Profile_Error_SecretBoth)
+	}
+	return nil
+}
+
+type ProfileTLS struct {
+	CaFile     string `json:"ca_file,omitempty"` // PEM encoded certificates to trust besides the system ones.
+	Insecure   bool   `json:"insecure,omitempty"`
+	ServerName string `json:"server_name,omitempty"`
+}
+
+func (t ProfileTLS) config() (*tls.Config, error) {
+	if t == (ProfileTLS{}) {
+		return nil, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: t.Insecure, ServerName: t.ServerName}
+	if t.CaFile == "" {
+		return config, nil
+	}
+	raw, err := os.ReadFile(t.CaFile)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(raw) {
+		return nil, errors.New(Profile_Error_CaFile + t.CaFile)
+	}
+	config.RootCAs = pool
+	return config, nil
+}
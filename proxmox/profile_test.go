@@ -0,0 +1,168 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadProfiles(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "dir", "profiles.json")
+	profiles, err := LoadProfiles(path)
+	require.NoError(t, err)
+	require.Equal(t, &Profiles{}, profiles)
+	require.NoError(t, profiles.Set("b", Profile{
+		ApiUrls:     []string{"https://b:8006/api2/json"},
+		Credentials: ProfileCredentials{Token: "root@pam!test", Password: "secret"}}))
+	require.NoError(t, profiles.Set("a", Profile{
+		ApiUrls:     []string{"https://a1:8006/api2/json", "https://a2:8006/api2/json"},
+		Credentials: ProfileCredentials{User: "root@pam", Command: []string{"pass", "pve"}},
+		TaskTimeout: 60,
+		TLS:         ProfileTLS{Insecure: true}}))
+	require.NoError(t, profiles.Save(path))
+	loaded, err := LoadProfiles(path)
+	require.NoError(t, err)
+	require.Equal(t, profiles, loaded)
+	require.Equal(t, "b", loaded.Current)
+	require.Equal(t, []string{"a", "b"}, loaded.Names())
+}
+
+func Test_Profiles_Get(t *testing.T) {
+	t.Parallel()
+	profile := Profile{ApiUrls: []string{"https://pve:8006/api2/json"}}
+	tests := []struct {
+		name     string
+		profiles Profiles
+		input    string
+		output   Profile
+		err      error
+	}{
+		{name: `Named`,
+			profiles: Profiles{Profiles: map[string]Profile{"test": profile}},
+			input:    "test",
+			output:   profile},
+		{name: `Current`,
+			profiles: Profiles{Current: "test", Profiles: map[string]Profile{"test": profile}},
+			output:   profile},
+		{name: `Invalid no current`,
+			profiles: Profiles{Profiles: map[string]Profile{"test": profile}},
+			err:      errors.New(Profile_Error_NoCurrent)},
+		{name: `Invalid not found`,
+			profiles: Profiles{Current: "test"},
+			input:    "other",
+			err:      errors.New(Profile_Error_NotFound + "other")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			profile, err := test.profiles.Get(test.input)
+			require.Equal(t, test.err, err)
+			require.Equal(t, test.output, profile)
+		})
+	}
+}
+
+func Test_Profiles_Use(t *testing.T) {
+	t.Parallel()
+	profiles := Profiles{Current: "a", Profiles: map[string]Profile{"a": {}, "b": {}}}
+	require.NoError(t, profiles.Use("b"))
+	require.Equal(t, "b", profiles.Current)
+	require.Equal(t, errors.New(Profile_Error_NotFound+"c"), profiles.Use("c"))
+	require.Equal(t, "b", profiles.Current)
+}
+
+func Test_Profile_Validate(t *testing.T) {
+	t.Parallel()
+	urls := []string{"https://pve:8006/api2/json"}
+	tests := []struct {
+		name   string
+		input  Profile
+		output error
+	}{
+		{name: `Valid password`,
+			input: Profile{ApiUrls: urls, Credentials: ProfileCredentials{User: "root@pam", Password: "test"}}},
+		{name: `Valid token command`,
+			input: Profile{ApiUrls: urls, Credentials: ProfileCredentials{Token: "root@pam!test", Command: []string{"cat", "secret"}}}},
+		{name: `Invalid api url`,
+			input:  Profile{Credentials: ProfileCredentials{User: "root@pam"}},
+			output: errors.New(Profile_Error_ApiUrlEmpty)},
+		{name: `Invalid credentials both`,
+			input:  Profile{ApiUrls: urls, Credentials: ProfileCredentials{User: "root@pam", Token: "root@pam!test"}},
+			output: errors.New(Profile_Error_CredentialsBoth)},
+		{name: `Invalid credentials none`,
+			input:  Profile{ApiUrls: urls},
+			output: errors.New(Profile_Error_CredentialsNone)},
+		{name: `Invalid secret both`,
+			input:  Profile{ApiUrls: urls, Credentials: ProfileCredentials{User: "root@pam", Password: "test", Command: []string{"echo"}}},
+			output: errors.New(Profile_Error_SecretBoth)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.output, test.input.Validate())
+		})
+	}
+}
+
+func Test_ProfileCredentials_secret(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  ProfileCredentials
+		output string
+		err    bool
+	}{
+		{name: `Password`,
+			input:  ProfileCredentials{Password: "test"},
+			output: "test"},
+		{name: `Command`,
+			input:  ProfileCredentials{Command: []string{"echo", "secret"}},
+			output: "secret"},
+		{name: `Command failed`,
+			input: ProfileCredentials{Command: []string{"false"}},
+			err:   true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			secret, err := test.input.secret(context.Background())
+			if test.err {
+				require.ErrorContains(t, err, Profile_Error_CommandFailed)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.output, secret)
+		})
+	}
+}
+
+func Test_NewClientFromProfile(t *testing.T) {
+	t.Parallel()
+	server := mockServer.New(t)
+	server.Set(mockServer.Append(mockServer.RequestsAuth(), mockServer.RequestsVersion("8.4.1")), t)
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	profiles := Profiles{}
+	require.NoError(t, profiles.Set("password", Profile{
+		ApiUrls:     []string{server.Url()},
+		Credentials: ProfileCredentials{User: "root@pam", Command: []string{"echo", "secret"}},
+		TLS:         ProfileTLS{Insecure: true}}))
+	require.NoError(t, profiles.Set("token", Profile{
+		ApiUrls:     []string{server.Url()},
+		Credentials: ProfileCredentials{Token: "root@pam!test", Password: "secret"},
+		TLS:         ProfileTLS{Insecure: true}}))
+	require.NoError(t, profiles.Save(path))
+	c, err := NewClientFromProfile(context.Background(), path, "")
+	require.NoError(t, err)
+	require.Equal(t, "FAKE_TICKET", c.session.AuthTicket)
+	require.Equal(t, profileDefaultTaskTimeout, c.TaskTimeout)
+	c, err = NewClientFromProfile(context.Background(), path, "token")
+	require.NoError(t, err)
+	version, err := c.Version(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Version{Major: 8, Minor: 4, Patch: 1}, version)
+}
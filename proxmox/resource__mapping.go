@@ -46,6 +46,25 @@ func (id ResourceMappingPciID) Validate() error {
 	return mappingID(id).Validate(resourceMappingPciKey)
 }
 
+// minimum length: 2
+// ,maximum length: 128
+// ,regex: ^\w(\w|\d|_|-){1,127}$
+type ResourceMappingDirID string
+
+const (
+	resourceMappingDirKey                string = "dir"
+	ResourceMappingDirID_Error_MaxLength string = resourceMappingDirKey + mappingID_Error_MaxLength
+	ResourceMappingDirID_Error_MinLength string = resourceMappingDirKey + mappingID_Error_MinLength
+	ResourceMappingDirID_Error_Start     string = resourceMappingDirKey + mappingID_Error_Start
+	ResourceMappingDirID_Error_Invalid   string = resourceMappingDirKey + mappingID_Error_Invalid
+)
+
+func (id ResourceMappingDirID) String() string { return string(id) } // String is for fmt.Stringer.
+
+func (id ResourceMappingDirID) Validate() error {
+	return mappingID(id).Validate(resourceMappingDirKey)
+}
+
 type mappingID string
 
 const (
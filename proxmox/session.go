@@ -11,16 +11,28 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const debugLargeBodyThreshold = 5 * 1024 * 1024
 
+const (
+	sessionTicketPath = "/access/ticket"
+	// Proxmox VE tickets expire after 2 hours, we renew them well before that.
+	ticketRenewAfter = time.Hour
+	ticketLifetime   = 2 * time.Hour
+)
+
 type Session struct {
 	httpClient *http.Client
 	ApiUrl     string
@@ -29,6 +41,33 @@ type Session struct {
 	AuthToken  string // Combination of user, realm, token ID and UUID
 	Headers    http.Header
 	Debug      bool
+
+	authMutex    sync.RWMutex // protects AuthTicket, CsrfToken, AuthToken, ticketIssued, ticketUser, loginUser, loginPassword and renewalHook
+	renewMutex   sync.Mutex   // ensures only one ticket renewal happens at a time
+	ticketIssued time.Time
+	ticketUser   string
+	renewalHook  func(TicketRenewal)
+	// Credentials of the last login without a one-time password.
+	// Used to log in again when the ticket expired and can't be renewed with itself.
+	loginUser     string
+	loginPassword string
+
+	limiter atomic.Pointer[sessionLimiter]
+	slogger atomic.Pointer[slog.Logger]
+
+	requestObserver atomic.Pointer[RequestObserver]
+
+	failover atomic.Pointer[endpointFailover]
+
+	cache atomic.Pointer[responseCache]
+}
+
+// TicketRenewal is passed to the renewal hook after every attempt to renew the authentication ticket.
+type TicketRenewal struct {
+	Err          error     // Nil when the ticket was renewed.
+	Issued       time.Time // When the renewed ticket was issued.
+	Unauthorized bool      // True when the renewal was triggered by a 401 response instead of the age of the ticket.
+	User         string
 }
 
 func NewSession(apiUrl string, hclient *http.Client, proxyString string, tls *tls.Config) (session *Session, err error) {
@@ -143,11 +182,126 @@ func responseJSON(resp *http.Response) (jbody map[string]interface{}, err error)
 	return jbody, err
 }
 
-func (s *Session) setAPIToken(token ApiToken) { s.AuthToken = token.String() }
+func (s *Session) setAPIToken(token ApiToken) {
+	s.authMutex.Lock()
+	s.AuthToken = token.String()
+	s.authMutex.Unlock()
+}
+
+// setRateLimit replaces the rate limit, requests already waiting keep the old limit.
+func (s *Session) setRateLimit(limit RateLimit) {
+	if limit == (RateLimit{}) {
+		s.limiter.Store(nil)
+		return
+	}
+	s.limiter.Store(newSessionLimiter(limit))
+}
+
+// setCredentials stores the credentials of a successful login, so a new ticket can be requested once the current one expired.
+// Logins with a one-time password can't be repeated, so the stored credentials are cleared instead.
+func (s *Session) setCredentials(username, password, otp string) {
+	s.authMutex.Lock()
+	if otp != "" {
+		username, password = "", ""
+	}
+	s.loginUser = username
+	s.loginPassword = password
+	s.authMutex.Unlock()
+}
+
+func (s *Session) setRenewalHook(hook func(TicketRenewal)) {
+	s.authMutex.Lock()
+	s.renewalHook = hook
+	s.authMutex.Unlock()
+}
 
+// setTicket also extracts the user and issue time from the ticket, so it can be renewed.
 func (s *Session) setTicket(ticket, csrfPreventionToken string) {
+	user, issued := parseTicket(ticket)
+	s.authMutex.Lock()
 	s.AuthTicket = ticket
 	s.CsrfToken = csrfPreventionToken
+	s.ticketIssued = issued
+	s.ticketUser = user
+	s.authMutex.Unlock()
+}
+
+// parseTicket extracts the user and issue time from a ticket in the format "PVE:user@realm:HEXTIME::signature".
+// When the issue time can't be determined the current time is returned.
+func parseTicket(ticket string) (user string, issued time.Time) {
+	issued = time.Now()
+	parts := strings.SplitN(ticket, ":", 4)
+	if len(parts) < 4 {
+		return
+	}
+	user = parts[1]
+	if seconds, err := strconv.ParseInt(parts[2], 16, 64); err == nil {
+		issued = time.Unix(seconds, 0)
+	}
+	return
+}
+
+// renewableTicket returns the current ticket when it can be renewed.
+func (s *Session) renewableTicket() (string, bool) {
+	s.authMutex.RLock()
+	defer s.authMutex.RUnlock()
+	return s.AuthTicket, s.AuthToken == "" && s.AuthTicket != "" && s.ticketUser != ""
+}
+
+// renewExpiringTicket renews the ticket when it is about to expire.
+// Errors are only reported to the renewal hook, as the current ticket might still be valid.
+// Only Error.LoginRequired() is returned, as an expired ticket will be rejected anyway.
+func (s *Session) renewExpiringTicket(ctx context.Context) error {
+	ticket, ok := s.renewableTicket()
+	if !ok {
+		return nil
+	}
+	s.authMutex.RLock()
+	issued := s.ticketIssued
+	s.authMutex.RUnlock()
+	if time.Since(issued) < ticketRenewAfter {
+		return nil
+	}
+	if err := s.renewTicket(ctx, ticket, false); errors.Is(err, errLoginRequired) {
+		return err
+	}
+	return nil
+}
+
+// renewTicket exchanges the ticket for a new one.
+// A ticket that expired, or was rejected, is replaced by logging in again with the stored credentials.
+// Without stored credentials Error.LoginRequired() is returned for an expired ticket.
+// When another goroutine already renewed the ticket, nothing happens.
+func (s *Session) renewTicket(ctx context.Context, ticket string, unauthorized bool) error {
+	s.renewMutex.Lock()
+	defer s.renewMutex.Unlock()
+	s.authMutex.RLock()
+	current, user, issued, hook := s.AuthTicket, s.ticketUser, s.ticketIssued, s.renewalHook
+	loginUser, password := s.loginUser, s.loginPassword
+	s.authMutex.RUnlock()
+	if current != ticket {
+		return nil
+	}
+	var err error
+	if time.Since(issued) < ticketLifetime {
+		err = s.login(ctx, user, ticket, "")
+	} else {
+		err = errLoginRequired
+	}
+	if err != nil && errors.Is(err, errPermissionDenied) && loginUser == user && password != "" {
+		err = s.login(ctx, user, password, "")
+	}
+	if hook != nil {
+		s.authMutex.RLock()
+		renewal := TicketRenewal{
+			Err:          err,
+			Issued:       s.ticketIssued,
+			Unauthorized: unauthorized,
+			User:         user}
+		s.authMutex.RUnlock()
+		hook(renewal)
+	}
+	return err
 }
 
 func (s *Session) login(ctx context.Context, username string, password string, otp string) (err error) {
@@ -156,10 +310,13 @@ func (s *Session) login(ctx context.Context, username string, password string, o
 		reqUser["otp"] = otp
 	}
 	reqbody := paramsToBody(reqUser)
-	olddebug := s.Debug
-	s.Debug = false // don't share passwords in debug log
-	resp, _, err := s.post(ctx, "/access/ticket", nil, &s.Headers, &reqbody)
-	s.Debug = olddebug
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.ApiUrl+sessionTicketPath, bytes.NewReader(reqbody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, _, err := s.do(req)
 	if err != nil {
 		return err
 	}
@@ -179,12 +336,23 @@ func (s *Session) login(ctx context.Context, username string, password string, o
 	if dat["NeedTFA"] == 1.0 {
 		return fmt.Errorf("missing TFA code")
 	}
-	s.AuthTicket = dat["ticket"].(string)
+	ticket := dat["ticket"].(string)
+	_, issued := parseTicket(ticket)
+	s.authMutex.Lock()
+	s.AuthTicket = ticket
 	s.CsrfToken = dat["CSRFPreventionToken"].(string)
+	s.ticketIssued = issued
+	s.ticketUser = username
+	s.authMutex.Unlock()
 	return nil
 }
 
+// NewRequest creates a request with the authentication headers of the session.
+// A ticket that is about to expire is renewed first.
 func (s *Session) NewRequest(ctx context.Context, method, url string, headers *http.Header, body io.Reader) (req *http.Request, err error) {
+	if err = s.renewExpiringTicket(ctx); err != nil {
+		return nil, err
+	}
 	req, err = http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
@@ -192,6 +360,8 @@ func (s *Session) NewRequest(ctx context.Context, method, url string, headers *h
 	if headers != nil {
 		req.Header = *headers
 	}
+	s.authMutex.RLock()
+	defer s.authMutex.RUnlock()
 	if s.AuthToken != "" {
 		req.Header["Authorization"] = []string{"PVEAPIToken=" + s.AuthToken}
 	} else if s.AuthTicket != "" {
@@ -202,23 +372,61 @@ func (s *Session) NewRequest(ctx context.Context, method, url string, headers *h
 }
 
 func (s *Session) do(req *http.Request) (resp *http.Response, retry bool, err error) {
+	if limiter := s.limiter.Load(); limiter != nil {
+		done, err := limiter.acquire(req.Context(), req.Method)
+		if err != nil {
+			return nil, false, err
+		}
+		defer done()
+	}
+	observer := s.observer()
+	var observed ObservedRequest
+	if observer != nil {
+		path := s.relativePath(req.URL)
+		observed = ObservedRequest{
+			Method:   req.Method,
+			Path:     path,
+			Endpoint: endpointTemplate(path),
+			Bytes:    req.ContentLength}
+		req = req.WithContext(observer.Start(req.Context(), observed))
+	}
+	start := time.Now()
+	resp, respBody, retry, err := s.roundTrip(req)
+	duration := time.Since(start)
+	if cache := s.cache.Load(); cache != nil && req.Method != http.MethodGet && req.Method != http.MethodHead {
+		if path := s.relativePath(req.URL); path != sessionTicketPath {
+			cache.invalidate(path)
+		}
+	}
+	s.logRequest(req, resp, respBody, duration, err)
+	if observer != nil {
+		result := ObservedResult{
+			Duration: duration,
+			Bytes:    int64(len(respBody)),
+			Err:      err}
+		if resp != nil {
+			result.Status = resp.StatusCode
+		}
+		result.ErrClass = RequestErrorClass(0).classify(result.Status, err)
+		observer.Finish(req.Context(), observed, result)
+	}
+	return resp, retry, err
+}
+
+func (s *Session) roundTrip(req *http.Request) (resp *http.Response, respBody []byte, retry bool, err error) {
 	// Add session headers
 	for k, v := range s.Headers {
 		req.Header[k] = v
 	}
 
 	if s.Debug {
-		includeBody := req.ContentLength < debugLargeBodyThreshold
-		d, _ := httputil.DumpRequestOut(req, includeBody)
-		if !includeBody {
-			d = append(d, fmt.Sprintf("<request body of %d bytes not shown>\n\n", req.ContentLength)...)
-		}
+		d := dumpRequest(req, req.ContentLength < debugLargeBodyThreshold)
 		log.Printf(">>>>>>>>>> REQUEST:\n%v", string(d))
 	}
 
-	resp, err = s.httpClient.Do(req)
+	resp, err = s.send(req)
 	if err != nil {
-		return nil, true, &errorWrap{
+		return nil, nil, true, &errorWrap{
 			err:     err,
 			message: "error performing http request",
 		}
@@ -228,9 +436,9 @@ func (s *Session) do(req *http.Request) (resp *http.Response, retry bool, err er
 	// session.do, and they might not be able to reliably close it themselves.
 	// Therefore, read the body out, close the original, then replace it with
 	// a NopCloser over the bytes, which does not need to be closed downsteam.
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, true, &errorWrap{
+		return nil, nil, true, &errorWrap{
 			err:     err,
 			message: "error reading response body",
 		}
@@ -239,11 +447,7 @@ func (s *Session) do(req *http.Request) (resp *http.Response, retry bool, err er
 	resp.Body = io.NopCloser(bytes.NewReader(respBody))
 
 	if s.Debug {
-		includeBody := resp.ContentLength < debugLargeBodyThreshold
-		dr, _ := httputil.DumpResponse(resp, includeBody)
-		if !includeBody {
-			dr = append(dr, fmt.Sprintf("<response body of %d bytes not shown>\n\n", resp.ContentLength)...)
-		}
+		dr := dumpResponse(resp, respBody, resp.ContentLength < debugLargeBodyThreshold)
 		log.Printf("<<<<<<<<<< RESULT:\n%v", string(dr))
 	}
 
@@ -261,13 +465,13 @@ func (s *Session) do(req *http.Request) (resp *http.Response, retry bool, err er
 				if v, ok := bodyObj["message"]; ok {
 					apiErr.Message = strings.TrimRight(v.(string), "\n")
 				}
-				return resp, false, &apiErr
+				return resp, respBody, false, &apiErr
 			}
 		}
-		return resp, true, errors.New(resp.Status)
+		return resp, respBody, true, &StatusError{Code: resp.StatusCode, Status: resp.Status}
 	}
 
-	return resp, false, nil
+	return resp, respBody, false, nil
 }
 
 // Perform a simple get to an endpoint
@@ -278,6 +482,32 @@ func (s *Session) request(
 	params *url.Values,
 	headers *http.Header,
 	body *[]byte,
+) (resp *http.Response, retry bool, err error) {
+	resp, retry, err = s.requestOnce(ctx, method, url, params, headers, body)
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized || url == sessionTicketPath {
+		return
+	}
+	// The ticket might have expired or been revoked, renew it and try once more.
+	ticket, ok := s.renewableTicket()
+	if !ok {
+		return
+	}
+	if renewErr := s.renewTicket(ctx, ticket, true); renewErr != nil {
+		if errors.Is(renewErr, errLoginRequired) {
+			return nil, false, renewErr
+		}
+		return
+	}
+	return s.requestOnce(ctx, method, url, params, headers, body)
+}
+
+func (s *Session) requestOnce(
+	ctx context.Context,
+	method string,
+	url string,
+	params *url.Values,
+	headers *http.Header,
+	body *[]byte,
 ) (resp *http.Response, retry bool, err error) {
 	// add params to url here
 	url = s.ApiUrl + url
@@ -293,7 +523,7 @@ func (s *Session) request(
 
 	req, err := s.NewRequest(ctx, method, url, headers, buf)
 	if err != nil {
-		return nil, true, err
+		return nil, !errors.Is(err, errLoginRequired), err
 	}
 
 	req.Header.Set("Accept", "application/json")
@@ -316,6 +546,21 @@ func (s *Session) requestJSON(
 	// 	headers.Add("Content-Type", "application/json")
 	// }
 
+	cache := s.cache.Load()
+	var cacheKey string
+	var generation uint64
+	if cache != nil && method == http.MethodGet {
+		cacheKey = url
+		if params != nil {
+			cacheKey += "?" + params.Encode()
+		}
+		var cached []byte
+		var hit bool
+		if cached, generation, hit = cache.get(cacheKey); hit {
+			return nil, false, json.Unmarshal(cached, &responseContainer)
+		}
+	}
+
 	resp, retry, err = s.request(ctx, method, url, params, headers, body)
 	if err != nil {
 		return resp, retry, err
@@ -333,6 +578,9 @@ func (s *Session) requestJSON(
 	if err = json.Unmarshal(rbody, &responseContainer); err != nil {
 		return resp, true, err
 	}
+	if cacheKey != "" {
+		cache.set(cacheKey, rbody, generation)
+	}
 
 	return resp, false, nil
 }
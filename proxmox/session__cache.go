@@ -0,0 +1,187 @@
+package proxmox
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheConfig enables caching of read-heavy endpoints.
+// Each field is the time to live of its entries, 0 disables caching for that kind of endpoint.
+// Mutating requests made by the same client invalidate the affected entries, as do the tasks they start once they are observed to have finished.
+type CacheConfig struct {
+	// /cluster/resources, invalidated by every mutating request.
+	Resources time.Duration
+	// /version, never invalidated.
+	Version time.Duration
+	// Guest, pool and storage configurations, invalidated by mutating requests against the same guest, pool or storage.
+	Config time.Duration
+}
+
+const CacheConfig_Error_Negative = "cache time to live may not be negative"
+
+func (config CacheConfig) Validate() error {
+	if config.Resources < 0 || config.Version < 0 || config.Config < 0 {
+		return errors.New(CacheConfig_Error_Negative)
+	}
+	return nil
+}
+
+// CacheStats are the statistics of the cache since it was configured.
+type CacheStats struct {
+	Entries       uint // Number of entries currently cached, including expired ones.
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64 // Number of entries removed by mutating requests.
+}
+
+// Scopes of cache entries, mutating requests invalidate entries by scope.
+const (
+	cacheScopeGuest     = "guest:"
+	cacheScopePool      = "pool"
+	cacheScopeResources = "resources"
+	cacheScopeStorage   = "storage"
+	cacheScopeVersion   = "version"
+)
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+	scope   string
+}
+
+// responseCache caches the bodies of GET requests, it is safe for concurrent use.
+type responseCache struct {
+	config     CacheConfig
+	entries    map[string]cacheEntry
+	generation uint64 // incremented on every invalidation, so responses that raced with a write are not stored.
+	mutex      sync.Mutex
+	now        func() time.Time
+	stats      CacheStats
+}
+
+func newResponseCache(config CacheConfig) *responseCache {
+	return &responseCache{
+		config:  config,
+		entries: make(map[string]cacheEntry),
+		now:     time.Now}
+}
+
+// classify returns the scope and time to live of the path, a time to live of 0 means the path is not cached.
+func (c *responseCache) classify(path string) (string, time.Duration) {
+	path, _, _ = strings.Cut(path, "?")
+	switch endpointTemplate(path) {
+	case "/cluster/resources":
+		return cacheScopeResources, c.config.Resources
+	case "/version":
+		return cacheScopeVersion, c.config.Version
+	case "/nodes/{node}/qemu/{vmid}/config", "/nodes/{node}/lxc/{vmid}/config":
+		return cacheScopeGuest + strings.Split(path, "/")[4], c.config.Config
+	case "/pools", "/pools/{poolid}":
+		return cacheScopePool, c.config.Config
+	case "/storage", "/storage/{storage}":
+		return cacheScopeStorage, c.config.Config
+	}
+	return "", 0
+}
+
+// get returns the cached body for the path, and the generation to pass to set.
+func (c *responseCache) get(path string) ([]byte, uint64, bool) {
+	if _, ttl := c.classify(path); ttl == 0 {
+		return nil, 0, false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, isSet := c.entries[path]
+	if !isSet || !c.now().Before(entry.expires) {
+		c.stats.Misses++
+		return nil, c.generation, false
+	}
+	c.stats.Hits++
+	return entry.body, c.generation, true
+}
+
+// set stores the body, unless the cache was invalidated since the generation was obtained.
+func (c *responseCache) set(path string, body []byte, generation uint64) {
+	scope, ttl := c.classify(path)
+	if ttl == 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.entries[path] = cacheEntry{body: body, expires: c.now().Add(ttl), scope: scope}
+}
+
+// invalidate removes the entries affected by a mutating request against the path.
+func (c *responseCache) invalidate(path string) {
+	path, _, _ = strings.Cut(path, "?")
+	scopes := map[string]struct{}{cacheScopeResources: {}}
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		switch segments[i-1] {
+		case "qemu", "lxc":
+			// guests may be added to or removed from a pool
+			scopes[cacheScopeGuest+segments[i]] = struct{}{}
+			scopes[cacheScopePool] = struct{}{}
+		}
+	}
+	switch {
+	case strings.HasPrefix(path, "/pools"):
+		scopes[cacheScopePool] = struct{}{}
+	case strings.HasPrefix(path, "/storage"):
+		scopes[cacheScopeStorage] = struct{}{}
+	}
+	c.invalidateScopes(scopes)
+}
+
+// invalidateTask removes the entries affected by a finished task.
+// Tasks like clone, start, migrate and resize change the state of the guest after the request that started them returned,
+// so entries read while the task was running are stale.
+func (c *responseCache) invalidateTask(upid UPID) {
+	scopes := map[string]struct{}{
+		cacheScopeResources: {},
+		cacheScopePool:      {}}
+	if upid.ID != "" {
+		scopes[cacheScopeGuest+upid.ID] = struct{}{}
+	}
+	c.invalidateScopes(scopes)
+}
+
+func (c *responseCache) invalidateScopes(scopes map[string]struct{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	for key, entry := range c.entries {
+		if _, isSet := scopes[entry.scope]; isSet {
+			delete(c.entries, key)
+			c.stats.Invalidations++
+		}
+	}
+}
+
+func (c *responseCache) clear() {
+	c.mutex.Lock()
+	c.generation++
+	c.entries = make(map[string]cacheEntry)
+	c.mutex.Unlock()
+}
+
+func (c *responseCache) statistics() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	stats := c.stats
+	stats.Entries = uint(len(c.entries))
+	return stats
+}
+
+func (s *Session) setCache(config CacheConfig) {
+	if config == (CacheConfig{}) {
+		s.cache.Store(nil)
+		return
+	}
+	s.cache.Store(newResponseCache(config))
+}
@@ -0,0 +1,214 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_SetCache(t *testing.T) {
+	t.Parallel()
+	const tokenPath = "/access/users/test@pve/token/testToken"
+	resources := []any{map[string]any{"vmid": float64(100), "type": "qemu", "node": "pve"}}
+	t.Run(`Invalid`, func(t *testing.T) {
+		t.Parallel()
+		_, c := testMockServerInit(t)
+		require.Equal(t, errors.New(CacheConfig_Error_Negative), c.SetCache(CacheConfig{Config: -1}))
+	})
+	t.Run(`Cached and invalidated`, func(t *testing.T) {
+		t.Parallel()
+		server, c := testMockServerInit(t)
+		require.NoError(t, c.SetCache(CacheConfig{Resources: time.Hour}))
+		server.Set(mockServer.Append(
+			mockServer.RequestsGetJsonData("/cluster/resources?type=vm", resources),
+			mockServer.RequestsDelete(tokenPath, nil),
+			mockServer.RequestsGetJsonData("/cluster/resources?type=vm", resources)), t)
+		for range 2 {
+			list, err := c.GetResourceList(context.Background(), resourceListGuest)
+			require.NoError(t, err)
+			require.Equal(t, resources, list)
+		}
+		_, err := c.New().ApiToken.Delete(context.Background(), ApiTokenID{
+			User:      UserID{Name: "test", Realm: "pve"},
+			TokenName: "testToken"})
+		require.NoError(t, err)
+		list, err := c.GetResourceList(context.Background(), resourceListGuest)
+		require.NoError(t, err)
+		require.Equal(t, resources, list)
+		server.Clear(t)
+		require.Equal(t, CacheStats{Entries: 1, Hits: 1, Misses: 2, Invalidations: 1}, c.CacheStats())
+		c.ClearCache()
+		require.Equal(t, uint(0), c.CacheStats().Entries)
+	})
+	t.Run(`Disabled`, func(t *testing.T) {
+		t.Parallel()
+		server, c := testMockServerInit(t)
+		server.Set(mockServer.Append(
+			mockServer.RequestsGetJsonData("/cluster/resources?type=vm", resources),
+			mockServer.RequestsGetJsonData("/cluster/resources?type=vm", resources)), t)
+		for range 2 {
+			_, err := c.GetResourceList(context.Background(), resourceListGuest)
+			require.NoError(t, err)
+		}
+		server.Clear(t)
+		require.Equal(t, CacheStats{}, c.CacheStats())
+	})
+}
+
+func Test_responseCache_classify(t *testing.T) {
+	t.Parallel()
+	cache := newResponseCache(CacheConfig{Resources: 1, Version: 2, Config: 3})
+	tests := []struct {
+		name  string
+		input string
+		scope string
+		ttl   time.Duration
+	}{
+		{name: `Resources`,
+			input: "/cluster/resources?type=vm",
+			scope: cacheScopeResources,
+			ttl:   1},
+		{name: `Version`,
+			input: "/version",
+			scope: cacheScopeVersion,
+			ttl:   2},
+		{name: `Qemu config`,
+			input: "/nodes/pve/qemu/100/config",
+			scope: cacheScopeGuest + "100",
+			ttl:   3},
+		{name: `Lxc config`,
+			input: "/nodes/pve/lxc/101/config",
+			scope: cacheScopeGuest + "101",
+			ttl:   3},
+		{name: `Pool`,
+			input: "/pools?poolid=test",
+			scope: cacheScopePool,
+			ttl:   3},
+		{name: `Storage`,
+			input: "/storage/local",
+			scope: cacheScopeStorage,
+			ttl:   3},
+		{name: `Not cached`,
+			input: "/nodes/pve/qemu/100/status/current"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			scope, ttl := cache.classify(test.input)
+			require.Equal(t, test.scope, scope)
+			require.Equal(t, test.ttl, ttl)
+		})
+	}
+}
+
+func Test_responseCache_invalidate(t *testing.T) {
+	t.Parallel()
+	paths := []string{
+		"/cluster/resources",
+		"/version",
+		"/nodes/pve/qemu/100/config",
+		"/nodes/pve/qemu/101/config",
+		"/pools?poolid=test",
+		"/storage/local"}
+	tests := []struct {
+		name   string
+		input  string
+		output []string
+	}{
+		{name: `Guest`,
+			input:  "/nodes/pve/qemu/100/config",
+			output: []string{"/nodes/pve/qemu/101/config", "/storage/local", "/version"}},
+		{name: `Pool`,
+			input:  "/pools/test",
+			output: []string{"/nodes/pve/qemu/100/config", "/nodes/pve/qemu/101/config", "/storage/local", "/version"}},
+		{name: `Storage`,
+			input:  "/storage/local",
+			output: []string{"/nodes/pve/qemu/100/config", "/nodes/pve/qemu/101/config", "/pools?poolid=test", "/version"}},
+		{name: `Other`,
+			input:  "/access/users/test@pve",
+			output: []string{"/nodes/pve/qemu/100/config", "/nodes/pve/qemu/101/config", "/pools?poolid=test", "/storage/local", "/version"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			cache := newResponseCache(CacheConfig{Resources: time.Hour, Version: time.Hour, Config: time.Hour})
+			for _, path := range paths {
+				cache.set(path, []byte("{}"), 0)
+			}
+			cache.invalidate(test.input)
+			var output []string
+			for _, path := range paths {
+				if _, _, hit := cache.get(path); hit {
+					output = append(output, path)
+				}
+			}
+			require.ElementsMatch(t, test.output, output)
+			require.Equal(t, uint64(len(paths)-len(test.output)), cache.statistics().Invalidations)
+		})
+	}
+}
+
+func Test_responseCache_invalidateTask(t *testing.T) {
+	t.Parallel()
+	paths := []string{
+		"/cluster/resources",
+		"/version",
+		"/nodes/pve/qemu/100/config",
+		"/nodes/pve/qemu/101/config",
+		"/pools?poolid=test",
+		"/storage/local"}
+	tests := []struct {
+		name   string
+		input  UPID
+		output []string
+	}{
+		{name: `Guest`,
+			input:  UPID{Node: "pve", Type: "qmclone", ID: "100"},
+			output: []string{"/nodes/pve/qemu/101/config", "/storage/local", "/version"}},
+		{name: `No guest`,
+			input:  UPID{Node: "pve", Type: "vzdump"},
+			output: []string{"/nodes/pve/qemu/100/config", "/nodes/pve/qemu/101/config", "/storage/local", "/version"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			cache := newResponseCache(CacheConfig{Resources: time.Hour, Version: time.Hour, Config: time.Hour})
+			for _, path := range paths {
+				cache.set(path, []byte("{}"), 0)
+			}
+			cache.invalidateTask(test.input)
+			var output []string
+			for _, path := range paths {
+				if _, _, hit := cache.get(path); hit {
+					output = append(output, path)
+				}
+			}
+			require.ElementsMatch(t, test.output, output)
+			require.Equal(t, uint64(len(paths)-len(test.output)), cache.statistics().Invalidations)
+		})
+	}
+}
+
+func Test_responseCache_get(t *testing.T) {
+	t.Parallel()
+	t.Run(`Expired`, func(t *testing.T) {
+		now := time.Unix(0, 0)
+		cache := newResponseCache(CacheConfig{Version: time.Minute})
+		cache.now = func() time.Time { return now }
+		cache.set("/version", []byte("{}"), 0)
+		_, _, hit := cache.get("/version")
+		require.True(t, hit)
+		now = now.Add(time.Minute)
+		_, _, hit = cache.get("/version")
+		require.False(t, hit)
+	})
+	t.Run(`Invalidated while in flight`, func(t *testing.T) {
+		cache := newResponseCache(CacheConfig{Resources: time.Minute})
+		_, generation, _ := cache.get("/cluster/resources")
+		cache.invalidate("/pools/test")
+		cache.set("/cluster/resources", []byte("{}"), generation)
+		_, _, hit := cache.get("/cluster/resources")
+		require.False(t, hit)
+	})
+}
@@ -0,0 +1,184 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	Failover_Error_NoEndpoints = "at least one api url is required"
+	Failover_Error_InvalidUrl  = "invalid api url: "
+)
+
+// Time a health check of an endpoint may take.
+const failoverHealthCheckTimeout = 5 * time.Second
+
+// endpointFailover sends requests to the active endpoint, and switches to the next healthy endpoint when it becomes unreachable.
+// Requests are built against `base`, which is replaced by the active endpoint.
+type endpointFailover struct {
+	base      string
+	endpoints []string
+	mutex     sync.Mutex
+	active    int
+}
+
+func newEndpointFailover(base string, endpoints []string) (*endpointFailover, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New(Failover_Error_NoEndpoints)
+	}
+	cleaned := make([]string, len(endpoints))
+	for i := range endpoints {
+		u, err := url.ParseRequestURI(endpoints[i])
+		if err != nil || u.Host == "" {
+			return nil, errors.New(Failover_Error_InvalidUrl + endpoints[i])
+		}
+		cleaned[i] = strings.TrimRight(endpoints[i], "/")
+	}
+	return &endpointFailover{base: base, endpoints: cleaned}, nil
+}
+
+func (f *endpointFailover) current() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.active
+}
+
+// activate makes the endpoint the active one, unless another request already switched away from `from`.
+func (f *endpointFailover) activate(from, to int) {
+	f.mutex.Lock()
+	if f.active == from {
+		f.active = to
+	}
+	f.mutex.Unlock()
+}
+
+// send sends the request to the active endpoint.
+// Requests that never reached the endpoint, and idempotent requests that fail without a response, are sent to the next healthy endpoint.
+// Other requests might have been processed before the response was lost, e.g. growing a disk or sending a key, so they are never sent again.
+func (f *endpointFailover) send(client *http.Client, req *http.Request) (*http.Response, error) {
+	active := f.current()
+	resp, err := client.Do(f.rewrite(req, active))
+	if err == nil || req.Context().Err() != nil || !requestReplayable(req) || !(requestIdempotent(req) || requestNotSent(err)) {
+		return resp, err
+	}
+	for i := 1; i < len(f.endpoints); i++ {
+		next := (active + i) % len(f.endpoints)
+		if !f.healthy(client, req, next) {
+			continue
+		}
+		retry := f.rewrite(req, next)
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			retry.Body = body
+		}
+		f.activate(active, next)
+		return client.Do(retry)
+	}
+	return resp, err
+}
+
+// healthy checks the endpoint by requesting its version, any response below 500 means the node is up.
+func (f *endpointFailover) healthy(client *http.Client, req *http.Request, endpoint int) bool {
+	ctx, cancel := context.WithTimeout(req.Context(), failoverHealthCheckTimeout)
+	defer cancel()
+	check, err := http.NewRequestWithContext(ctx, http.MethodGet, f.endpoints[endpoint]+"/version", nil)
+	if err != nil {
+		return false
+	}
+	for _, key := range []string{"Authorization", "CSRFPreventionToken"} {
+		if value := req.Header.Get(key); value != "" {
+			check.Header.Set(key, value)
+		}
+	}
+	resp, err := client.Do(check)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < 500
+}
+
+// rewrite points the request at the endpoint, requests that were not built against the base url are returned as is.
+func (f *endpointFailover) rewrite(req *http.Request, endpoint int) *http.Request {
+	raw := req.URL.String()
+	if !strings.HasPrefix(raw, f.base) {
+		return req
+	}
+	u, err := url.Parse(f.endpoints[endpoint] + strings.TrimPrefix(raw, f.base))
+	if err != nil {
+		return req
+	}
+	clone := req.Clone(req.Context())
+	clone.URL = u
+	clone.Host = u.Host
+	return clone
+}
+
+func (f *endpointFailover) endpoint() string {
+	return f.endpoints[f.current()]
+}
+
+// requestIdempotent reports whether the request may be sent again without side effects.
+// PUT and DELETE are not considered idempotent, as some Proxmox VE endpoints aren't, e.g. resizing a disk by "+1G".
+// Logging in is a POST, but has no side effects.
+func requestIdempotent(req *http.Request) bool {
+	if strings.HasSuffix(req.URL.Path, sessionTicketPath) {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// requestReplayable reports whether the request can be sent again, requests with a body that can't be read again can't.
+func requestReplayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// requestNotSent reports whether the error happened before a connection to the endpoint was made,
+// in which case the endpoint can't have processed the request.
+func requestNotSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// setEndpoints enables failover between the endpoints, the first endpoint becomes the active one.
+func (s *Session) setEndpoints(endpoints []string) error {
+	failover, err := newEndpointFailover(s.ApiUrl, endpoints)
+	if err != nil {
+		return err
+	}
+	s.failover.Store(failover)
+	return nil
+}
+
+// send sends the request, failing over to another endpoint when configured.
+func (s *Session) send(req *http.Request) (*http.Response, error) {
+	if failover := s.failover.Load(); failover != nil {
+		return failover.send(s.httpClient, req)
+	}
+	return s.httpClient.Do(req)
+}
+
+// activeEndpoint returns the url requests are currently sent to.
+func (s *Session) activeEndpoint() string {
+	if failover := s.failover.Load(); failover != nil {
+		return failover.endpoint()
+	}
+	return s.ApiUrl
+}
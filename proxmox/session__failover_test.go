@@ -0,0 +1,165 @@
+package proxmox
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewClientFailover(t *testing.T) {
+	t.Parallel()
+	const path = "/access/users/test@pve/token/testToken"
+	// An address nothing listens on.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachable := "https://" + listener.Addr().String()
+	require.NoError(t, listener.Close())
+	t.Run(`Failover`, func(t *testing.T) {
+		t.Parallel()
+		server := mockServer.New(t)
+		c, err := NewClientFailover([]string{unreachable, server.Url()}, nil, "", &tls.Config{InsecureSkipVerify: true}, "", 1000, false)
+		require.NoError(t, err)
+		c.timeUnit = time.Nanosecond
+		require.Equal(t, unreachable, c.ActiveEndpoint())
+		server.Set(mockServer.Append(
+			mockServer.RequestsVersion("8.4.1"),
+			mockServer.RequestsAuth(),
+			mockServer.RequestsDelete(path, nil)), t)
+		require.NoError(t, c.Login(context.Background(), "root@pam", "", ""))
+		require.Equal(t, server.Url(), c.ActiveEndpoint())
+		_, err = c.New().ApiToken.Delete(context.Background(), ApiTokenID{
+			User:      UserID{Name: "test", Realm: "pve"},
+			TokenName: "testToken"})
+		require.NoError(t, err)
+		server.Clear(t)
+	})
+	t.Run(`Unhealthy`, func(t *testing.T) {
+		t.Parallel()
+		server := mockServer.New(t)
+		c, err := NewClientFailover([]string{unreachable, server.Url()}, nil, "", &tls.Config{InsecureSkipVerify: true}, "", 1000, false)
+		require.NoError(t, err)
+		server.Set(mockServer.RequestsError("/version", mockServer.GET, 500, 1), t)
+		require.Error(t, c.Login(context.Background(), "root@pam", "", ""))
+		require.Equal(t, unreachable, c.ActiveEndpoint())
+		server.Clear(t)
+	})
+	t.Run(`Not idempotent not sent`, func(t *testing.T) {
+		t.Parallel()
+		server := mockServer.New(t)
+		c, err := NewClientFailover([]string{unreachable, server.Url()}, nil, "", &tls.Config{InsecureSkipVerify: true}, "", 1000, false)
+		require.NoError(t, err)
+		server.Set(mockServer.Append(
+			mockServer.RequestsVersion("8.4.1"),
+			mockServer.RequestsPost("/pools", map[string]any{"poolid": "test"})), t)
+		body := []byte("poolid=test")
+		_, _, err = c.session.post(context.Background(), "/pools", nil, nil, &body)
+		require.NoError(t, err)
+		require.Equal(t, server.Url(), c.ActiveEndpoint())
+		server.Clear(t)
+	})
+	// The connection is closed after the request was received, so the request might have been processed.
+	hangup, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { hangup.Close() })
+	go func() {
+		for {
+			conn, err := hangup.Accept()
+			if err != nil {
+				return
+			}
+			conn.Read(make([]byte, 1024))
+			conn.Close()
+		}
+	}()
+	for _, method := range []string{http.MethodDelete, http.MethodPost, http.MethodPut} {
+		t.Run(`Not idempotent response lost `+method, func(t *testing.T) {
+			t.Parallel()
+			server := mockServer.New(t)
+			c, err := NewClientFailover([]string{"http://" + hangup.Addr().String(), server.Url()}, nil, "", &tls.Config{InsecureSkipVerify: true}, "", 1000, false)
+			require.NoError(t, err)
+			server.Set(nil, t)
+			body := []byte("size=%2B1G")
+			_, _, err = c.session.requestOnce(context.Background(), method, "/nodes/pve/qemu/100/resize", nil, nil, &body)
+			require.Error(t, err)
+			require.Equal(t, "http://"+hangup.Addr().String(), c.ActiveEndpoint())
+			server.Clear(t)
+		})
+	}
+	t.Run(`No endpoints`, func(t *testing.T) {
+		t.Parallel()
+		_, err := NewClientFailover(nil, nil, "", nil, "", 1000, false)
+		require.Equal(t, errors.New(Failover_Error_NoEndpoints), err)
+	})
+	t.Run(`Invalid endpoint`, func(t *testing.T) {
+		t.Parallel()
+		_, err := NewClientFailover([]string{"https://127.0.0.1:8006/api2/json", "pve2"}, nil, "", nil, "", 1000, false)
+		require.Equal(t, errors.New(Failover_Error_InvalidUrl+"pve2"), err)
+	})
+}
+
+func Test_requestIdempotent(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   io.Reader
+		output bool
+	}{
+		{name: `GET`,
+			method: http.MethodGet,
+			path:   "/nodes",
+			output: true},
+		{name: `PUT`,
+			method: http.MethodPut,
+			path:   "/nodes/pve/qemu/100/resize",
+			body:   strings.NewReader("size=%2B1G")},
+		{name: `DELETE`,
+			method: http.MethodDelete,
+			path:   "/nodes/pve/qemu/100"},
+		{name: `POST`,
+			method: http.MethodPost,
+			path:   "/nodes/pve/qemu/100/status/start",
+			body:   strings.NewReader("timeout=30")},
+		{name: `POST login`,
+			method: http.MethodPost,
+			path:   "/api2/json/access/ticket",
+			body:   strings.NewReader("username=root%40pam"),
+			output: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			req, err := http.NewRequest(test.method, "https://127.0.0.1:8006"+test.path, test.body)
+			require.NoError(t, err)
+			require.Equal(t, test.output, requestIdempotent(req))
+		})
+	}
+}
+
+func Test_requestReplayable(t *testing.T) {
+	t.Parallel()
+	replayable, err := http.NewRequest(http.MethodPut, "https://127.0.0.1:8006/nodes", strings.NewReader("cores=2"))
+	require.NoError(t, err)
+	require.True(t, requestReplayable(replayable))
+	notReplayable, err := http.NewRequest(http.MethodPut, "https://127.0.0.1:8006/nodes", io.MultiReader(strings.NewReader("cores=2")))
+	require.NoError(t, err)
+	require.False(t, requestReplayable(notReplayable))
+}
+
+func Test_requestNotSent(t *testing.T) {
+	t.Parallel()
+	require.True(t, requestNotSent(&net.OpError{Op: "dial", Err: errors.New("no route to host")}))
+	require.True(t, requestNotSent(&net.OpError{Op: "read", Err: syscall.ECONNREFUSED}))
+	require.False(t, requestNotSent(&net.OpError{Op: "read", Err: syscall.ECONNRESET}))
+	require.False(t, requestNotSent(io.EOF))
+}
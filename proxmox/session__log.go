@@ -0,0 +1,131 @@
+package proxmox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Telmate/proxmox-api-go/internal/redact"
+)
+
+// logger returns nil when no logger is configured.
+func (s *Session) logger() *slog.Logger { return s.slogger.Load() }
+
+func (s *Session) setLogger(logger *slog.Logger) { s.slogger.Store(logger) }
+
+// logRequest emits a structured record for a finished request.
+func (s *Session) logRequest(req *http.Request, resp *http.Response, respBody []byte, duration time.Duration, err error) {
+	logger := s.logger()
+	if logger == nil {
+		return
+	}
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+	}
+	ctx := req.Context()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", s.relativePath(req.URL)),
+		slog.Duration("duration", duration)}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if upid := responseUPID(respBody); upid != "" {
+		attrs = append(attrs, slog.String("upid", upid))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, level, "api request", attrs...)
+}
+
+// relativePath returns the path of the request relative to the API URL, without query parameters.
+func (s *Session) relativePath(u *url.URL) string {
+	path := *u
+	path.RawQuery = ""
+	return strings.TrimPrefix(path.String(), s.ApiUrl)
+}
+
+// responseUPID returns the UPID of the task started by the request, if any.
+func responseUPID(body []byte) string {
+	if !bytes.HasPrefix(body, []byte(`{"data":"UPID:`)) {
+		return ""
+	}
+	var response struct {
+		Data string `json:"data"`
+	}
+	if json.Unmarshal(body, &response) != nil {
+		return ""
+	}
+	return response.Data
+}
+
+// logRetry emits a structured record for a failed attempt that will be retried.
+func logRetry(ctx context.Context, logger *slog.Logger, attempt RetryAttempt, delay time.Duration) {
+	if logger == nil {
+		return
+	}
+	logger.LogAttrs(ctx, slog.LevelWarn, "retrying api request",
+		slog.String("method", attempt.Method),
+		slog.String("path", attempt.Path),
+		slog.Uint64("attempt", uint64(attempt.Attempt)),
+		slog.Int("status", attempt.Status),
+		slog.Duration("delay", delay),
+		slog.String("error", attempt.Err.Error()))
+}
+
+// dumpRequest dumps the request with all secrets redacted.
+// The body is only included when it can be read without consuming the body of the original request.
+func dumpRequest(req *http.Request, includeBody bool) []byte {
+	clone := req.Clone(req.Context())
+	clone.Header = redact.Header(req.Header)
+	if includeBody && req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			includeBody = false
+		} else if body, err := req.GetBody(); err == nil {
+			raw, _ := io.ReadAll(body)
+			raw = redact.Body(req.Header.Get("Content-Type"), raw)
+			clone.Body = io.NopCloser(bytes.NewReader(raw))
+			clone.ContentLength = int64(len(raw))
+		}
+	}
+	if !includeBody {
+		clone.Body = nil
+		clone.GetBody = nil
+	}
+	d, _ := httputil.DumpRequestOut(clone, includeBody)
+	if !includeBody && req.ContentLength != 0 {
+		d = append(d, fmt.Sprintf("<request body of %d bytes not shown>\n\n", req.ContentLength)...)
+	}
+	return d
+}
+
+// dumpResponse dumps the response with all secrets redacted.
+func dumpResponse(resp *http.Response, body []byte, includeBody bool) []byte {
+	clone := *resp
+	clone.Header = redact.Header(resp.Header)
+	if includeBody {
+		body = redact.Body(resp.Header.Get("Content-Type"), body)
+		clone.Body = io.NopCloser(bytes.NewReader(body))
+		if clone.ContentLength >= 0 {
+			clone.ContentLength = int64(len(body))
+		}
+	}
+	d, _ := httputil.DumpResponse(&clone, includeBody)
+	if !includeBody {
+		d = append(d, fmt.Sprintf("<response body of %d bytes not shown>\n\n", resp.ContentLength)...)
+	}
+	return d
+}
@@ -0,0 +1,150 @@
+package proxmox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
+	"github.com/Telmate/proxmox-api-go/internal/redact"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_SetLogger(t *testing.T) {
+	t.Parallel()
+	const path = "/access/users/test@pve/token/testToken"
+	tests := []struct {
+		name     string
+		requests []mockServer.Request
+		output   []map[string]any
+	}{
+		{name: `Success`,
+			requests: mockServer.RequestsDelete(path, nil),
+			output: []map[string]any{
+				{"level": "DEBUG", "msg": "api request", "method": "DELETE", "path": path, "status": float64(200)}}},
+		{name: `Retry`,
+			requests: mockServer.Append(
+				mockServer.RequestsError(path, mockServer.DELETE, 500, 1),
+				mockServer.RequestsDelete(path, nil)),
+			output: []map[string]any{
+				{"level": "WARN", "msg": "api request", "method": "DELETE", "path": path, "status": float64(500), "error": mockServer.InternalServerError},
+				{"level": "WARN", "msg": "retrying api request", "method": "DELETE", "path": path, "status": float64(500), "error": mockServer.InternalServerError, "attempt": float64(1)},
+				{"level": "DEBUG", "msg": "api request", "method": "DELETE", "path": path, "status": float64(200)}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			server, c := testMockServerInit(t)
+			var buffer bytes.Buffer
+			c.SetLogger(slog.New(slog.NewJSONHandler(&buffer, &slog.HandlerOptions{
+				Level: slog.LevelDebug,
+				ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+					switch a.Key {
+					case slog.TimeKey, "duration", "delay":
+						return slog.Attr{}
+					}
+					return a
+				}})))
+			server.Set(test.requests, t)
+			_, err := c.New().ApiToken.Delete(context.Background(), ApiTokenID{
+				User:      UserID{Name: "test", Realm: "pve"},
+				TokenName: "testToken"})
+			require.NoError(t, err)
+			server.Clear(t)
+			var output []map[string]any
+			for line := range strings.Lines(buffer.String()) {
+				var record map[string]any
+				require.NoError(t, json.Unmarshal([]byte(line), &record))
+				output = append(output, record)
+			}
+			require.Equal(t, test.output, output)
+		})
+	}
+}
+
+// Not parallel, as the debug output is written to the standard logger.
+func Test_Client_SetLogger_ApiTokenSecret(t *testing.T) {
+	const secret = "0e3a4c6e-2f5b-4b8e-9a1d-7c6f5e4d3b2a"
+	server, c := testMockServerInit(t)
+	var buffer bytes.Buffer
+	c.SetLogger(slog.New(slog.NewJSONHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	c.session.Debug = true
+	log.SetOutput(&buffer)
+	defer log.SetOutput(os.Stderr)
+	server.Set([]mockServer.Request{{
+		Path:   "/access/users/test@pve/token/testToken",
+		Method: mockServer.POST,
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request, t *testing.T) {
+			w.Header().Set("Content-Type", "application/json;charset=utf-8")
+			w.Write([]byte(`{"data":{"full-tokenid":"test@pve!testToken","info":{"privsep":1},"value":"` + secret + `"}}`))
+		}}}, t)
+	tokenSecret, err := c.New().ApiToken.Create(context.Background(), UserID{Name: "test", Realm: "pve"}, ApiTokenConfig{Name: "testToken"})
+	require.NoError(t, err)
+	require.Equal(t, ApiTokenSecret(secret), tokenSecret)
+	server.Clear(t)
+	require.Contains(t, buffer.String(), "test@pve!testToken")
+	require.NotContains(t, buffer.String(), secret)
+}
+
+func Test_dumpRequest(t *testing.T) {
+	t.Parallel()
+	body := "password=secret&username=root%40pam"
+	req, err := http.NewRequest(http.MethodPost, "https://127.0.0.1:8006/api2/json/access/ticket", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "PVEAPIToken=root@pam!test=secret")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	dump := string(dumpRequest(req, true))
+	require.NotContains(t, dump, "secret")
+	require.Contains(t, dump, "password=%5BREDACTED%5D&username=root%40pam")
+	require.Contains(t, dump, "Authorization: "+redact.Redacted)
+	// the original request is left untouched
+	require.Equal(t, "PVEAPIToken=root@pam!test=secret", req.Header.Get("Authorization"))
+	original, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	require.Equal(t, body, string(original))
+}
+
+func Test_dumpResponse(t *testing.T) {
+	t.Parallel()
+	body := []byte(`{"data":{"CSRFPreventionToken":"csrf","ticket":"PVE:root@pam:66F2A1B0::secret","username":"root@pam"}}`)
+	resp := &http.Response{
+		StatusCode:    200,
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json;charset=UTF-8"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body))}
+	dump := string(dumpResponse(resp, body, true))
+	require.NotContains(t, dump, "secret")
+	require.NotContains(t, dump, "csrf")
+	require.Contains(t, dump, `"username":"root@pam"`)
+}
+
+func Test_responseUPID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		output string
+	}{
+		{name: `UPID`,
+			input:  `{"data":"UPID:pve:0000A1B2:0001C3D4:66F2A1B0:qmstart:100:root@pam:"}`,
+			output: "UPID:pve:0000A1B2:0001C3D4:66F2A1B0:qmstart:100:root@pam:"},
+		{name: `Other string`,
+			input: `{"data":"test"}`},
+		{name: `Null`,
+			input: `{"data":null}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			require.Equal(t, test.output, responseUPID([]byte(test.input)))
+		})
+	}
+}
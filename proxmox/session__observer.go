@@ -0,0 +1,178 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestObserver is notified about every request made to the API, e.g. to record metrics or tracing spans.
+// Implementations must be safe for concurrent use.
+type RequestObserver interface {
+	// Start is called right before the request is sent.
+	// The returned context is used for the request and passed to Finish.
+	Start(ctx context.Context, request ObservedRequest) context.Context
+	// Finish is called once the response has been read, or the request failed.
+	Finish(ctx context.Context, request ObservedRequest, result ObservedResult)
+}
+
+// ObservedRequest describes a request made to the API.
+type ObservedRequest struct {
+	Method string
+	// Path relative to the API URL, without query parameters. e.g. /nodes/pve1/qemu/100/config
+	Path string
+	// Path with its variable segments replaced by placeholders. e.g. /nodes/{node}/qemu/{vmid}/config
+	Endpoint string
+	// Size of the request body in bytes, -1 when unknown.
+	Bytes int64
+}
+
+// ObservedResult describes the outcome of a request made to the API.
+type ObservedResult struct {
+	Status   int           // HTTP status code, 0 when no response was received.
+	Duration time.Duration // Time between sending the request and reading the full response.
+	Bytes    int64         // Size of the response body in bytes.
+	Err      error
+	ErrClass RequestErrorClass
+}
+
+// RequestErrorClass groups request errors by their cause.
+type RequestErrorClass uint8
+
+const (
+	RequestErrorClassNone     RequestErrorClass = 0
+	RequestErrorClassCanceled RequestErrorClass = 1 // The context was canceled or its deadline exceeded.
+	RequestErrorClassNetwork  RequestErrorClass = 2 // No response was received.
+	RequestErrorClassClient   RequestErrorClass = 3 // 4xx status code.
+	RequestErrorClassServer   RequestErrorClass = 4 // 5xx status code.
+)
+
+func (RequestErrorClass) classify(status int, err error) RequestErrorClass {
+	switch {
+	case err == nil:
+		return RequestErrorClassNone
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return RequestErrorClassCanceled
+	case status >= 500:
+		return RequestErrorClassServer
+	case status >= 400:
+		return RequestErrorClassClient
+	default:
+		return RequestErrorClassNetwork
+	}
+}
+
+func (class RequestErrorClass) String() string { // String is for fmt.Stringer.
+	switch class {
+	case RequestErrorClassCanceled:
+		return "canceled"
+	case RequestErrorClassNetwork:
+		return "network"
+	case RequestErrorClassClient:
+		return "client"
+	case RequestErrorClassServer:
+		return "server"
+	default:
+		return ""
+	}
+}
+
+// Maps a path segment to the placeholder of the segment that follows it.
+var endpointPlaceholders = map[string]string{
+	"account":   "{name}",
+	"content":   "{volume}",
+	"dir":       "{id}",
+	"dns":       "{dns}",
+	"domains":   "{realm}",
+	"groups":    "{groupid}",
+	"ipset":     "{name}",
+	"lxc":       "{vmid}",
+	"network":   "{iface}",
+	"nodes":     "{node}",
+	"pci":       "{id}",
+	"plugins":   "{id}",
+	"pools":     "{poolid}",
+	"qemu":      "{vmid}",
+	"resources": "{sid}",
+	"roles":     "{roleid}",
+	"rules":     "{rule}",
+	"server":    "{id}",
+	"snapshot":  "{snapname}",
+	"storage":   "{storage}",
+	"subnets":   "{subnet}",
+	"tasks":     "{upid}",
+	"token":     "{tokenid}",
+	"usb":       "{id}",
+	"users":     "{userid}",
+	"vnets":     "{vnet}",
+	"zones":     "{zone}",
+}
+
+// endpointTemplate replaces the variable segments of the path with placeholders.
+func endpointTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		if i > 1 && segments[i-2] == "ipset" && segments[i] != "" { // the cidr of an ipset entry may contain a slash
+			return strings.Join(append(segments[:i], "{cidr}"), "/")
+		}
+		if placeholder, isSet := endpointPlaceholders[segments[i-1]]; isSet && segments[i] != "" {
+			segments[i] = placeholder
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func (s *Session) observer() RequestObserver {
+	if observer := s.requestObserver.Load(); observer != nil {
+		return *observer
+	}
+	return nil
+}
+
+func (s *Session) setObserver(observer RequestObserver) {
+	if observer == nil {
+		s.requestObserver.Store(nil)
+		return
+	}
+	s.requestObserver.Store(&observer)
+}
+
+// RequestRecorder is a RequestObserver that keeps all finished requests in memory.
+// Intended for tests.
+type RequestRecorder struct {
+	mutex   sync.Mutex
+	records []RecordedRequest
+}
+
+type RecordedRequest struct {
+	Request ObservedRequest
+	Result  ObservedResult
+}
+
+var _ RequestObserver = &RequestRecorder{}
+
+func (r *RequestRecorder) Start(ctx context.Context, _ ObservedRequest) context.Context { return ctx }
+
+func (r *RequestRecorder) Finish(_ context.Context, request ObservedRequest, result ObservedResult) {
+	r.mutex.Lock()
+	r.records = append(r.records, RecordedRequest{Request: request, Result: result})
+	r.mutex.Unlock()
+}
+
+// Records returns a copy of the requests recorded so far, in the order they finished.
+func (r *RequestRecorder) Records() []RecordedRequest {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	records := make([]RecordedRequest, len(r.records))
+	copy(records, r.records)
+	return records
+}
+
+// Reset removes all recorded requests.
+func (r *RequestRecorder) Reset() {
+	r.mutex.Lock()
+	r.records = nil
+	r.mutex.Unlock()
+}
@@ -0,0 +1,219 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_SetRequestObserver(t *testing.T) {
+	t.Parallel()
+	const path = "/access/users/test@pve/token/testToken"
+	const endpoint = "/access/users/{userid}/token/{tokenid}"
+	tests := []struct {
+		name     string
+		requests []mockServer.Request
+		output   []RecordedRequest
+	}{
+		{name: `Success`,
+			requests: mockServer.RequestsDelete(path, nil),
+			output: []RecordedRequest{
+				{Request: ObservedRequest{Method: "DELETE", Path: path, Endpoint: endpoint},
+					Result: ObservedResult{Status: 200}}}},
+		{name: `Retry`,
+			requests: mockServer.Append(
+				mockServer.RequestsError(path, mockServer.DELETE, 500, 1),
+				mockServer.RequestsDelete(path, nil)),
+			output: []RecordedRequest{
+				{Request: ObservedRequest{Method: "DELETE", Path: path, Endpoint: endpoint},
+					Result: ObservedResult{Status: 500, Bytes: 1, Err: &StatusError{Code: 500, Status: mockServer.InternalServerError}, ErrClass: RequestErrorClassServer}},
+				{Request: ObservedRequest{Method: "DELETE", Path: path, Endpoint: endpoint},
+					Result: ObservedResult{Status: 200}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			server, c := testMockServerInit(t)
+			recorder := &RequestRecorder{}
+			c.SetRequestObserver(recorder)
+			server.Set(test.requests, t)
+			_, err := c.New().ApiToken.Delete(context.Background(), ApiTokenID{
+				User:      UserID{Name: "test", Realm: "pve"},
+				TokenName: "testToken"})
+			require.NoError(t, err)
+			server.Clear(t)
+			records := recorder.Records()
+			for i := range records {
+				require.Greater(t, records[i].Result.Duration, time.Duration(0))
+				records[i].Result.Duration = 0
+			}
+			require.Equal(t, test.output, records)
+			recorder.Reset()
+			require.Empty(t, recorder.Records())
+		})
+	}
+}
+
+func Test_RequestErrorClass_classify(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		output RequestErrorClass
+	}{
+		{name: `None`,
+			status: 200},
+		{name: `Canceled`,
+			err:    context.Canceled,
+			output: RequestErrorClassCanceled},
+		{name: `Deadline exceeded`,
+			err:    &errorWrap{err: context.DeadlineExceeded, message: "error performing http request"},
+			output: RequestErrorClassCanceled},
+		{name: `Network`,
+			err:    errors.New("connection refused"),
+			output: RequestErrorClassNetwork},
+		{name: `Client`,
+			status: 403,
+			err:    &ApiError{Code: "403"},
+			output: RequestErrorClassClient},
+		{name: `Server`,
+			status: 596,
+			err:    &StatusError{Code: 596, Status: "596 Broken pipe"},
+			output: RequestErrorClassServer},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			require.Equal(t, test.output, RequestErrorClass(0).classify(test.status, test.err))
+		})
+	}
+}
+
+func Test_RequestErrorClass_String(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  RequestErrorClass
+		output string
+	}{
+		{name: `None`,
+			input: RequestErrorClassNone},
+		{name: `Canceled`,
+			input:  RequestErrorClassCanceled,
+			output: "canceled"},
+		{name: `Network`,
+			input:  RequestErrorClassNetwork,
+			output: "network"},
+		{name: `Client`,
+			input:  RequestErrorClassClient,
+			output: "client"},
+		{name: `Server`,
+			input:  RequestErrorClassServer,
+			output: "server"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			require.Equal(t, test.output, test.input.String())
+		})
+	}
+}
+
+func Test_endpointTemplate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		output string
+	}{
+		{name: `Qemu config`,
+			input:  "/nodes/pve1/qemu/100/config",
+			output: "/nodes/{node}/qemu/{vmid}/config"},
+		{name: `Collection`,
+			input:  "/nodes",
+			output: "/nodes"},
+		{name: `Task status`,
+			input:  "/nodes/pve1/tasks/UPID:pve1:0000A1B2:0001C3D4:66F2A1B0:qmstart:100:root@pam:/status",
+			output: "/nodes/{node}/tasks/{upid}/status"},
+		{name: `Storage content`,
+			input:  "/nodes/pve1/storage/local/content/local:iso%2Fdebian.iso",
+			output: "/nodes/{node}/storage/{storage}/content/{volume}"},
+		{name: `Static`,
+			input:  "/cluster/resources",
+			output: "/cluster/resources"},
+		{name: `ACME account`,
+			input:  "/cluster/acme/account/default",
+			output: "/cluster/acme/account/{name}"},
+		{name: `ACME plugin`,
+			input:  "/cluster/acme/plugins/cloudflare",
+			output: "/cluster/acme/plugins/{id}"},
+		{name: `API token`,
+			input:  "/access/users/root@pam/token/test",
+			output: "/access/users/{userid}/token/{tokenid}"},
+		{name: `Domain`,
+			input:  "/access/domains/ldap",
+			output: "/access/domains/{realm}"},
+		{name: `Firewall ipset`,
+			input:  "/nodes/pve1/qemu/100/firewall/ipset/allowed",
+			output: "/nodes/{node}/qemu/{vmid}/firewall/ipset/{name}"},
+		{name: `Firewall ipset cidr`,
+			input:  "/nodes/pve1/qemu/100/firewall/ipset/allowed/10.0.0.0/24",
+			output: "/nodes/{node}/qemu/{vmid}/firewall/ipset/{name}/{cidr}"},
+		{name: `Group`,
+			input:  "/access/groups/admins",
+			output: "/access/groups/{groupid}"},
+		{name: `HA resource`,
+			input:  "/cluster/ha/resources/100",
+			output: "/cluster/ha/resources/{sid}"},
+		{name: `HA rule`,
+			input:  "/cluster/ha/rules/rule1",
+			output: "/cluster/ha/rules/{rule}"},
+		{name: `Lxc snapshot`,
+			input:  "/nodes/pve1/lxc/101/snapshot/before/rollback",
+			output: "/nodes/{node}/lxc/{vmid}/snapshot/{snapname}/rollback"},
+		{name: `Mapping dir`,
+			input:  "/cluster/mapping/dir/share",
+			output: "/cluster/mapping/dir/{id}"},
+		{name: `Mapping pci`,
+			input:  "/cluster/mapping/pci/gpu",
+			output: "/cluster/mapping/pci/{id}"},
+		{name: `Mapping usb`,
+			input:  "/cluster/mapping/usb/keyboard",
+			output: "/cluster/mapping/usb/{id}"},
+		{name: `Metrics server`,
+			input:  "/cluster/metrics/server/influx",
+			output: "/cluster/metrics/server/{id}"},
+		{name: `Node network`,
+			input:  "/nodes/pve1/network/vmbr0",
+			output: "/nodes/{node}/network/{iface}"},
+		{name: `Pool`,
+			input:  "/pools/test",
+			output: "/pools/{poolid}"},
+		{name: `Role`,
+			input:  "/access/roles/PVEAdmin",
+			output: "/access/roles/{roleid}"},
+		{name: `SDN dns`,
+			input:  "/cluster/sdn/dns/powerdns",
+			output: "/cluster/sdn/dns/{dns}"},
+		{name: `SDN subnet`,
+			input:  "/cluster/sdn/vnets/vnet0/subnets/zone0-10.0.0.0-24",
+			output: "/cluster/sdn/vnets/{vnet}/subnets/{subnet}"},
+		{name: `SDN zone`,
+			input:  "/cluster/sdn/zones/zone0",
+			output: "/cluster/sdn/zones/{zone}"},
+		{name: `Storage`,
+			input:  "/storage/local",
+			output: "/storage/{storage}"},
+		{name: `User`,
+			input:  "/access/users/root@pam",
+			output: "/access/users/{userid}"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			require.Equal(t, test.output, endpointTemplate(test.input))
+		})
+	}
+}
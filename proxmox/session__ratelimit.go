@@ -0,0 +1,185 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit limits the requests a client makes to the API.
+// Fields left at their zero value are unlimited.
+type RateLimit struct {
+	// Maximum number of requests in flight at the same time, regardless of their kind.
+	MaxInFlight uint
+	// Budget for requests that only read, GET and HEAD.
+	Read RateLimitBudget
+	// Budget for requests that mutate, POST, PUT and DELETE.
+	Write RateLimitBudget
+}
+
+const RateLimit_Error_Read = "invalid read budget: "
+const RateLimit_Error_Write = "invalid write budget: "
+
+func (limit RateLimit) Validate() error {
+	if err := limit.Read.Validate(); err != nil {
+		return errors.New(RateLimit_Error_Read + err.Error())
+	}
+	if err := limit.Write.Validate(); err != nil {
+		return errors.New(RateLimit_Error_Write + err.Error())
+	}
+	return nil
+}
+
+// RateLimitBudget limits one kind of request.
+type RateLimitBudget struct {
+	// Maximum number of requests of this kind in flight at the same time, 0 for unlimited.
+	MaxInFlight uint
+	// Average number of requests of this kind per second, 0 for unlimited.
+	RequestsPerSecond float64
+	// Number of requests that may be made in quick succession before RequestsPerSecond kicks in.
+	// Default: 1
+	Burst uint
+}
+
+const RateLimitBudget_Error_RequestsPerSecond = "requests per second may not be negative"
+
+func (budget RateLimitBudget) Validate() error {
+	if budget.RequestsPerSecond < 0 {
+		return errors.New(RateLimitBudget_Error_RequestsPerSecond)
+	}
+	return nil
+}
+
+// sessionLimiter enforces a RateLimit, it is safe for concurrent use.
+type sessionLimiter struct {
+	inFlight semaphore
+	read     budgetLimiter
+	write    budgetLimiter
+}
+
+func newSessionLimiter(limit RateLimit) *sessionLimiter {
+	return &sessionLimiter{
+		inFlight: newSemaphore(limit.MaxInFlight),
+		read:     newBudgetLimiter(limit.Read),
+		write:    newBudgetLimiter(limit.Write)}
+}
+
+// acquire blocks until the request may be made, or the context is done.
+// When no error is returned, the returned function must be called once the request is done.
+func (l *sessionLimiter) acquire(ctx context.Context, method string) (func(), error) {
+	budget := &l.write
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		budget = &l.read
+	}
+	if err := budget.bucket.wait(ctx); err != nil {
+		return nil, err
+	}
+	if err := budget.inFlight.acquire(ctx); err != nil {
+		return nil, err
+	}
+	if err := l.inFlight.acquire(ctx); err != nil {
+		budget.inFlight.release()
+		return nil, err
+	}
+	return func() {
+		l.inFlight.release()
+		budget.inFlight.release()
+	}, nil
+}
+
+type budgetLimiter struct {
+	bucket   *tokenBucket
+	inFlight semaphore
+}
+
+func newBudgetLimiter(budget RateLimitBudget) budgetLimiter {
+	return budgetLimiter{
+		bucket:   newTokenBucket(budget.RequestsPerSecond, budget.Burst),
+		inFlight: newSemaphore(budget.MaxInFlight)}
+}
+
+// semaphore limits the number of concurrent holders, a nil semaphore is unlimited.
+type semaphore chan struct{}
+
+func newSemaphore(size uint) semaphore {
+	if size == 0 {
+		return nil
+	}
+	return make(semaphore, size)
+}
+
+func (s semaphore) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// tokenBucket allows `rate` requests per second on average with bursts of `burst` requests, a nil tokenBucket is unlimited.
+type tokenBucket struct {
+	mutex  sync.Mutex
+	burst  float64
+	last   time.Time
+	now    func() time.Time
+	rate   float64
+	tokens float64
+}
+
+func newTokenBucket(rate float64, burst uint) *tokenBucket {
+	if rate == 0 {
+		return nil
+	}
+	if burst == 0 {
+		burst = 1
+	}
+	return &tokenBucket{
+		burst:  float64(burst),
+		now:    time.Now,
+		rate:   rate,
+		tokens: float64(burst)}
+}
+
+// reserve takes a token when one is available, otherwise it returns how long to wait for the next token.
+func (b *tokenBucket) reserve() (time.Duration, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second)), false
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	for {
+		delay, ok := b.reserve()
+		if ok {
+			return nil
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
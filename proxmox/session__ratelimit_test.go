@@ -0,0 +1,143 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_SetRateLimit(t *testing.T) {
+	t.Parallel()
+	const path = "/access/users/test@pve/token/testToken"
+	tests := []struct {
+		name     string
+		limit    RateLimit
+		requests []mockServer.Request
+		err      error
+	}{
+		{name: `Unlimited`,
+			requests: mockServer.RequestsDelete(path, nil)},
+		{name: `Limited`,
+			limit: RateLimit{
+				MaxInFlight: 1,
+				Read:        RateLimitBudget{MaxInFlight: 1, RequestsPerSecond: 1000},
+				Write:       RateLimitBudget{MaxInFlight: 1, RequestsPerSecond: 1000}},
+			requests: mockServer.RequestsDelete(path, nil)},
+		{name: `Invalid`,
+			limit: RateLimit{Write: RateLimitBudget{RequestsPerSecond: -1}},
+			err:   errors.New(RateLimit_Error_Write + RateLimitBudget_Error_RequestsPerSecond)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			server, c := testMockServerInit(t)
+			err := c.SetRateLimit(test.limit)
+			require.Equal(t, test.err, err)
+			if err != nil {
+				return
+			}
+			server.Set(test.requests, t)
+			_, err = c.New().ApiToken.Delete(context.Background(), ApiTokenID{
+				User:      UserID{Name: "test", Realm: "pve"},
+				TokenName: "testToken"})
+			require.NoError(t, err)
+			server.Clear(t)
+		})
+	}
+}
+
+func Test_RateLimit_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input RateLimit
+		err   error
+	}{
+		{name: `Valid empty`},
+		{name: `Valid full`,
+			input: RateLimit{
+				MaxInFlight: 10,
+				Read:        RateLimitBudget{MaxInFlight: 8, RequestsPerSecond: 20, Burst: 5},
+				Write:       RateLimitBudget{MaxInFlight: 2, RequestsPerSecond: 0.5, Burst: 1}}},
+		{name: `Invalid Read`,
+			input: RateLimit{Read: RateLimitBudget{RequestsPerSecond: -1}},
+			err:   errors.New(RateLimit_Error_Read + RateLimitBudget_Error_RequestsPerSecond)},
+		{name: `Invalid Write`,
+			input: RateLimit{Write: RateLimitBudget{RequestsPerSecond: -0.1}},
+			err:   errors.New(RateLimit_Error_Write + RateLimitBudget_Error_RequestsPerSecond)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			require.Equal(t, test.err, test.input.Validate())
+		})
+	}
+}
+
+func Test_sessionLimiter_acquire(t *testing.T) {
+	t.Parallel()
+	t.Run(`MaxInFlight shared`, func(t *testing.T) {
+		l := newSessionLimiter(RateLimit{MaxInFlight: 1})
+		done, err := l.acquire(context.Background(), http.MethodGet)
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = l.acquire(ctx, http.MethodPost)
+		require.Equal(t, context.Canceled, err)
+		done()
+		done, err = l.acquire(context.Background(), http.MethodPost)
+		require.NoError(t, err)
+		done()
+	})
+	t.Run(`MaxInFlight per budget`, func(t *testing.T) {
+		l := newSessionLimiter(RateLimit{Write: RateLimitBudget{MaxInFlight: 1}})
+		done, err := l.acquire(context.Background(), http.MethodDelete)
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = l.acquire(ctx, http.MethodPut)
+		require.Equal(t, context.Canceled, err)
+		readDone, err := l.acquire(context.Background(), http.MethodGet)
+		require.NoError(t, err)
+		readDone()
+		done()
+	})
+	t.Run(`RequestsPerSecond context canceled`, func(t *testing.T) {
+		l := newSessionLimiter(RateLimit{Read: RateLimitBudget{RequestsPerSecond: 0.001}})
+		done, err := l.acquire(context.Background(), http.MethodGet)
+		require.NoError(t, err)
+		done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		_, err = l.acquire(ctx, http.MethodGet)
+		require.Equal(t, context.DeadlineExceeded, err)
+	})
+}
+
+func Test_tokenBucket_reserve(t *testing.T) {
+	t.Parallel()
+	start := time.Unix(0, 0)
+	now := start
+	b := newTokenBucket(2, 2)
+	b.now = func() time.Time { return now }
+	reserve := func(delay time.Duration, ok bool) {
+		d, o := b.reserve()
+		require.Equal(t, delay, d)
+		require.Equal(t, ok, o)
+	}
+	reserve(0, true)
+	reserve(0, true)
+	reserve(500*time.Millisecond, false)
+	now = start.Add(250 * time.Millisecond)
+	reserve(250*time.Millisecond, false)
+	now = start.Add(500 * time.Millisecond)
+	reserve(0, true)
+	now = start.Add(time.Hour)
+	reserve(0, true)
+	reserve(0, true)
+	reserve(500*time.Millisecond, false)
+}
@@ -1,8 +1,15 @@
 package proxmox
 
 import (
+	"context"
+	"net/http"
 	"slices"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParamsTo(t *testing.T) {
@@ -95,3 +102,151 @@ func TestParamsToWithEmpty(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseTicket(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		user   string
+		issued time.Time
+	}{
+		{name: `Valid`,
+			input:  "PVE:root@pam:66F2A1B0::c2lnbmF0dXJl",
+			user:   "root@pam",
+			issued: time.Unix(0x66F2A1B0, 0)},
+		{name: `Invalid time`,
+			input: "PVE:root@pam:XYZ::c2lnbmF0dXJl",
+			user:  "root@pam"},
+		{name: `Invalid format`,
+			input: "FAKE_TICKET"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			user, issued := parseTicket(test.input)
+			require.Equal(t, test.user, user)
+			if test.issued.IsZero() {
+				require.WithinDuration(t, time.Now(), issued, time.Minute)
+			} else {
+				require.Equal(t, test.issued, issued)
+			}
+		})
+	}
+}
+
+func Test_Session_ticketRenewal(t *testing.T) {
+	t.Parallel()
+	const path = "/access/users/test@pve/token/testToken"
+	expired := "PVE:root@pam:" + strconv.FormatInt(time.Now().Add(-ticketRenewAfter).Unix(), 16) + "::c2lnbmF0dXJl"
+	fullyExpired := "PVE:root@pam:" + strconv.FormatInt(time.Now().Add(-ticketLifetime).Unix(), 16) + "::c2lnbmF0dXJl"
+	renew := func(password string) []mockServer.Request {
+		return []mockServer.Request{{
+			Path:   sessionTicketPath,
+			Method: mockServer.POST,
+			HandlerFunc: func(w http.ResponseWriter, r *http.Request, t *testing.T) {
+				require.NoError(t, r.ParseForm())
+				require.Equal(t, "root@pam", r.PostForm.Get("username"))
+				require.Equal(t, password, r.PostForm.Get("password"))
+				w.Write([]byte(`{"data":{"ticket":"RENEWED_TICKET","CSRFPreventionToken":"RENEWED_CSRF_TOKEN"}}`))
+			}}}
+	}
+	tests := []struct {
+		name     string
+		ticket   string
+		password string
+		token    bool
+		requests []mockServer.Request
+		renewals []TicketRenewal
+		err      error
+	}{
+		{name: `Not expired`,
+			requests: mockServer.RequestsDelete(path, nil)},
+		{name: `Expired`,
+			ticket: expired,
+			requests: mockServer.Append(
+				renew(expired),
+				mockServer.RequestsDelete(path, nil)),
+			renewals: []TicketRenewal{{User: "root@pam"}}},
+		{name: `Expired renewal failed`,
+			ticket: expired,
+			requests: mockServer.Append(
+				mockServer.RequestsError(sessionTicketPath, mockServer.POST, 500, 1),
+				mockServer.RequestsDelete(path, nil)),
+			renewals: []TicketRenewal{{User: "root@pam", Err: &StatusError{Code: 500, Status: mockServer.InternalServerError}}}},
+		{name: `Fully expired`,
+			ticket:   fullyExpired,
+			password: "secret",
+			requests: mockServer.Append(
+				renew("secret"),
+				mockServer.RequestsDelete(path, nil)),
+			renewals: []TicketRenewal{{User: "root@pam"}}},
+		{name: `Fully expired no credentials`,
+			ticket:   fullyExpired,
+			renewals: []TicketRenewal{{User: "root@pam", Err: Error.LoginRequired()}},
+			err:      Error.LoginRequired()},
+		{name: `Unauthorized renewal rejected`,
+			password: "secret",
+			requests: mockServer.Append(
+				mockServer.RequestsError(path, mockServer.DELETE, 401, 1),
+				mockServer.RequestsErrorHandled(sessionTicketPath, mockServer.POST, mockServer.JsonError(401, map[string]any{"message": "authentication failure"})),
+				renew("secret"),
+				mockServer.RequestsDelete(path, nil)),
+			renewals: []TicketRenewal{{User: "root@pam", Unauthorized: true}}},
+		{name: `Unauthorized`,
+			requests: mockServer.Append(
+				mockServer.RequestsError(path, mockServer.DELETE, 401, 1),
+				renew("FAKE_TICKET"),
+				mockServer.RequestsDelete(path, nil)),
+			renewals: []TicketRenewal{{User: "root@pam", Unauthorized: true}}},
+		{name: `Unauthorized twice`,
+			requests: mockServer.Append(
+				mockServer.RequestsError(path, mockServer.DELETE, 401, 1),
+				renew("FAKE_TICKET"),
+				mockServer.RequestsError(path, mockServer.DELETE, 401, 1)),
+			renewals: []TicketRenewal{{User: "root@pam", Unauthorized: true}},
+			err:      &StatusError{Code: 401, Status: "401 Unauthorized"}},
+		{name: `Unauthorized API token`,
+			token:    true,
+			requests: mockServer.RequestsError(path, mockServer.DELETE, 401, 1),
+			err:      &StatusError{Code: 401, Status: "401 Unauthorized"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			server, c := testMockServerInit(t)
+			c.session.setCredentials("root@pam", test.password, "")
+			if test.ticket != "" {
+				c.SetTicket(test.ticket, "FAKE_CSRF_TOKEN")
+			}
+			if test.token {
+				c.SetAPIToken(ApiToken{
+					ID:     ApiTokenID{User: UserID{Name: "root", Realm: "pam"}, TokenName: "test"},
+					Secret: "secret"})
+			}
+			require.NoError(t, c.SetRetryPolicy(RetryPolicyExponential{Attempts: 1}))
+			var renewals []TicketRenewal
+			c.SetTicketRenewalHook(func(renewal TicketRenewal) {
+				renewal.Issued = time.Time{}
+				renewals = append(renewals, renewal)
+			})
+			server.Set(test.requests, t)
+			_, err := c.New().ApiToken.Delete(context.Background(), ApiTokenID{
+				User:      UserID{Name: "test", Realm: "pve"},
+				TokenName: "testToken"})
+			require.Equal(t, test.err, err)
+			require.Equal(t, test.renewals, renewals)
+			server.Clear(t)
+		})
+	}
+}
+
+func Test_Session_setCredentials(t *testing.T) {
+	t.Parallel()
+	s := &Session{}
+	s.setCredentials("root@pam", "secret", "")
+	require.Equal(t, "root@pam", s.loginUser)
+	require.Equal(t, "secret", s.loginPassword)
+	s.setCredentials("root@pam", "secret", "123456") // one-time passwords can't be reused
+	require.Empty(t, s.loginUser)
+	require.Empty(t, s.loginPassword)
+}
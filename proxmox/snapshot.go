@@ -609,7 +609,7 @@ func (snap SnapshotName) rollback(ctx context.Context, c *clientAPI, vmr VmRef,
 	if start {
 		body = util.Pointer([]byte("start=1"))
 	}
-	return c.postRawRetry(ctx, "/nodes/"+vmr.node.String()+"/"+vmr.vmType.String()+"/"+strconv.FormatInt(int64(vmr.vmId), 10)+"/snapshot/"+string(snap)+"/rollback", body, 3)
+	return c.postRawRetry(ctx, "/nodes/"+vmr.node.String()+"/"+vmr.vmType.String()+"/"+strconv.FormatInt(int64(vmr.vmId), 10)+"/snapshot/"+string(snap)+"/rollback", body)
 }
 
 // Deprecated use SnapshotInterface.Rollback() instead
@@ -630,7 +630,7 @@ func (snap SnapshotName) RollbackNoCheck(ctx context.Context, c *Client, vmr *Vm
 func (snap SnapshotName) String() string { return string(snap) } // for fmt.Stringer
 
 func (snap SnapshotName) update(ctx context.Context, c *clientAPI, vmr VmRef, description string) error {
-	return c.putRawRetry(ctx, "/nodes/"+vmr.node.String()+"/"+vmr.vmType.String()+"/"+vmr.vmId.String()+"/snapshot/"+string(snap)+"/config", util.Pointer([]byte(snapshotApiKeyDescription+"="+body.Escape(description))), 3)
+	return c.putRawRetry(ctx, "/nodes/"+vmr.node.String()+"/"+vmr.vmType.String()+"/"+vmr.vmId.String()+"/snapshot/"+string(snap)+"/config", util.Pointer([]byte(snapshotApiKeyDescription+"="+body.Escape(description))))
 }
 
 // Deprecated use SnapshotInterface.Update() instead
@@ -71,12 +71,12 @@ func Test_snapshotClient_CreateLxc(t *testing.T) {
 			guest:        VmRef{vmId: 100},
 			snapshotName: SnapshotName("mySnap"),
 			requests:     mockServer.RequestsError("/cluster/resources?type=vm", mockServer.GET, 500, 3),
-			err:          errors.New(mockServer.InternalServerError)},
+			err:          &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Error Creating snapshot`,
 			guest:        VmRef{vmId: 100, node: "testNode"},
 			snapshotName: SnapshotName("snap1"),
 			requests:     mockServer.RequestsError("/nodes/testNode/lxc/100/snapshot", mockServer.POST, 500, 3),
-			err:          errors.New(mockServer.InternalServerError)},
+			err:          &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -149,12 +149,12 @@ func Test_snapshotClient_CreateQemu(t *testing.T) {
 			guest:        VmRef{vmId: 100},
 			snapshotName: SnapshotName("mySnap"),
 			requests:     mockServer.RequestsError("/cluster/resources?type=vm", mockServer.GET, 500, 3),
-			err:          errors.New(mockServer.InternalServerError)},
+			err:          &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Error Creating snapshot`,
 			guest:        VmRef{vmId: 100, node: "testNode"},
 			snapshotName: SnapshotName("snap1"),
 			requests:     mockServer.RequestsError("/nodes/testNode/qemu/100/snapshot", mockServer.POST, 500, 3),
-			err:          errors.New(mockServer.InternalServerError)},
+			err:          &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -219,12 +219,12 @@ func Test_snapshotClient_Delete(t *testing.T) {
 			guest:        VmRef{vmId: 100},
 			snapshotName: SnapshotName("mySnap"),
 			requests:     mockServer.RequestsError("/cluster/resources?type=vm", mockServer.GET, 500, 3),
-			err:          errors.New(mockServer.InternalServerError)},
+			err:          &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Error Deleting snapshot`,
 			guest:        VmRef{vmId: 100, node: "testNode", vmType: GuestQemu},
 			snapshotName: SnapshotName("snap2"),
 			requests:     mockServer.RequestsError("/nodes/testNode/qemu/100/snapshot/snap2", mockServer.DELETE, 500, 3),
-			err:          errors.New(mockServer.InternalServerError)},
+			err:          &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -335,14 +335,14 @@ func Test_snapshotClient_List(t *testing.T) {
 		{name: `Error Listing VMs`,
 			guest:    VmRef{vmId: 100, node: "testNode", vmType: GuestLxc},
 			requests: mockServer.RequestsError("/nodes/testNode/lxc/100/snapshot", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Error getting VmRef`,
 			guest: VmRef{vmId: 200},
 			snapshots: []SnapshotInfo{
 				{Name: "current",
 					Description: "You are here!"}},
 			requests: mockServer.RequestsError("/cluster/resources?type=vm", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -443,12 +443,12 @@ func Test_snapshotClient_ReadLxc(t *testing.T) {
 			guest:    VmRef{vmId: 200},
 			snapName: SnapshotName("snap1"),
 			requests: mockServer.RequestsError("/cluster/resources?type=vm", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Error during config read`,
 			guest:    VmRef{vmId: 100, node: "testNode", vmType: GuestLxc},
 			snapName: SnapshotName("snap1"),
 			requests: mockServer.RequestsError("/nodes/testNode/lxc/100/snapshot/snap1/config", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -470,9 +470,6 @@ func Test_snapshotClient_ReadQemu(t *testing.T) {
 		if c.Bios == "" {
 			c.Bios = "seabios"
 		}
-		if c.Boot == "" {
-			c.Boot = "cdn"
-		}
 		if c.CPU == nil {
 			c.CPU = &QemuCPU{
 				Cores:   new(QemuCpuCores(1)),
@@ -482,8 +479,8 @@ func Test_snapshotClient_ReadQemu(t *testing.T) {
 		if c.Description == nil {
 			c.Description = util.Pointer("")
 		}
-		if c.Hotplug == "" {
-			c.Hotplug = "network,disk,usb"
+		if c.Hotplug == nil {
+			c.Hotplug = new(qemuHotplugDefault())
 		}
 		if c.Memory == nil {
 			c.Memory = &QemuMemory{}
@@ -500,14 +497,8 @@ func Test_snapshotClient_ReadQemu(t *testing.T) {
 		if c.QemuOs == "" {
 			c.QemuOs = "other"
 		}
-		if c.QemuUnusedDisks == nil {
-			c.QemuUnusedDisks = QemuDevices{}
-		}
-		if c.QemuVga == nil {
-			c.QemuVga = QemuDevice{}
-		}
-		if c.Scsihw == "" {
-			c.Scsihw = "lsi"
+		if c.ScsiController == nil {
+			c.ScsiController = new(QemuScsiControllerLsi)
 		}
 		if c.StartAtNodeBoot == nil {
 			c.StartAtNodeBoot = util.Pointer(false)
@@ -566,12 +557,12 @@ func Test_snapshotClient_ReadQemu(t *testing.T) {
 			guest:    VmRef{vmId: 200},
 			snapName: SnapshotName("snap1"),
 			requests: mockServer.RequestsError("/cluster/resources?type=vm", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Error during config read`,
 			guest:    VmRef{vmId: 100, node: "testNode", vmType: GuestQemu},
 			snapName: SnapshotName("snap1"),
 			requests: mockServer.RequestsError("/nodes/testNode/qemu/100/snapshot/snap1/config", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -626,12 +617,12 @@ func Test_snapshotClient_Rollback(t *testing.T) {
 			guest:    VmRef{vmId: 200},
 			snapName: SnapshotName("snap1"),
 			requests: mockServer.RequestsError("/cluster/resources?type=vm", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Error during Rollback`,
 			guest:    VmRef{vmId: 100, node: "testNode", vmType: GuestQemu},
 			snapName: SnapshotName("snap1"),
 			requests: mockServer.RequestsError("/nodes/testNode/qemu/100/snapshot/snap1/rollback", mockServer.POST, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -685,13 +676,13 @@ func Test_snapshotClient_Update(t *testing.T) {
 			guest:    VmRef{vmId: 200},
 			snapName: SnapshotName("snap1"),
 			requests: mockServer.RequestsError("/cluster/resources?type=vm", mockServer.GET, 500, 3),
-			err:      errors.New(mockServer.InternalServerError)},
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 		{name: `Error during Update`,
 			guest:       VmRef{vmId: 100, node: "testNode", vmType: GuestQemu},
 			snapName:    SnapshotName("snap1"),
 			description: "update description",
 			requests:    mockServer.RequestsError("/nodes/testNode/qemu/100/snapshot/snap1/config", mockServer.PUT, 500, 3),
-			err:         errors.New(mockServer.InternalServerError)},
+			err:         &StatusError{Code: 500, Status: mockServer.InternalServerError}},
 	}
 	server, c := testMockServerInit(t)
 	for _, test := range tests {
@@ -0,0 +1,418 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	TaskInterface interface {
+		// Cancel stops a running task, the same as pressing "Stop" in the web interface.
+		Cancel(context.Context, UPID) error
+		CancelNoCheck(context.Context, UPID) error
+
+		// Log returns the log lines of the task after line number `start`.
+		// To read the log incrementally, pass the `Number` of the last line received.
+		// A `limit` of 0 uses the default of the API (50 lines).
+		Log(ctx context.Context, upid UPID, start, limit uint) ([]TaskLogLine, error)
+		LogNoCheck(ctx context.Context, upid UPID, start, limit uint) ([]TaskLogLine, error)
+
+		Read(context.Context, UPID) (RawTaskStatus, error)
+		ReadNoCheck(context.Context, UPID) (RawTaskStatus, error)
+
+		// Wait polls the task until it has finished or the context is done.
+		// When the task finished unsuccessfully a *TaskError is returned alongside the status.
+		// When backoff is nil the default polling behavior is used.
+		Wait(ctx context.Context, upid UPID, backoff *TaskBackoff) (RawTaskStatus, error)
+		WaitNoCheck(ctx context.Context, upid UPID, backoff *TaskBackoff) (RawTaskStatus, error)
+	}
+
+	taskClient struct {
+		api       *clientAPI
+		oldClient *Client
+	}
+)
+
+var _ TaskInterface = (*taskClient)(nil)
+
+func (c *taskClient) Cancel(ctx context.Context, upid UPID) error {
+	if err := upid.Validate(); err != nil {
+		return err
+	}
+	return c.CancelNoCheck(ctx, upid)
+}
+
+func (c *taskClient) CancelNoCheck(ctx context.Context, upid UPID) error {
+	return c.api.cancelTask(ctx, upid)
+}
+
+func (c *taskClient) Log(ctx context.Context, upid UPID, start, limit uint) ([]TaskLogLine, error) {
+	if err := upid.Validate(); err != nil {
+		return nil, err
+	}
+	return c.LogNoCheck(ctx, upid, start, limit)
+}
+
+func (c *taskClient) LogNoCheck(ctx context.Context, upid UPID, start, limit uint) ([]TaskLogLine, error) {
+	return upid.log(ctx, c.api, start, limit)
+}
+
+func (c *taskClient) Read(ctx context.Context, upid UPID) (RawTaskStatus, error) {
+	if err := upid.Validate(); err != nil {
+		return nil, err
+	}
+	return c.ReadNoCheck(ctx, upid)
+}
+
+func (c *taskClient) ReadNoCheck(ctx context.Context, upid UPID) (RawTaskStatus, error) {
+	raw, err := upid.read(ctx, c.api)
+	if err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (c *taskClient) Wait(ctx context.Context, upid UPID, backoff *TaskBackoff) (RawTaskStatus, error) {
+	if err := upid.Validate(); err != nil {
+		return nil, err
+	}
+	if backoff != nil {
+		if err := backoff.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return c.WaitNoCheck(ctx, upid, backoff)
+}
+
+func (c *taskClient) WaitNoCheck(ctx context.Context, upid UPID, backoff *TaskBackoff) (RawTaskStatus, error) {
+	settings := defaultTaskBackoff(time.Second)
+	if backoff != nil {
+		settings = backoff.combine(settings)
+	}
+	raw, err := upid.wait(ctx, c.api, settings)
+	if raw == nil {
+		return nil, err
+	}
+	return raw, err
+}
+
+// TaskBackoff configures how often the status of a task is polled while waiting for it.
+// The delay between polls starts at `Initial` and is multiplied by `Multiplier` after every poll, until it reaches `Maximum`.
+// Fields left at their zero value use the defaults.
+type TaskBackoff struct {
+	Initial    time.Duration `json:"initial,omitempty"`    // Default: 500ms
+	Maximum    time.Duration `json:"maximum,omitempty"`    // Default: 2s
+	Multiplier float64       `json:"multiplier,omitempty"` // Default: 1.5, minimum 1
+}
+
+const (
+	TaskBackoff_Error_Initial    = "initial task backoff may not be negative"
+	TaskBackoff_Error_Maximum    = "maximum task backoff may not be negative"
+	TaskBackoff_Error_MaxInitial = "maximum task backoff may not be smaller than the initial task backoff"
+	TaskBackoff_Error_Multiplier = "task backoff multiplier must be 1 or greater"
+)
+
+// returns the default backoff, scaled by the time unit so tests don't have to wait.
+func defaultTaskBackoff(timeUnit time.Duration) TaskBackoff {
+	return TaskBackoff{
+		Initial:    timeUnit / 2,
+		Maximum:    TaskStatusCheckInterval * timeUnit,
+		Multiplier: 1.5}
+}
+
+func (b TaskBackoff) combine(defaults TaskBackoff) TaskBackoff {
+	if b.Initial != 0 {
+		defaults.Initial = b.Initial
+	}
+	if b.Maximum != 0 {
+		defaults.Maximum = b.Maximum
+	}
+	if b.Multiplier != 0 {
+		defaults.Multiplier = b.Multiplier
+	}
+	if defaults.Maximum < defaults.Initial {
+		defaults.Maximum = defaults.Initial
+	}
+	return defaults
+}
+
+func (b TaskBackoff) next(current time.Duration) time.Duration {
+	next := time.Duration(float64(current) * b.Multiplier)
+	if next > b.Maximum {
+		return b.Maximum
+	}
+	return next
+}
+
+func (b TaskBackoff) Validate() error {
+	if b.Initial < 0 {
+		return errors.New(TaskBackoff_Error_Initial)
+	}
+	if b.Maximum < 0 {
+		return errors.New(TaskBackoff_Error_Maximum)
+	}
+	if b.Initial != 0 && b.Maximum != 0 && b.Maximum < b.Initial {
+		return errors.New(TaskBackoff_Error_MaxInitial)
+	}
+	if b.Multiplier != 0 && b.Multiplier < 1 {
+		return errors.New(TaskBackoff_Error_Multiplier)
+	}
+	return nil
+}
+
+type TaskLogLine struct {
+	Number uint   `json:"number"`
+	Text   string `json:"text"`
+}
+
+func (TaskLogLine) mapToSDK(params map[string]any) TaskLogLine {
+	var line TaskLogLine
+	if v, isSet := params[taskApiKeyLogNumber]; isSet {
+		line.Number = uint(v.(float64))
+	}
+	if v, isSet := params[taskApiKeyLogText]; isSet {
+		line.Text = v.(string)
+	}
+	return line
+}
+
+// Enum
+type TaskState uint8
+
+const (
+	TaskStateUnknown TaskState = 0
+	TaskStateRunning TaskState = 1
+	TaskStateStopped TaskState = 2
+)
+
+func (TaskState) parse(state string) TaskState {
+	switch state {
+	case "running":
+		return TaskStateRunning
+	case "stopped":
+		return TaskStateStopped
+	default:
+		return TaskStateUnknown
+	}
+}
+
+func (state TaskState) String() string { // String is for fmt.Stringer.
+	switch state {
+	case TaskStateRunning:
+		return "running"
+	case TaskStateStopped:
+		return "stopped"
+	default:
+		return ""
+	}
+}
+
+type TaskStatus struct {
+	ExitStatus string    `json:"exit_status,omitempty"`
+	State      TaskState `json:"state"`
+	UPID       UPID      `json:"upid"`
+}
+
+type (
+	RawTaskStatus interface {
+		Get() TaskStatus
+		GetExitStatus() string
+		GetState() TaskState
+		GetUPID() UPID
+		// Finished returns true when the task is no longer running.
+		Finished() bool
+		// Successful returns true when the task finished with the status "OK" or with warnings.
+		Successful() bool
+	}
+
+	rawTaskStatus struct {
+		a    map[string]any
+		upid UPID
+	}
+)
+
+var _ RawTaskStatus = (*rawTaskStatus)(nil)
+
+func (raw *rawTaskStatus) Get() TaskStatus {
+	return TaskStatus{
+		ExitStatus: raw.GetExitStatus(),
+		State:      raw.GetState(),
+		UPID:       raw.GetUPID()}
+}
+
+func (raw *rawTaskStatus) GetExitStatus() string {
+	if v, isSet := raw.a[taskApiKeyExitStatus]; isSet {
+		return v.(string)
+	}
+	return ""
+}
+
+func (raw *rawTaskStatus) GetState() TaskState {
+	if v, isSet := raw.a[taskApiKeyStatus]; isSet {
+		return TaskState(0).parse(v.(string))
+	}
+	if _, isSet := raw.a[taskApiKeyExitStatus]; isSet {
+		return TaskStateStopped
+	}
+	return TaskStateUnknown
+}
+
+func (raw *rawTaskStatus) GetUPID() UPID { return raw.upid }
+
+func (raw *rawTaskStatus) Finished() bool {
+	if _, isSet := raw.a[taskApiKeyExitStatus]; isSet {
+		return true
+	}
+	return raw.GetState() == TaskStateStopped
+}
+
+func (raw *rawTaskStatus) Successful() bool {
+	exitStatus := raw.GetExitStatus()
+	return exitStatus == exitStatusSuccess || strings.HasPrefix(exitStatus, taskExitStatusWarning)
+}
+
+func (raw *rawTaskStatus) err() error {
+	if raw.Successful() {
+		return nil
+	}
+	return &TaskError{
+		Message: raw.GetExitStatus(),
+		TaskID:  raw.upid.String()}
+}
+
+// UPID is the unique process ID Proxmox VE assigns to every task.
+// Format: UPID:{node}:{pid}:{pstart}:{starttime}:{type}:{id}:{user}:
+type UPID struct {
+	Node      NodeName     `json:"node"`
+	PID       uint32       `json:"pid"`
+	PStart    uint64       `json:"pstart"` // Start time of the process in clock ticks since boot.
+	StartTime time.Time    `json:"start_time"`
+	Type      string       `json:"type"` // e.g. qmstart, qmclone, vzdump
+	ID        string       `json:"id,omitempty"`
+	User      UserID       `json:"user"`
+	Token     ApiTokenName `json:"token,omitempty"` // Set when the task was started with an API token.
+}
+
+const (
+	UPID_Error_Invalid   = "invalid UPID, expected format UPID:node:pid:pstart:starttime:type:id:user:"
+	UPID_Error_TypeEmpty = "UPID task type may not be empty"
+)
+
+const upidPrefix = "UPID:"
+
+// Parses a UPID string as returned by the API.
+func (upid *UPID) Parse(s string) error {
+	if !strings.HasPrefix(s, upidPrefix) || !strings.HasSuffix(s, ":") {
+		return errors.New(UPID_Error_Invalid)
+	}
+	fields := strings.Split(s[len(upidPrefix):len(s)-1], ":")
+	if len(fields) != 7 || fields[0] == "" || fields[4] == "" {
+		return errors.New(UPID_Error_Invalid)
+	}
+	pid, err := strconv.ParseUint(fields[1], 16, 32)
+	if err != nil {
+		return errors.New(UPID_Error_Invalid)
+	}
+	pStart, err := strconv.ParseUint(fields[2], 16, 64)
+	if err != nil {
+		return errors.New(UPID_Error_Invalid)
+	}
+	startTime, err := strconv.ParseInt(fields[3], 16, 64)
+	if err != nil {
+		return errors.New(UPID_Error_Invalid)
+	}
+	var user UserID
+	var token ApiTokenName
+	if index := strings.IndexRune(fields[6], '!'); index != -1 {
+		token = ApiTokenName(fields[6][index+1:])
+		fields[6] = fields[6][:index]
+	}
+	if err = user.Parse(fields[6]); err != nil {
+		return errors.New(UPID_Error_Invalid)
+	}
+	*upid = UPID{
+		Node:      NodeName(fields[0]),
+		PID:       uint32(pid),
+		PStart:    pStart,
+		StartTime: time.Unix(startTime, 0).UTC(),
+		Type:      fields[4],
+		ID:        fields[5],
+		User:      user,
+		Token:     token}
+	return nil
+}
+
+func (upid UPID) String() string { // String is for fmt.Stringer.
+	user := upid.User.String()
+	if upid.Token != "" {
+		user += "!" + upid.Token.String()
+	}
+	return fmt.Sprintf(upidPrefix+"%s:%08X:%08X:%08X:%s:%s:%s:",
+		upid.Node, upid.PID, upid.PStart, upid.StartTime.Unix(), upid.Type, upid.ID, user)
+}
+
+func (upid UPID) Validate() error {
+	if err := upid.Node.Validate(); err != nil {
+		return err
+	}
+	if upid.Type == "" {
+		return errors.New(UPID_Error_TypeEmpty)
+	}
+	return upid.User.Validate()
+}
+
+func (upid UPID) log(ctx context.Context, c clientApiInterface, start, limit uint) ([]TaskLogLine, error) {
+	rawLines, err := c.getTaskLog(ctx, upid, start, limit)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]TaskLogLine, len(rawLines))
+	for i := range rawLines {
+		lines[i] = TaskLogLine{}.mapToSDK(rawLines[i].(map[string]any))
+	}
+	return lines, nil
+}
+
+func (upid UPID) read(ctx context.Context, c clientApiInterface) (*rawTaskStatus, error) {
+	status, err := c.getTaskStatus(ctx, upid)
+	if err != nil {
+		return nil, err
+	}
+	return &rawTaskStatus{a: status, upid: upid}, nil
+}
+
+// wait polls the task status until the task has finished or the context is done.
+func (upid UPID) wait(ctx context.Context, c clientApiInterface, backoff TaskBackoff) (*rawTaskStatus, error) {
+	delay := backoff.Initial
+	for {
+		raw, err := upid.read(ctx, c)
+		if err != nil {
+			if !errors.Is(err, io.ErrUnexpectedEOF) { // Early EOF can happen, keep polling
+				return nil, err
+			}
+		} else if raw.Finished() {
+			c.taskFinished(upid)
+			return raw, raw.err()
+		}
+		select {
+		case <-ctx.Done():
+			return raw, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = backoff.next(delay)
+	}
+}
+
+const (
+	taskApiKeyExitStatus string = "exitstatus"
+	taskApiKeyLogNumber  string = "n"
+	taskApiKeyLogText    string = "t"
+	taskApiKeyStatus     string = "status"
+)
+
+const taskExitStatusWarning = "WARNING"
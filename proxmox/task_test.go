@@ -0,0 +1,368 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_taskClient_Cancel(t *testing.T) {
+	t.Parallel()
+	upid := UPID{Node: "pve1", PID: 451787, PStart: 398255913, StartTime: time.Unix(1769119884, 0).UTC(), Type: "qmclone", ID: "100", User: UserID{Name: "root", Realm: "pam"}}
+	path := mockServer.Path("/nodes/pve1/tasks/" + upid.String())
+	tests := []struct {
+		name     string
+		upid     UPID
+		requests []mockServer.Request
+		err      error
+	}{
+		{name: `Cancel`,
+			upid:     upid,
+			requests: mockServer.RequestsDelete(path, nil)},
+		{name: `Error validate`,
+			upid: UPID{Node: "pve1"},
+			err:  errors.New(UPID_Error_TypeEmpty)},
+		{name: `500 internal server error`,
+			upid:     upid,
+			requests: mockServer.RequestsError(path, mockServer.DELETE, 500, 3),
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
+	}
+	server, c := testMockServerInit(t)
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			server.Set(test.requests, t)
+			err := c.New().Task.Cancel(context.Background(), test.upid)
+			require.Equal(t, test.err, err)
+			server.Clear(t)
+		})
+	}
+}
+
+func Test_taskClient_Log(t *testing.T) {
+	t.Parallel()
+	upid := UPID{Node: "pve1", PID: 451787, PStart: 398255913, StartTime: time.Unix(1769119884, 0).UTC(), Type: "vzdump", User: UserID{Name: "root", Realm: "pam"}}
+	path := "/nodes/pve1/tasks/" + upid.String() + "/log"
+	tests := []struct {
+		name     string
+		start    uint
+		limit    uint
+		output   []TaskLogLine
+		requests []mockServer.Request
+		err      error
+	}{
+		{name: `Log all`,
+			requests: mockServer.RequestsGetJsonData(mockServer.Path(path+"?start=0"), []any{
+				map[string]any{"n": float64(1), "t": "INFO: starting new backup job"},
+				map[string]any{"n": float64(2), "t": "INFO: Finished Backup of VM 100"}}),
+			output: []TaskLogLine{
+				{Number: 1, Text: "INFO: starting new backup job"},
+				{Number: 2, Text: "INFO: Finished Backup of VM 100"}}},
+		{name: `Log incremental`,
+			start: 2,
+			limit: 10,
+			requests: mockServer.RequestsGetJsonData(mockServer.Path(path+"?start=2&limit=10"), []any{
+				map[string]any{"n": float64(3), "t": "TASK OK"}}),
+			output: []TaskLogLine{{Number: 3, Text: "TASK OK"}}},
+		{name: `500 internal server error`,
+			requests: mockServer.RequestsError(mockServer.Path(path+"?start=0"), mockServer.GET, 500, 3),
+			err:      &StatusError{Code: 500, Status: mockServer.InternalServerError}},
+	}
+	server, c := testMockServerInit(t)
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			server.Set(test.requests, t)
+			lines, err := c.New().Task.Log(context.Background(), upid, test.start, test.limit)
+			require.Equal(t, test.err, err)
+			require.Equal(t, test.output, lines)
+			server.Clear(t)
+		})
+	}
+}
+
+func Test_taskClient_Read(t *testing.T) {
+	t.Parallel()
+	upid := UPID{Node: "pve1", PID: 451787, PStart: 398255913, StartTime: time.Unix(1769119884, 0).UTC(), Type: "qmstart", ID: "100", User: UserID{Name: "root", Realm: "pam"}}
+	path := mockServer.Path("/nodes/pve1/tasks/" + upid.String() + "/status")
+	tests := []struct {
+		name     string
+		upid     UPID
+		output   TaskStatus
+		requests []mockServer.Request
+		err      error
+	}{
+		{name: `Running`,
+			upid:     upid,
+			requests: mockServer.RequestsGetJsonData(path, map[string]any{"status": "running"}),
+			output:   TaskStatus{State: TaskStateRunning, UPID: upid}},
+		{name: `Stopped`,
+			upid:     upid,
+			requests: mockServer.RequestsGetJsonData(path, map[string]any{"status": "stopped", "exitstatus": "OK"}),
+			output:   TaskStatus{State: TaskStateStopped, ExitStatus: "OK", UPID: upid}},
+		{name: `Error validate`,
+			upid: UPID{},
+			err:  errors.New(NodeName_Error_Empty)},
+	}
+	server, c := testMockServerInit(t)
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			server.Set(test.requests, t)
+			raw, err := c.New().Task.Read(context.Background(), test.upid)
+			require.Equal(t, test.err, err)
+			if err == nil {
+				require.Equal(t, test.output, raw.Get())
+			} else {
+				require.Nil(t, raw)
+			}
+			server.Clear(t)
+		})
+	}
+}
+
+func Test_taskClient_Wait(t *testing.T) {
+	t.Parallel()
+	upid := UPID{Node: "pve1", PID: 451787, PStart: 398255913, StartTime: time.Unix(1769119884, 0).UTC(), Type: "qmclone", ID: "100", User: UserID{Name: "root", Realm: "pam"}}
+	path := mockServer.Path("/nodes/pve1/tasks/" + upid.String() + "/status")
+	backoff := &TaskBackoff{Initial: time.Nanosecond, Maximum: time.Nanosecond}
+	tests := []struct {
+		name     string
+		backoff  *TaskBackoff
+		output   TaskStatus
+		requests []mockServer.Request
+		err      error
+	}{
+		{name: `Finished after polling`,
+			backoff: backoff,
+			requests: mockServer.Append(
+				mockServer.RequestsGetJsonData(path, map[string]any{"status": "running"}),
+				mockServer.RequestsGetJsonData(path, map[string]any{"status": "running"}),
+				mockServer.RequestsGetJsonData(path, map[string]any{"status": "stopped", "exitstatus": "OK"})),
+			output: TaskStatus{State: TaskStateStopped, ExitStatus: "OK", UPID: upid}},
+		{name: `Finished with warnings`,
+			requests: mockServer.RequestsGetJsonData(path, map[string]any{"status": "stopped", "exitstatus": "WARNINGS: 1"}),
+			output:   TaskStatus{State: TaskStateStopped, ExitStatus: "WARNINGS: 1", UPID: upid}},
+		{name: `Failed`,
+			requests: mockServer.RequestsGetJsonData(path, map[string]any{"status": "stopped", "exitstatus": "clone failed"}),
+			output:   TaskStatus{State: TaskStateStopped, ExitStatus: "clone failed", UPID: upid},
+			err:      &TaskError{TaskID: upid.String(), Message: "clone failed"}},
+		{name: `Error backoff`,
+			backoff: &TaskBackoff{Multiplier: 0.5},
+			err:     errors.New(TaskBackoff_Error_Multiplier)},
+	}
+	server, c := testMockServerInit(t)
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			server.Set(test.requests, t)
+			raw, err := c.New().Task.Wait(context.Background(), upid, test.backoff)
+			require.Equal(t, test.err, err)
+			if raw != nil {
+				require.Equal(t, test.output, raw.Get())
+			}
+			server.Clear(t)
+		})
+	}
+}
+
+func Test_Client_WaitForCompletion(t *testing.T) {
+	t.Parallel()
+	upid := UPID{Node: "pve1", PID: 451787, PStart: 398255913, StartTime: time.Unix(1769119884, 0).UTC(), Type: "qmclone", ID: "100", User: UserID{Name: "root", Realm: "pam"}}
+	path := mockServer.Path("/nodes/pve1/tasks/" + upid.String() + "/status")
+	tests := []struct {
+		name     string
+		requests []mockServer.Request
+		output   string
+		err      error
+	}{
+		{name: `OK`,
+			requests: mockServer.RequestsGetJsonData(path, map[string]any{"status": "stopped", "exitstatus": "OK"}),
+			output:   "OK"},
+		{name: `Warnings`,
+			requests: mockServer.RequestsGetJsonData(path, map[string]any{"status": "stopped", "exitstatus": "WARNINGS: 1"}),
+			output:   "WARNINGS: 1"},
+		{name: `Failed`,
+			requests: mockServer.RequestsGetJsonData(path, map[string]any{"status": "stopped", "exitstatus": "clone failed"}),
+			err:      errors.New("clone failed")},
+	}
+	server, c := testMockServerInit(t)
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			server.Set(test.requests, t)
+			exitStatus, err := c.WaitForCompletion(context.Background(), map[string]any{"data": upid.String()})
+			require.Equal(t, test.err, err)
+			require.Equal(t, test.output, exitStatus)
+			server.Clear(t)
+		})
+	}
+}
+
+func Test_UPID_wait_context(t *testing.T) {
+	t.Parallel()
+	upid := UPID{Node: "pve1", Type: "qmclone", User: UserID{Name: "root", Realm: "pam"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	var polls int
+	raw, err := upid.wait(ctx, mockClientAPI{
+		getTaskStatusFunc: func(context.Context, UPID) (map[string]any, error) {
+			polls++
+			cancel()
+			return map[string]any{"status": "running"}, nil
+		},
+	}.new(), TaskBackoff{Initial: time.Hour, Maximum: time.Hour, Multiplier: 1})
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, 1, polls)
+	require.Equal(t, TaskStateRunning, raw.GetState())
+}
+
+func Test_TaskBackoff_combine(t *testing.T) {
+	t.Parallel()
+	defaults := defaultTaskBackoff(time.Second)
+	tests := []struct {
+		name   string
+		input  TaskBackoff
+		output TaskBackoff
+	}{
+		{name: `empty`,
+			output: defaults},
+		{name: `full`,
+			input:  TaskBackoff{Initial: time.Second, Maximum: 10 * time.Second, Multiplier: 2},
+			output: TaskBackoff{Initial: time.Second, Maximum: 10 * time.Second, Multiplier: 2}},
+		{name: `initial larger than default maximum`,
+			input:  TaskBackoff{Initial: 5 * time.Second},
+			output: TaskBackoff{Initial: 5 * time.Second, Maximum: 5 * time.Second, Multiplier: defaults.Multiplier}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			require.Equal(t, test.output, test.input.combine(defaults))
+		})
+	}
+}
+
+func Test_TaskBackoff_next(t *testing.T) {
+	t.Parallel()
+	backoff := TaskBackoff{Initial: time.Second, Maximum: 3 * time.Second, Multiplier: 2}
+	require.Equal(t, 2*time.Second, backoff.next(time.Second))
+	require.Equal(t, 3*time.Second, backoff.next(2*time.Second))
+}
+
+func Test_TaskBackoff_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input TaskBackoff
+		err   error
+	}{
+		{name: `Valid empty`},
+		{name: `Valid full`,
+			input: TaskBackoff{Initial: time.Second, Maximum: time.Minute, Multiplier: 1}},
+		{name: `Invalid Initial`,
+			input: TaskBackoff{Initial: -1},
+			err:   errors.New(TaskBackoff_Error_Initial)},
+		{name: `Invalid Maximum`,
+			input: TaskBackoff{Maximum: -1},
+			err:   errors.New(TaskBackoff_Error_Maximum)},
+		{name: `Invalid Maximum smaller than Initial`,
+			input: TaskBackoff{Initial: time.Minute, Maximum: time.Second},
+			err:   errors.New(TaskBackoff_Error_MaxInitial)},
+		{name: `Invalid Multiplier`,
+			input: TaskBackoff{Multiplier: 0.9},
+			err:   errors.New(TaskBackoff_Error_Multiplier)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			require.Equal(t, test.err, test.input.Validate())
+		})
+	}
+}
+
+func Test_TaskState_String(t *testing.T) {
+	t.Parallel()
+	for _, state := range []TaskState{TaskStateRunning, TaskStateStopped} {
+		require.Equal(t, state, TaskState(0).parse(state.String()))
+	}
+	require.Equal(t, TaskStateUnknown, TaskState(0).parse("unknown_Fallback_value"))
+	require.Equal(t, "", TaskStateUnknown.String())
+}
+
+func Test_UPID_Parse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		output UPID
+		err    error
+	}{
+		{name: `Valid user`,
+			input: "UPID:pve-01:00068180:17BE8318:697285F2:qmdelsnapshot:801:root@pam:",
+			output: UPID{
+				Node:      "pve-01",
+				PID:       0x68180,
+				PStart:    0x17BE8318,
+				StartTime: time.Unix(0x697285F2, 0).UTC(),
+				Type:      "qmdelsnapshot",
+				ID:        "801",
+				User:      UserID{Name: "root", Realm: "pam"}}},
+		{name: `Valid token no id`,
+			input: "UPID:pve1:0006E4CB:17C8E729:6972A08C:aptupdate::automation@pve!ci-token:",
+			output: UPID{
+				Node:      "pve1",
+				PID:       0x6E4CB,
+				PStart:    0x17C8E729,
+				StartTime: time.Unix(0x6972A08C, 0).UTC(),
+				Type:      "aptupdate",
+				User:      UserID{Name: "automation", Realm: "pve"},
+				Token:     "ci-token"}},
+		{name: `Invalid empty`,
+			err: errors.New(UPID_Error_Invalid)},
+		{name: `Invalid prefix`,
+			input: "PID:pve1:0006E4CB:17C8E729:6972A08C:qmstart:100:root@pam:",
+			err:   errors.New(UPID_Error_Invalid)},
+		{name: `Invalid missing field`,
+			input: "UPID:pve1:0006E4CB:17C8E729:qmstart:100:root@pam:",
+			err:   errors.New(UPID_Error_Invalid)},
+		{name: `Invalid pid`,
+			input: "UPID:pve1:XXXXXXXX:17C8E729:6972A08C:qmstart:100:root@pam:",
+			err:   errors.New(UPID_Error_Invalid)},
+		{name: `Invalid user`,
+			input: "UPID:pve1:0006E4CB:17C8E729:6972A08C:qmstart:100:root:",
+			err:   errors.New(UPID_Error_Invalid)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			var upid UPID
+			err := upid.Parse(test.input)
+			require.Equal(t, test.err, err)
+			require.Equal(t, test.output, upid)
+			if err == nil {
+				require.Equal(t, test.input, upid.String())
+			}
+		})
+	}
+}
+
+func Test_UPID_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input UPID
+		err   error
+	}{
+		{name: `Valid`,
+			input: UPID{Node: "pve1", Type: "qmstart", User: UserID{Name: "root", Realm: "pam"}}},
+		{name: `Invalid Node`,
+			input: UPID{Type: "qmstart", User: UserID{Name: "root", Realm: "pam"}},
+			err:   errors.New(NodeName_Error_Empty)},
+		{name: `Invalid Type`,
+			input: UPID{Node: "pve1", User: UserID{Name: "root", Realm: "pam"}},
+			err:   errors.New(UPID_Error_TypeEmpty)},
+		{name: `Invalid User`,
+			input: UPID{Node: "pve1", Type: "qmstart"},
+			err:   errors.New("no username is specified")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			require.Equal(t, test.err, test.input.Validate())
+		})
+	}
+}
@@ -35,6 +35,22 @@ func (vmr *VmRef) check_unsafe(ctx context.Context, c clientApiInterface) (*rawG
 	return raw, nil
 }
 
+// checkType_unsafe is check_unsafe for a guest that has to be of the given type.
+// A type set in the VmRef is compared as is, an unknown type is looked up even when the node is known.
+func (vmr *VmRef) checkType_unsafe(ctx context.Context, c clientApiInterface, guestType GuestType) (*rawGuestResource, error) {
+	if vmr.vmType != guestUnknown && vmr.vmType != guestType {
+		return nil, errors.New(VmRef_Error_GuestType)
+	}
+	raw, err := vmr.check_unsafe(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if vmr.vmType != guestType {
+		return nil, errors.New(VmRef_Error_GuestType)
+	}
+	return raw, nil
+}
+
 // CloneLxc clones a new LXC container by cloning current container
 func (vmr *VmRef) CloneLxc(ctx context.Context, settings CloneLxcTarget, c *Client) (*VmRef, error) {
 	if vmr == nil {
@@ -161,8 +177,8 @@ func (c *clientNewTest) guestStopForce(ctx context.Context, vmr *VmRef) error {
 	if err != nil {
 		return err
 	}
-	if version.Major < 8 {
-		return functionalityNotSupportedInVersion("force stop", version)
+	if err = CapabilityForceStop.check(version); err != nil {
+		return err
 	}
 	if err := c.oldClient.CheckVmRef(ctx, vmr); err != nil {
 		return err
@@ -289,6 +305,18 @@ func (c *clientNewTest) guestStop(ctx context.Context, vmr *VmRef) error {
 	return c.oldClient.New().Guest.Stop(ctx, *vmr, false)
 }
 
+// template_Unsafe converts the guest into a template, nothing happens when it already is one.
+func (vmr *VmRef) template_Unsafe(ctx context.Context, c clientApiInterface, guestType GuestType) error {
+	raw, err := vmr.checkType_unsafe(ctx, c, guestType)
+	if err != nil {
+		return err
+	}
+	if raw != nil && raw.GetTemplate() {
+		return nil
+	}
+	return c.createGuestTemplate(ctx, vmr)
+}
+
 func (vmr *VmRef) reboot_Unsafe(ctx context.Context, c *clientAPI) error {
 	return c.updateGuestStatus(ctx, vmr, "reboot", nil)
 }
@@ -300,7 +328,7 @@ func (vmr *VmRef) stop_Unsafe(ctx context.Context, c *clientAPI) error {
 
 // Doesn't see the difference between a stopped and absend guest
 func (vmr *VmRef) stopOverruleOpertunistic_Unsafe(ctx context.Context, c *clientAPI, version Version) error {
-	if version.Major >= 8 {
+	if CapabilityOverruleShutdown.SupportedBy(version) {
 		return vmr.stopOverrule_Unsafe(ctx, c)
 	}
 	return vmr.stop_Unsafe(ctx, c)
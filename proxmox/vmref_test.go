@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
 	"github.com/Telmate/proxmox-api-go/internal/util"
 	"github.com/stretchr/testify/require"
 )
@@ -358,6 +359,16 @@ func Test_CloneQemuTarget_Validate(t *testing.T) {
 	}
 }
 
+func Test_VmRef_ForceStop(t *testing.T) {
+	t.Parallel()
+	server, c := testMockServerInit(t)
+	server.Set(mockServer.RequestsVersion("7.4.3"), t)
+	err := NewVmRef(100).ForceStop(context.Background(), c)
+	require.Equal(t, CapabilityForceStop.error(Version{Major: 7, Minor: 4, Patch: 3}), err)
+	require.ErrorIs(t, err, Error.FunctionalityNotSupportedInVersion())
+	server.Clear(t)
+}
+
 func Test_VmRef_Migrate(t *testing.T) {
 	t.Parallel()
 	type testInput struct {
@@ -527,6 +538,64 @@ func Test_VmRef_pendingConfig(t *testing.T) {
 	}
 }
 
+func Test_VmRef_template_Unsafe(t *testing.T) {
+	t.Parallel()
+	resources := []any{
+		map[string]any{"vmid": float64(100), "node": "pve1", "type": "lxc"},
+		map[string]any{"vmid": float64(200), "node": "pve2", "type": "lxc", "template": float64(1)},
+		map[string]any{"vmid": float64(300), "node": "pve1", "type": "qemu"}}
+	tests := []struct {
+		name     string
+		input    VmRef
+		template *VmRef
+		err      error
+	}{
+		{name: `Complete reference`,
+			input:    VmRef{vmId: 400, node: "pve3", vmType: GuestLxc},
+			template: &VmRef{vmId: 400, node: "pve3", vmType: GuestLxc}},
+		{name: `Lookup`,
+			input:    VmRef{vmId: 100},
+			template: &VmRef{vmId: 100, node: "pve1", vmType: GuestLxc}},
+		{name: `Already template`,
+			input: VmRef{vmId: 200}},
+		{name: `Different type`,
+			input: VmRef{vmId: 300},
+			err:   errors.New(VmRef_Error_GuestType)},
+		{name: `Different type node set`,
+			input: VmRef{vmId: 300, node: "pve1"},
+			err:   errors.New(VmRef_Error_GuestType)},
+		{name: `Different type explicit`,
+			input: VmRef{vmId: 300, node: "pve1", vmType: GuestQemu},
+			err:   errors.New(VmRef_Error_GuestType)},
+		{name: `Node set`,
+			input:    VmRef{vmId: 100, node: "pve1"},
+			template: &VmRef{vmId: 100, node: "pve1", vmType: GuestLxc}},
+		{name: `Does not exist`,
+			input: VmRef{vmId: 500},
+			err:   errorMsg{}.guestDoesNotExist(500)},
+		{name: `Error`,
+			input:    VmRef{vmId: 100},
+			template: &VmRef{vmId: 100, node: "pve1", vmType: GuestLxc},
+			err:      errors.New("test")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var template *VmRef
+			err := test.input.template_Unsafe(context.Background(), &mockClientAPI{
+				listGuestResourcesFunc: func(context.Context) (*rawGuestResources, error) {
+					return &rawGuestResources{a: resources}, nil
+				},
+				createGuestTemplateFunc: func(_ context.Context, vmr *VmRef) error {
+					template = vmr
+					return test.err
+				}}, GuestLxc)
+			require.Equal(t, test.err, err)
+			require.Equal(t, test.template, template)
+		})
+	}
+}
+
 func Test_RawGuestStatus_Get(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
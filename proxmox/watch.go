@@ -0,0 +1,323 @@
+package proxmox
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+)
+
+type (
+	WatcherInterface interface {
+		// Watch polls the cluster and yields an event for every change since the previous poll.
+		// Errors while polling are yielded and polling continues, iteration ends when the context is done or the loop is broken.
+		// Events of a single poll are yielded in order of guest ID, followed by the tasks in order of their start time.
+		Watch(context.Context, WatchFilter) iter.Seq2[WatchEvent, error]
+		WatchNoCheck(context.Context, WatchFilter) iter.Seq2[WatchEvent, error]
+	}
+
+	watcherClient struct {
+		api       *clientAPI
+		oldClient *Client
+	}
+)
+
+var _ WatcherInterface = (*watcherClient)(nil)
+
+func (c *watcherClient) Watch(ctx context.Context, filter WatchFilter) iter.Seq2[WatchEvent, error] {
+	if err := filter.Validate(); err != nil {
+		return func(yield func(WatchEvent, error) bool) { yield(WatchEvent{}, err) }
+	}
+	return c.WatchNoCheck(ctx, filter)
+}
+
+func (c *watcherClient) WatchNoCheck(ctx context.Context, filter WatchFilter) iter.Seq2[WatchEvent, error] {
+	return filter.watch(ctx, c.api)
+}
+
+// WatchFilter limits which changes are reported by a watch.
+type WatchFilter struct {
+	Events []WatchEventType `json:"events,omitempty"` // When empty all events are reported.
+	Guests []GuestID        `json:"guests,omitempty"` // When empty all guests and tasks are reported.
+	Nodes  []NodeName       `json:"nodes,omitempty"`  // When empty the guests and tasks of all nodes are reported.
+	// Time between polls, defaults to 5 seconds.
+	Interval time.Duration `json:"interval,omitempty"`
+	// State to resume from, it is updated after every poll so it can be persisted and passed to a later watch.
+	// When nil or empty the first poll only records the state of the cluster without reporting events.
+	State *WatchState `json:"-"`
+}
+
+const WatchFilter_Error_Interval = "watch interval may not be negative"
+
+const watchDefaultInterval = 5 * time.Second
+
+func (filter WatchFilter) interval() time.Duration {
+	if filter.Interval == 0 {
+		return watchDefaultInterval
+	}
+	return filter.Interval
+}
+
+// matchGuest returns true when the guest is selected by the filter and at least one of the nodes is.
+func (filter WatchFilter) matchGuest(id GuestID, nodes ...NodeName) bool {
+	if len(filter.Guests) != 0 && !slices.Contains(filter.Guests, id) {
+		return false
+	}
+	if len(filter.Nodes) == 0 {
+		return true
+	}
+	for _, node := range nodes {
+		if slices.Contains(filter.Nodes, node) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTask returns true when the task runs on one of the selected nodes and is about one of the selected guests.
+func (filter WatchFilter) matchTask(upid UPID) bool {
+	if len(filter.Nodes) != 0 && !slices.Contains(filter.Nodes, upid.Node) {
+		return false
+	}
+	if len(filter.Guests) == 0 {
+		return true
+	}
+	id, err := strconv.ParseUint(upid.ID, 10, 32)
+	return err == nil && slices.Contains(filter.Guests, GuestID(id))
+}
+
+func (filter WatchFilter) Validate() error {
+	for _, event := range filter.Events {
+		if err := event.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, id := range filter.Guests {
+		if err := id.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, node := range filter.Nodes {
+		if err := node.Validate(); err != nil {
+			return err
+		}
+	}
+	if filter.Interval < 0 {
+		return errors.New(WatchFilter_Error_Interval)
+	}
+	return nil
+}
+
+func (filter WatchFilter) wants(events ...WatchEventType) bool {
+	if len(filter.Events) == 0 {
+		return true
+	}
+	for _, event := range events {
+		if slices.Contains(filter.Events, event) {
+			return true
+		}
+	}
+	return false
+}
+
+func (filter WatchFilter) watch(ctx context.Context, c clientApiInterface) iter.Seq2[WatchEvent, error] {
+	return func(yield func(WatchEvent, error) bool) {
+		state := filter.State
+		if state == nil {
+			state = &WatchState{}
+		}
+		for {
+			events, err := state.poll(ctx, c, filter)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && !yield(WatchEvent{}, err) {
+				return
+			}
+			for _, event := range events {
+				if !yield(event, nil) {
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(filter.interval()):
+			}
+		}
+	}
+}
+
+type WatchEvent struct {
+	Type WatchEventType `json:"type"`
+	// Set for guest events, the guest after the change or before it was removed.
+	Guest *WatchGuest `json:"guest,omitempty"`
+	// Set for WatchEventType_NodeChanged and WatchEventType_PowerStateChanged, the guest before the change.
+	Previous *WatchGuest `json:"previous,omitempty"`
+	Task     *TaskStatus `json:"task,omitempty"` // Set for task events.
+}
+
+type WatchEventType string // Enum
+
+const (
+	WatchEventType_GuestAdded        WatchEventType = "guest-added"
+	WatchEventType_GuestRemoved      WatchEventType = "guest-removed"
+	WatchEventType_NodeChanged       WatchEventType = "node-changed" // The guest was migrated.
+	WatchEventType_PowerStateChanged WatchEventType = "power-state-changed"
+	WatchEventType_TaskFinished      WatchEventType = "task-finished"
+	WatchEventType_TaskStarted       WatchEventType = "task-started"
+)
+
+const WatchEventType_Error_Invalid = "event type should be one of: guest-added, guest-removed, node-changed, power-state-changed, task-finished, task-started"
+
+func (event WatchEventType) Validate() error {
+	switch event {
+	case WatchEventType_GuestAdded, WatchEventType_GuestRemoved, WatchEventType_NodeChanged,
+		WatchEventType_PowerStateChanged, WatchEventType_TaskFinished, WatchEventType_TaskStarted:
+		return nil
+	}
+	return errors.New(WatchEventType_Error_Invalid)
+}
+
+type WatchGuest struct {
+	ID    GuestID    `json:"id"`
+	Name  GuestName  `json:"name"`
+	Node  NodeName   `json:"node"`
+	State PowerState `json:"state"`
+	Type  GuestType  `json:"type"`
+}
+
+// WatchState is the state of the cluster as seen by the last poll of a watch.
+// It can be stored as JSON to resume watching later, changes made in the meantime are reported by the first poll.
+type WatchState struct {
+	Guests map[GuestID]WatchGuest `json:"guests"`
+	Tasks  map[string]TaskState   `json:"tasks"` // Key is the UPID.
+}
+
+// poll reads the cluster, updates the state and returns the events of the changes.
+// The state is left untouched when reading fails.
+func (state *WatchState) poll(ctx context.Context, c clientApiInterface, filter WatchFilter) ([]WatchEvent, error) {
+	// Only poll what is needed for the requested events.
+	watchGuests := filter.wants(WatchEventType_GuestAdded, WatchEventType_GuestRemoved, WatchEventType_NodeChanged, WatchEventType_PowerStateChanged)
+	watchTasks := filter.wants(WatchEventType_TaskFinished, WatchEventType_TaskStarted)
+	var guests *rawGuestResources
+	var tasks []any
+	var err error
+	if watchGuests {
+		if guests, err = c.listGuestResources(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if watchTasks {
+		if tasks, err = c.listTasks(ctx); err != nil {
+			return nil, err
+		}
+	}
+	events := make([]WatchEvent, 0)
+	if watchGuests {
+		events = state.pollGuests(guests, filter)
+	}
+	if watchTasks {
+		events = append(events, state.pollTasks(tasks, filter)...)
+	}
+	return events, nil
+}
+
+func (state *WatchState) pollGuests(raws *rawGuestResources, filter WatchFilter) []WatchEvent {
+	current := make(map[GuestID]WatchGuest, raws.Len())
+	for raw := range raws.Iter() {
+		current[raw.GetID()] = WatchGuest{
+			ID:    raw.GetID(),
+			Name:  raw.GetName(),
+			Node:  raw.GetNode(),
+			State: raw.GetStatus(),
+			Type:  raw.GetType()}
+	}
+	previous := state.Guests
+	state.Guests = current
+	if previous == nil {
+		return nil
+	}
+	events := make([]WatchEvent, 0)
+	ids := slices.AppendSeq(make([]GuestID, 0, len(current)), maps.Keys(current))
+	for id := range previous {
+		if _, exists := current[id]; !exists {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		newGuest, exists := current[id]
+		oldGuest, existed := previous[id]
+		switch {
+		case !existed:
+			if filter.wants(WatchEventType_GuestAdded) && filter.matchGuest(id, newGuest.Node) {
+				events = append(events, WatchEvent{Type: WatchEventType_GuestAdded, Guest: &newGuest})
+			}
+		case !exists:
+			if filter.wants(WatchEventType_GuestRemoved) && filter.matchGuest(id, oldGuest.Node) {
+				events = append(events, WatchEvent{Type: WatchEventType_GuestRemoved, Guest: &oldGuest})
+			}
+		default:
+			if !filter.matchGuest(id, oldGuest.Node, newGuest.Node) {
+				continue
+			}
+			if oldGuest.Node != newGuest.Node && filter.wants(WatchEventType_NodeChanged) {
+				events = append(events, WatchEvent{Type: WatchEventType_NodeChanged, Guest: &newGuest, Previous: &oldGuest})
+			}
+			if oldGuest.State != newGuest.State && filter.wants(WatchEventType_PowerStateChanged) {
+				events = append(events, WatchEvent{Type: WatchEventType_PowerStateChanged, Guest: &newGuest, Previous: &oldGuest})
+			}
+		}
+	}
+	return events
+}
+
+// https://pve.proxmox.com/pve-docs/api-viewer/#/cluster/tasks
+func (state *WatchState) pollTasks(raws []any, filter WatchFilter) []WatchEvent {
+	current := make(map[string]TaskState, len(raws))
+	tasks := make([]TaskStatus, 0, len(raws))
+	// The API lists the most recent task first.
+	for i := len(raws) - 1; i >= 0; i-- {
+		params := raws[i].(map[string]any)
+		upid, _ := params[watchApiKeyUPID].(string)
+		task := TaskStatus{State: TaskStateRunning}
+		if err := task.UPID.Parse(upid); err != nil {
+			continue
+		}
+		if v, isSet := params[watchApiKeyTaskStatus]; isSet {
+			task.ExitStatus = v.(string)
+			task.State = TaskStateStopped
+		}
+		current[task.UPID.String()] = task.State
+		tasks = append(tasks, task)
+	}
+	previous := state.Tasks
+	state.Tasks = current
+	if previous == nil {
+		return nil
+	}
+	slices.SortStableFunc(tasks, func(a, b TaskStatus) int { return a.UPID.StartTime.Compare(b.UPID.StartTime) })
+	events := make([]WatchEvent, 0)
+	for i := range tasks {
+		if !filter.matchTask(tasks[i].UPID) {
+			continue
+		}
+		previousState, seen := previous[tasks[i].UPID.String()]
+		if !seen && filter.wants(WatchEventType_TaskStarted) {
+			events = append(events, WatchEvent{Type: WatchEventType_TaskStarted, Task: &tasks[i]})
+		}
+		if previousState != TaskStateStopped && tasks[i].State == TaskStateStopped && filter.wants(WatchEventType_TaskFinished) {
+			events = append(events, WatchEvent{Type: WatchEventType_TaskFinished, Task: &tasks[i]})
+		}
+	}
+	return events
+}
+
+const (
+	watchApiKeyTaskStatus string = "status"
+	watchApiKeyUPID       string = "upid"
+)
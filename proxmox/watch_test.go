@@ -0,0 +1,187 @@
+package proxmox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WatchFilter_Validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  WatchFilter
+		output error
+	}{
+		{name: `Valid empty`},
+		{name: `Valid`,
+			input: WatchFilter{
+				Events:   []WatchEventType{WatchEventType_GuestAdded, WatchEventType_TaskFinished},
+				Guests:   []GuestID{100},
+				Nodes:    []NodeName{"pve"},
+				Interval: time.Second}},
+		{name: `Invalid event`,
+			input:  WatchFilter{Events: []WatchEventType{"invalid"}},
+			output: errors.New(WatchEventType_Error_Invalid)},
+		{name: `Invalid guest`,
+			input:  WatchFilter{Guests: []GuestID{0}},
+			output: errors.New(GuestID_Error_Minimum)},
+		{name: `Invalid interval`,
+			input:  WatchFilter{Interval: -1},
+			output: errors.New(WatchFilter_Error_Interval)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, test.output, test.input.Validate())
+		})
+	}
+}
+
+func Test_WatchState_poll(t *testing.T) {
+	t.Parallel()
+	guest := func(id float64, node, status string) map[string]any {
+		return map[string]any{"vmid": id, "name": "test", "node": node, "status": status, "type": "qemu"}
+	}
+	watchGuest := func(id GuestID, node NodeName, state PowerState) *WatchGuest {
+		return &WatchGuest{ID: id, Name: "test", Node: node, State: state, Type: GuestQemu}
+	}
+	task := func(upid, status string) map[string]any {
+		raw := map[string]any{"upid": upid}
+		if status != "" {
+			raw["status"] = status
+		}
+		return raw
+	}
+	taskStatus := func(upid, exitStatus string) *TaskStatus {
+		status := TaskStatus{ExitStatus: exitStatus, State: TaskStateRunning}
+		require.NoError(t, status.UPID.Parse(upid))
+		if exitStatus != "" {
+			status.State = TaskStateStopped
+		}
+		return &status
+	}
+	const (
+		upidStart   = "UPID:pve1:00000001:00000001:00000010:qmstart:100:root@pam:"
+		upidMigrate = "UPID:pve1:00000002:00000002:00000020:qmigrate:100:root@pam:"
+		upidBackup  = "UPID:pve2:00000003:00000003:00000030:vzdump:200:root@pam:"
+	)
+	type poll struct {
+		guests []any
+		tasks  []any
+		events []WatchEvent
+	}
+	tests := []struct {
+		name   string
+		filter WatchFilter
+		polls  []poll
+	}{
+		{name: `Guests`,
+			filter: WatchFilter{Events: []WatchEventType{
+				WatchEventType_GuestAdded, WatchEventType_GuestRemoved, WatchEventType_NodeChanged, WatchEventType_PowerStateChanged}},
+			polls: []poll{
+				{guests: []any{guest(100, "pve1", "stopped"), guest(200, "pve1", "running")}},
+				{guests: []any{guest(100, "pve1", "running"), guest(200, "pve1", "running"), guest(300, "pve2", "stopped")},
+					events: []WatchEvent{
+						{Type: WatchEventType_PowerStateChanged, Guest: watchGuest(100, "pve1", PowerStateRunning), Previous: watchGuest(100, "pve1", PowerStateStopped)},
+						{Type: WatchEventType_GuestAdded, Guest: watchGuest(300, "pve2", PowerStateStopped)}}},
+				{guests: []any{guest(100, "pve2", "stopped"), guest(300, "pve2", "stopped")},
+					events: []WatchEvent{
+						{Type: WatchEventType_NodeChanged, Guest: watchGuest(100, "pve2", PowerStateStopped), Previous: watchGuest(100, "pve1", PowerStateRunning)},
+						{Type: WatchEventType_PowerStateChanged, Guest: watchGuest(100, "pve2", PowerStateStopped), Previous: watchGuest(100, "pve1", PowerStateRunning)},
+						{Type: WatchEventType_GuestRemoved, Guest: watchGuest(200, "pve1", PowerStateRunning)}}},
+				{guests: []any{guest(100, "pve2", "stopped"), guest(300, "pve2", "stopped")},
+					events: []WatchEvent{}}}},
+		{name: `Guests filtered`,
+			filter: WatchFilter{
+				Events: []WatchEventType{WatchEventType_GuestRemoved, WatchEventType_NodeChanged},
+				Guests: []GuestID{100, 200},
+				Nodes:  []NodeName{"pve1"}},
+			polls: []poll{
+				{guests: []any{guest(100, "pve1", "stopped"), guest(200, "pve2", "stopped")}},
+				{guests: []any{guest(100, "pve2", "running"), guest(300, "pve1", "running")},
+					events: []WatchEvent{
+						{Type: WatchEventType_NodeChanged, Guest: watchGuest(100, "pve2", PowerStateRunning), Previous: watchGuest(100, "pve1", PowerStateStopped)}}}}},
+		{name: `Tasks`,
+			filter: WatchFilter{Events: []WatchEventType{WatchEventType_TaskStarted, WatchEventType_TaskFinished}},
+			polls: []poll{
+				{tasks: []any{task(upidStart, "")}},
+				{tasks: []any{task(upidBackup, "OK"), task(upidMigrate, ""), task(upidStart, "OK")},
+					events: []WatchEvent{
+						{Type: WatchEventType_TaskFinished, Task: taskStatus(upidStart, "OK")},
+						{Type: WatchEventType_TaskStarted, Task: taskStatus(upidMigrate, "")},
+						{Type: WatchEventType_TaskStarted, Task: taskStatus(upidBackup, "OK")},
+						{Type: WatchEventType_TaskFinished, Task: taskStatus(upidBackup, "OK")}}},
+				{tasks: []any{task(upidBackup, "OK"), task(upidMigrate, "migration aborted")},
+					events: []WatchEvent{
+						{Type: WatchEventType_TaskFinished, Task: taskStatus(upidMigrate, "migration aborted")}}}}},
+		{name: `Tasks filtered`,
+			filter: WatchFilter{
+				Events: []WatchEventType{WatchEventType_TaskStarted},
+				Guests: []GuestID{100},
+				Nodes:  []NodeName{"pve1"}},
+			polls: []poll{
+				{tasks: []any{}},
+				{tasks: []any{task(upidBackup, ""), task(upidMigrate, ""), task(upidStart, "OK")},
+					events: []WatchEvent{
+						{Type: WatchEventType_TaskStarted, Task: taskStatus(upidStart, "OK")},
+						{Type: WatchEventType_TaskStarted, Task: taskStatus(upidMigrate, "")}}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			var state WatchState
+			for i, p := range test.polls {
+				c := mockClientAPI{
+					listGuestResourcesFunc: func(context.Context) (*rawGuestResources, error) {
+						return &rawGuestResources{a: p.guests}, nil
+					},
+					listTasksFunc: func(context.Context) ([]any, error) { return p.tasks, nil },
+				}
+				if p.guests == nil {
+					c.listGuestResourcesFunc = nil
+				}
+				if p.tasks == nil {
+					c.listTasksFunc = nil
+				}
+				events, err := state.poll(context.Background(), c.new(), test.filter)
+				require.NoError(t, err)
+				if i == 0 {
+					require.Empty(t, events)
+					continue
+				}
+				require.Equal(t, p.events, events, "poll %d", i)
+			}
+		})
+	}
+}
+
+func Test_WatchState_poll_Error(t *testing.T) {
+	t.Parallel()
+	state := WatchState{Tasks: map[string]TaskState{}}
+	c := mockClientAPI{
+		listGuestResourcesFunc: func(context.Context) (*rawGuestResources, error) {
+			return &rawGuestResources{a: []any{map[string]any{"vmid": float64(100)}}}, nil
+		},
+		listTasksFunc: func(context.Context) ([]any, error) { return nil, errors.New("test") },
+	}
+	_, err := state.poll(context.Background(), c.new(), WatchFilter{})
+	require.Error(t, err)
+	require.Equal(t, WatchState{Tasks: map[string]TaskState{}}, state)
+}
+
+func Test_WatchState_json(t *testing.T) {
+	t.Parallel()
+	state := WatchState{
+		Guests: map[GuestID]WatchGuest{100: {ID: 100, Name: "test", Node: "pve", State: PowerStateRunning, Type: GuestLxc}},
+		Tasks:  map[string]TaskState{}}
+	raw, err := json.Marshal(state)
+	require.NoError(t, err)
+	var resumed WatchState
+	require.NoError(t, json.Unmarshal(raw, &resumed))
+	require.Equal(t, state, resumed)
+}
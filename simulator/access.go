@@ -0,0 +1,471 @@
+package simulator
+
+import (
+	"crypto/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const ticketPath = "/access/ticket"
+
+// privileges are all privileges known by Proxmox VE, every user has all of them on "/".
+var privileges = []string{
+	"Datastore.Allocate",
+	"Datastore.AllocateSpace",
+	"Datastore.AllocateTemplate",
+	"Datastore.Audit",
+	"Group.Allocate",
+	"Permissions.Modify",
+	"Pool.Allocate",
+	"Pool.Audit",
+	"Realm.Allocate",
+	"Realm.AllocateUser",
+	"SDN.Allocate",
+	"SDN.Audit",
+	"Sys.Audit",
+	"Sys.Console",
+	"Sys.Incoming",
+	"Sys.Modify",
+	"Sys.PowerMgmt",
+	"Sys.Syslog",
+	"User.Modify",
+	"VM.Allocate",
+	"VM.Audit",
+	"VM.Backup",
+	"VM.Clone",
+	"VM.Config.CDROM",
+	"VM.Config.CPU",
+	"VM.Config.Cloudinit",
+	"VM.Config.Disk",
+	"VM.Config.HWType",
+	"VM.Config.Memory",
+	"VM.Config.Network",
+	"VM.Config.Options",
+	"VM.Console",
+	"VM.Migrate",
+	"VM.Monitor",
+	"VM.PowerMgmt",
+	"VM.Snapshot",
+	"VM.Snapshot.Rollback"}
+
+// userKeys are the settings of a user, besides its groups.
+var userKeys = []string{"comment", "email", "enable", "expire", "firstname", "keys", "lastname"}
+
+type session struct {
+	user string
+	csrf string
+}
+
+type user struct {
+	// All settings of the user as strings, groups are stored as a comma separated list.
+	config   map[string]string
+	password string
+	tokens   map[string]*token
+}
+
+func (u *user) groups() []string { return splitList(u.config["groups"]) }
+
+func (u *user) inGroup(group string) bool { return slices.Contains(u.groups(), group) }
+
+// export returns the user the way the API does, when list is set groups are a comma separated list.
+func (u *user) export(id string, list bool) map[string]any {
+	data := map[string]any{
+		"userid": id,
+		"enable": 1,
+		"expire": 0}
+	for _, key := range userKeys {
+		value, set := u.config[key]
+		if !set {
+			continue
+		}
+		if key == "enable" || key == "expire" {
+			data[key], _ = strconv.Atoi(value)
+			continue
+		}
+		data[key] = value
+	}
+	if list {
+		data["groups"] = strings.Join(u.groups(), ",")
+	} else {
+		data["groups"] = u.groups()
+	}
+	return data
+}
+
+func (u *user) update(r *request) {
+	for _, key := range slices.Concat(userKeys, []string{"groups"}) {
+		if !r.Form.Has(key) {
+			continue
+		}
+		value := r.param(key)
+		if key == "groups" {
+			value = strings.Join(r.list(key), ",")
+			if r.param("append") == "1" {
+				value = strings.Join(append(u.groups(), r.list(key)...), ",")
+			}
+		}
+		u.config[key] = value
+	}
+}
+
+type token struct {
+	comment string
+	expire  uint
+	privsep bool
+	secret  string
+}
+
+func (t *token) export(name string) map[string]any {
+	return map[string]any{
+		"tokenid": name,
+		"comment": t.comment,
+		"expire":  t.expire,
+		"privsep": boolToInt(t.privsep)}
+}
+
+func (t *token) update(r *request) *apiError {
+	if r.Form.Has("comment") {
+		t.comment = r.param("comment")
+	}
+	if r.Form.Has("expire") {
+		expire, err := strconv.ParseUint(r.param("expire"), 10, 0)
+		if err != nil {
+			return parameterError("expire", "value must be an integer")
+		}
+		t.expire = uint(expire)
+	}
+	if r.Form.Has("privsep") {
+		t.privsep = r.param("privsep") == "1"
+	}
+	return nil
+}
+
+type group struct {
+	comment string
+}
+
+// authenticate returns the user or API token that made the request.
+func (s *Server) authenticate(r *http.Request) (string, *apiError) {
+	authorization := r.Header.Get("Authorization")
+	if credentials, ok := strings.CutPrefix(authorization, "PVEAPIToken="); ok {
+		id, secret, _ := strings.Cut(credentials, "=")
+		userID, tokenName, _ := strings.Cut(id, "!")
+		if u, exists := s.users[userID]; exists {
+			if t, exists := u.tokens[tokenName]; exists && t.secret == secret {
+				return id, nil
+			}
+		}
+		return "", unauthorized("invalid token value!")
+	}
+	ticket, ok := strings.CutPrefix(authorization, "PVEAuthCookie=")
+	if !ok {
+		if cookie, err := r.Cookie("PVEAuthCookie"); err == nil {
+			ticket = cookie.Value
+		}
+	}
+	sess, exists := s.sessions[ticket]
+	if !exists {
+		return "", unauthorized("authentication failure")
+	}
+	if r.Method != http.MethodGet && r.Header.Get("CSRFPreventionToken") != sess.csrf {
+		return "", unauthorized("Permission check failed (invalid csrf token)")
+	}
+	return sess.user, nil
+}
+
+func (s *Server) createTicket(r *request) (any, *apiError) {
+	username := r.param("username")
+	password := r.param("password")
+	u, exists := s.users[username]
+	if !exists || (password != u.password && s.sessions[password].user != username) {
+		return nil, unauthorized("authentication failure")
+	}
+	ticket := "PVE:" + username + ":" + strings.ToUpper(strconv.FormatInt(time.Now().Unix(), 16)) + "::" + randomHex(32)
+	csrf := strings.ToUpper(strconv.FormatInt(time.Now().Unix(), 16)) + ":" + randomHex(16)
+	s.sessions[ticket] = session{user: username, csrf: csrf}
+	return map[string]any{
+		"username":            username,
+		"ticket":              ticket,
+		"CSRFPreventionToken": csrf,
+		"cap":                 map[string]any{}}, nil
+}
+
+func (s *Server) getPermissions(*request) (any, *apiError) {
+	all := make(map[string]int, len(privileges))
+	for _, privilege := range privileges {
+		all[privilege] = 1
+	}
+	return map[string]any{"/": all}, nil
+}
+
+func (s *Server) updatePassword(r *request) (any, *apiError) {
+	id, err := r.required("userid")
+	if err != nil {
+		return nil, err
+	}
+	password, err := r.required("password")
+	if err != nil {
+		return nil, err
+	}
+	u, exists := s.users[id]
+	if !exists {
+		return nil, errorf("no such user ('%s')", id)
+	}
+	u.password = password
+	return nil, nil
+}
+
+func (s *Server) listUsers(r *request) (any, *apiError) {
+	full := r.param("full") == "1"
+	users := make([]map[string]any, 0, len(s.users))
+	for _, id := range sortedKeys(s.users) {
+		u := s.users[id]
+		data := u.export(id, true)
+		if full {
+			tokens := make([]map[string]any, 0, len(u.tokens))
+			for _, name := range sortedKeys(u.tokens) {
+				tokens = append(tokens, u.tokens[name].export(name))
+			}
+			data["tokens"] = tokens
+		}
+		users = append(users, data)
+	}
+	return users, nil
+}
+
+func (s *Server) createUser(r *request) (any, *apiError) {
+	id, err := r.required("userid")
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := s.users[id]; exists {
+		return nil, errorf("create user failed: user '%s' already exists", id)
+	}
+	if err := s.checkGroups(r.list("groups")); err != nil {
+		return nil, err
+	}
+	u := &user{config: map[string]string{}, password: r.param("password"), tokens: map[string]*token{}}
+	u.update(r)
+	s.users[id] = u
+	return nil, nil
+}
+
+func (s *Server) getUser(r *request) (any, *apiError) {
+	id := r.PathValue("userid")
+	u, exists := s.users[id]
+	if !exists {
+		return nil, errorf("no such user ('%s')", id)
+	}
+	data := u.export(id, false)
+	delete(data, "userid")
+	tokens := make(map[string]any, len(u.tokens))
+	for name, t := range u.tokens {
+		tokens[name] = t.export(name)
+	}
+	data["tokens"] = tokens
+	return data, nil
+}
+
+func (s *Server) updateUser(r *request) (any, *apiError) {
+	id := r.PathValue("userid")
+	u, exists := s.users[id]
+	if !exists {
+		return nil, errorf("update user failed: no such user ('%s')", id)
+	}
+	if err := s.checkGroups(r.list("groups")); err != nil {
+		return nil, err
+	}
+	u.update(r)
+	return nil, nil
+}
+
+func (s *Server) deleteUser(r *request) (any, *apiError) {
+	id := r.PathValue("userid")
+	if _, exists := s.users[id]; !exists {
+		return nil, errorf("delete user failed: no such user ('%s')", id)
+	}
+	delete(s.users, id)
+	for ticket, sess := range s.sessions {
+		if sess.user == id {
+			delete(s.sessions, ticket)
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) checkGroups(groups []string) *apiError {
+	for _, g := range groups {
+		if _, exists := s.groups[g]; !exists {
+			return errorf("group '%s' does not exist", g)
+		}
+	}
+	return nil
+}
+
+// userToken returns the user and the token, the token is nil when it does not exist.
+func (s *Server) userToken(r *request) (*user, *token, *apiError) {
+	id := r.PathValue("userid")
+	u, exists := s.users[id]
+	if !exists {
+		return nil, nil, errorf("no such user ('%s')", id)
+	}
+	return u, u.tokens[r.PathValue("tokenid")], nil
+}
+
+func (s *Server) noSuchToken(r *request) *apiError {
+	return errorf("no such token '%s' for user '%s'", r.PathValue("tokenid"), r.PathValue("userid"))
+}
+
+func (s *Server) listTokens(r *request) (any, *apiError) {
+	u, _, err := s.userToken(r)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]map[string]any, 0, len(u.tokens))
+	for _, name := range sortedKeys(u.tokens) {
+		tokens = append(tokens, u.tokens[name].export(name))
+	}
+	return tokens, nil
+}
+
+func (s *Server) getToken(r *request) (any, *apiError) {
+	_, t, err := s.userToken(r)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, s.noSuchToken(r)
+	}
+	data := t.export(r.PathValue("tokenid"))
+	delete(data, "tokenid")
+	return data, nil
+}
+
+func (s *Server) createToken(r *request) (any, *apiError) {
+	u, t, err := s.userToken(r)
+	if err != nil {
+		return nil, err
+	}
+	name := r.PathValue("tokenid")
+	if t != nil {
+		return nil, errorf("Token already exists.")
+	}
+	t = &token{privsep: true, secret: rand.Text()}
+	if err := t.update(r); err != nil {
+		return nil, err
+	}
+	u.tokens[name] = t
+	info := t.export(name)
+	delete(info, "tokenid")
+	return map[string]any{
+		"full-tokenid": r.PathValue("userid") + "!" + name,
+		"info":         info,
+		"value":        t.secret}, nil
+}
+
+func (s *Server) updateToken(r *request) (any, *apiError) {
+	_, t, err := s.userToken(r)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, s.noSuchToken(r)
+	}
+	if err := t.update(r); err != nil {
+		return nil, err
+	}
+	data := t.export(r.PathValue("tokenid"))
+	delete(data, "tokenid")
+	return data, nil
+}
+
+func (s *Server) deleteToken(r *request) (any, *apiError) {
+	u, t, err := s.userToken(r)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, s.noSuchToken(r)
+	}
+	delete(u.tokens, r.PathValue("tokenid"))
+	return nil, nil
+}
+
+// members returns the users that are a member of the group.
+func (s *Server) members(name string) []string {
+	members := []string{}
+	for _, id := range sortedKeys(s.users) {
+		if s.users[id].inGroup(name) {
+			members = append(members, id)
+		}
+	}
+	return members
+}
+
+func (s *Server) listGroups(*request) (any, *apiError) {
+	groups := make([]map[string]any, 0, len(s.groups))
+	for _, name := range sortedKeys(s.groups) {
+		groups = append(groups, map[string]any{
+			"groupid": name,
+			"comment": s.groups[name].comment,
+			"users":   strings.Join(s.members(name), ",")})
+	}
+	return groups, nil
+}
+
+func (s *Server) createGroup(r *request) (any, *apiError) {
+	name, err := r.required("groupid")
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := s.groups[name]; exists {
+		return nil, errorf("create group failed: group '%s' already exists", name)
+	}
+	s.groups[name] = &group{comment: r.param("comment")}
+	return nil, nil
+}
+
+func (s *Server) getGroup(r *request) (any, *apiError) {
+	name := r.PathValue("groupid")
+	g, exists := s.groups[name]
+	if !exists {
+		return nil, errorf("group '%s' does not exist", name)
+	}
+	return map[string]any{"comment": g.comment, "members": s.members(name)}, nil
+}
+
+func (s *Server) updateGroup(r *request) (any, *apiError) {
+	name := r.PathValue("groupid")
+	g, exists := s.groups[name]
+	if !exists {
+		return nil, errorf("update group failed: group '%s' does not exist", name)
+	}
+	if r.Form.Has("comment") {
+		g.comment = r.param("comment")
+	}
+	return nil, nil
+}
+
+func (s *Server) deleteGroup(r *request) (any, *apiError) {
+	name := r.PathValue("groupid")
+	if _, exists := s.groups[name]; !exists {
+		return nil, errorf("delete group failed: group '%s' does not exist", name)
+	}
+	delete(s.groups, name)
+	for _, u := range s.users {
+		if u.inGroup(name) {
+			u.config["groups"] = strings.Join(slices.DeleteFunc(u.groups(), func(g string) bool { return g == name }), ",")
+		}
+	}
+	return nil, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,401 @@
+package simulator
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type pool struct {
+	comment  string
+	storages map[string]struct{}
+}
+
+func (s *Server) getVersion(*request) (any, *apiError) {
+	return map[string]any{
+		"version": Version,
+		"release": Version[:strings.LastIndexByte(Version, '.')],
+		"repoid":  "simulator"}, nil
+}
+
+func (s *Server) listNodes(*request) (any, *apiError) {
+	nodes := make([]map[string]any, 0, len(s.nodes))
+	for _, name := range sortedKeys(s.nodes) {
+		nodes = append(nodes, map[string]any{
+			"node":   name,
+			"type":   "node",
+			"id":     "node/" + name,
+			"status": "online"})
+	}
+	return nodes, nil
+}
+
+func (s *Server) node(r *request) *apiError {
+	if _, exists := s.nodes[r.PathValue("node")]; !exists {
+		return errorf("hostname lookup '%s' failed - failed to get address info for: %s: Name or service not known", r.PathValue("node"), r.PathValue("node"))
+	}
+	return nil
+}
+
+func (s *Server) listResources(r *request) (any, *apiError) {
+	resourceType := r.param("type")
+	resources := []map[string]any{}
+	if resourceType == "" || resourceType == "node" {
+		for _, name := range sortedKeys(s.nodes) {
+			resources = append(resources, map[string]any{
+				"id":     "node/" + name,
+				"type":   "node",
+				"node":   name,
+				"status": "online"})
+		}
+	}
+	if resourceType != "" && resourceType != "vm" {
+		return resources, nil
+	}
+	for _, id := range sortedKeys(s.guests) {
+		g := s.guests[id]
+		resource := map[string]any{
+			"id":       string(g.Type) + "/" + strconv.FormatUint(uint64(id), 10),
+			"type":     string(g.Type),
+			"vmid":     id,
+			"node":     g.Node,
+			"name":     g.name(),
+			"status":   g.status(),
+			"template": boolToInt(g.Config["template"] == "1"),
+			"maxcpu":   g.cores(),
+			"maxmem":   g.memory() << 20,
+			"uptime":   g.uptime()}
+		if g.Pool != "" {
+			resource["pool"] = g.Pool
+		}
+		if g.Lock != "" {
+			resource["lock"] = g.Lock
+		}
+		if tags, set := g.Config["tags"]; set {
+			resource["tags"] = tags
+		}
+		if ha, set := s.haResources[id]; set {
+			resource["hastate"] = ha["state"]
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+func (s *Server) getNextID(r *request) (any, *apiError) {
+	if r.Form.Has("vmid") {
+		id, err := strconv.ParseUint(r.param("vmid"), 10, 0)
+		if err != nil || id < 100 {
+			return nil, parameterError("vmid", "value must have a minimum value of 100")
+		}
+		if _, exists := s.guests[uint(id)]; exists {
+			return nil, &apiError{status: http.StatusBadRequest, message: "VM " + r.param("vmid") + " already exists"}
+		}
+		return r.param("vmid"), nil
+	}
+	id := uint(100)
+	for {
+		if _, exists := s.guests[id]; !exists {
+			return strconv.FormatUint(uint64(id), 10), nil
+		}
+		id++
+	}
+}
+
+func (s *Server) listPools(*request) (any, *apiError) {
+	pools := make([]map[string]any, 0, len(s.pools))
+	for _, name := range sortedKeys(s.pools) {
+		pools = append(pools, map[string]any{"poolid": name, "comment": s.pools[name].comment})
+	}
+	return pools, nil
+}
+
+func (s *Server) createPool(r *request) (any, *apiError) {
+	name, err := r.required("poolid")
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := s.pools[name]; exists {
+		return nil, errorf("create pool failed: pool '%s' already exists", name)
+	}
+	s.pools[name] = &pool{comment: r.param("comment"), storages: map[string]struct{}{}}
+	return nil, nil
+}
+
+func (s *Server) getPool(r *request) (any, *apiError) {
+	name := r.PathValue("poolid")
+	p, exists := s.pools[name]
+	if !exists {
+		return nil, errorf("pool '%s' does not exist", name)
+	}
+	members := []map[string]any{}
+	for _, id := range sortedKeys(s.guests) {
+		g := s.guests[id]
+		if g.Pool != name {
+			continue
+		}
+		members = append(members, map[string]any{
+			"id":     string(g.Type) + "/" + strconv.FormatUint(uint64(id), 10),
+			"type":   string(g.Type),
+			"vmid":   id,
+			"node":   g.Node,
+			"name":   g.name(),
+			"status": g.status()})
+	}
+	for _, storage := range sortedKeys(p.storages) {
+		for _, node := range sortedKeys(s.nodes) {
+			members = append(members, map[string]any{
+				"id":      "storage/" + node + "/" + storage,
+				"type":    "storage",
+				"node":    node,
+				"storage": storage})
+		}
+	}
+	return map[string]any{"comment": p.comment, "members": members}, nil
+}
+
+func (s *Server) updatePool(r *request) (any, *apiError) {
+	name := r.PathValue("poolid")
+	p, exists := s.pools[name]
+	if !exists {
+		return nil, errorf("pool '%s' does not exist", name)
+	}
+	remove := r.param("delete") == "1"
+	var guests []*guest
+	for _, rawID := range r.list("vms") {
+		id, err := strconv.ParseUint(rawID, 10, 0)
+		if err != nil {
+			return nil, parameterError("vms", "invalid format - value does not look like a valid VM ID")
+		}
+		g, exists := s.guests[uint(id)]
+		if !exists {
+			return nil, errorf("no such VMID '%s'", rawID)
+		}
+		if !remove && g.Pool != "" && g.Pool != name && r.param("allow-move") != "1" {
+			return nil, errorf("VM %s belongs already to pool '%s'", rawID, g.Pool)
+		}
+		guests = append(guests, g)
+	}
+	if r.Form.Has("comment") {
+		p.comment = r.param("comment")
+	}
+	for _, g := range guests {
+		if !remove {
+			g.Pool = name
+		} else if g.Pool == name {
+			g.Pool = ""
+		}
+	}
+	for _, storage := range r.list("storage") {
+		if remove {
+			delete(p.storages, storage)
+		} else {
+			p.storages[storage] = struct{}{}
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) deletePool(r *request) (any, *apiError) {
+	name := r.PathValue("poolid")
+	p, exists := s.pools[name]
+	if !exists {
+		return nil, errorf("delete pool failed: pool '%s' does not exist", name)
+	}
+	empty := len(p.storages) == 0
+	for _, g := range s.guests {
+		if g.Pool == name {
+			empty = false
+		}
+	}
+	if !empty {
+		return nil, errorf("delete pool failed: pool '%s' is not empty", name)
+	}
+	delete(s.pools, name)
+	return nil, nil
+}
+
+// haGuest returns the guest of a HA resource ID, e.g. "vm:100" or "100".
+func (s *Server) haGuest(sid string) (uint, *apiError) {
+	_, rawID, found := strings.Cut(sid, ":")
+	if !found {
+		rawID = sid
+	}
+	id, err := strconv.ParseUint(rawID, 10, 0)
+	if err != nil {
+		return 0, parameterError("sid", "unable to parse service id")
+	}
+	if _, exists := s.guests[uint(id)]; !exists {
+		return 0, errorf("no such VMID '%s'", rawID)
+	}
+	return uint(id), nil
+}
+
+func (s *Server) createHaResource(r *request) (any, *apiError) {
+	sid, err := r.required("sid")
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.haGuest(sid)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := s.haResources[id]; exists {
+		return nil, errorf("service '%s' already defined", sid)
+	}
+	s.haResources[id] = map[string]string{"state": "started"}
+	for _, key := range []string{"comment", "group", "max_relocate", "max_restart", "state"} {
+		if r.Form.Has(key) {
+			s.haResources[id][key] = r.param(key)
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) getHaResource(r *request) (any, *apiError) {
+	id, err := s.haGuest(r.PathValue("sid"))
+	if err != nil {
+		return nil, err
+	}
+	ha, exists := s.haResources[id]
+	if !exists {
+		return nil, errorf("no such resource '%s'", r.PathValue("sid"))
+	}
+	prefix := "vm:"
+	if s.guests[id].Type == GuestLxc {
+		prefix = "ct:"
+	}
+	sid := prefix + strconv.FormatUint(uint64(id), 10)
+	resource := map[string]any{"sid": sid, "type": prefix[:2], "digest": haRuleDigest(ha)}
+	for key, value := range ha {
+		resource[key] = value
+	}
+	return resource, nil
+}
+
+func (s *Server) updateHaResource(r *request) (any, *apiError) {
+	id, err := s.haGuest(r.PathValue("sid"))
+	if err != nil {
+		return nil, err
+	}
+	ha, exists := s.haResources[id]
+	if !exists {
+		return nil, errorf("service '%s' does not exist", r.PathValue("sid"))
+	}
+	for _, key := range []string{"comment", "group", "max_relocate", "max_restart", "state"} {
+		if r.Form.Has(key) {
+			ha[key] = r.param(key)
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) deleteHaResource(r *request) (any, *apiError) {
+	id, err := s.haGuest(r.PathValue("sid"))
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := s.haResources[id]; !exists {
+		return nil, errorf("cannot delete service '%s', not HA managed!", r.PathValue("sid"))
+	}
+	delete(s.haResources, id)
+	return nil, nil
+}
+
+// haRuleDigest returns the digest of the rule, which changes whenever the rule changes.
+func haRuleDigest(rule map[string]string) string {
+	hash := sha1.New()
+	for _, key := range sortedKeys(rule) {
+		hash.Write([]byte(key + "=" + rule[key] + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func exportHaRule(name string, rule map[string]string) map[string]any {
+	data := map[string]any{"rule": name, "digest": haRuleDigest(rule)}
+	for key, value := range rule {
+		data[key] = value
+	}
+	return data
+}
+
+func (s *Server) listHaRules(*request) (any, *apiError) {
+	rules := make([]map[string]any, 0, len(s.haRules))
+	for _, name := range sortedKeys(s.haRules) {
+		rules = append(rules, exportHaRule(name, s.haRules[name]))
+	}
+	return rules, nil
+}
+
+func (s *Server) getHaRule(r *request) (any, *apiError) {
+	name := r.PathValue("rule")
+	rule, exists := s.haRules[name]
+	if !exists {
+		return nil, errorf("no such ha rule '%s'", name)
+	}
+	return exportHaRule(name, rule), nil
+}
+
+func (s *Server) createHaRule(r *request) (any, *apiError) {
+	name, err := r.required("rule")
+	if err != nil {
+		return nil, err
+	}
+	ruleType, err := r.required("type")
+	if err != nil {
+		return nil, err
+	}
+	if ruleType != "node-affinity" && ruleType != "resource-affinity" {
+		return nil, parameterError("type", "value '"+ruleType+"' does not have a value in the enumeration 'node-affinity, resource-affinity'")
+	}
+	if _, exists := s.haRules[name]; exists {
+		return nil, errorf("ha rule '%s' already defined", name)
+	}
+	rule := map[string]string{}
+	for key := range r.Form {
+		if key != "rule" {
+			rule[key] = r.param(key)
+		}
+	}
+	s.haRules[name] = rule
+	return nil, nil
+}
+
+func (s *Server) updateHaRule(r *request) (any, *apiError) {
+	name := r.PathValue("rule")
+	rule, exists := s.haRules[name]
+	if !exists {
+		return nil, errorf("no such ha rule '%s'", name)
+	}
+	if r.Form.Has("type") && r.param("type") != rule["type"] {
+		return nil, parameterError("type", "rule type cannot be changed")
+	}
+	if r.Form.Has("digest") && r.param("digest") != haRuleDigest(rule) {
+		return nil, errorf("detected modified configuration - file changed by other user? Try again.")
+	}
+	for key := range r.Form {
+		switch key {
+		case "delete", "digest":
+		default:
+			rule[key] = r.param(key)
+		}
+	}
+	for _, key := range r.list("delete") {
+		if !slices.Contains([]string{"rule", "type"}, key) {
+			delete(rule, key)
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) deleteHaRule(r *request) (any, *apiError) {
+	name := r.PathValue("rule")
+	if _, exists := s.haRules[name]; !exists {
+		return nil, errorf("no such ha rule '%s'", name)
+	}
+	delete(s.haRules, name)
+	return nil, nil
+}
@@ -0,0 +1,755 @@
+package simulator
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// intKeys are the configuration keys the API returns as numbers instead of strings.
+var intKeys = map[GuestType][]string{
+	GuestLxc: {"console", "cores", "cpuunits", "memory", "onboot", "protection", "swap", "template", "tty", "unprivileged"},
+	GuestQemu: {"acpi", "autostart", "balloon", "ciupgrade", "cores", "cpuunits", "freeze", "kvm", "localtime", "numa",
+		"onboot", "protection", "reboot", "shares", "sockets", "tablet", "template", "vcpus"}}
+
+// createOnlyKeys are parameters of the create request that don't end up in the configuration.
+var createOnlyKeys = []string{"archive", "force", "live-restore", "ostemplate", "password", "pool", "restore",
+	"ssh-public-keys", "start", "storage", "unique", "vmid"}
+
+// diskKeys matches the configuration keys of disks, whose "storage:size" values allocate a new volume.
+var diskKeys = map[GuestType]*regexp.Regexp{
+	GuestLxc:  regexp.MustCompile(`^(rootfs|mp\d+)$`),
+	GuestQemu: regexp.MustCompile(`^(efidisk0|tpmstate0|(ide|sata|scsi|virtio)\d+)$`)}
+
+var volumeAllocation = regexp.MustCompile(`^([^:,]+):(\d+(\.\d+)?|cloudinit)(,.*)?$`)
+
+// allocationOptions are the options that only apply while allocating a volume.
+var allocationOptions = regexp.MustCompile(`,(format|import-from|size)=[^,]*`)
+
+type guest struct {
+	Guest
+	// The snapshot the current state is based on.
+	parent  string
+	started time.Time
+}
+
+func (g *guest) id() string { return strconv.FormatUint(uint64(g.ID), 10) }
+
+func (g *guest) name() string {
+	if g.Type == GuestLxc {
+		return g.Config["hostname"]
+	}
+	return g.Config["name"]
+}
+
+func (g *guest) status() string {
+	if g.Running {
+		return "running"
+	}
+	return "stopped"
+}
+
+func (g *guest) uptime() int64 {
+	if !g.Running {
+		return 0
+	}
+	return int64(time.Since(g.started).Seconds())
+}
+
+func (g *guest) cores() int {
+	cores, err := strconv.Atoi(g.Config["cores"])
+	if err != nil || cores == 0 {
+		cores = 1
+	}
+	if sockets, err := strconv.Atoi(g.Config["sockets"]); err == nil && sockets > 0 {
+		cores *= sockets
+	}
+	return cores
+}
+
+// memory returns the memory of the guest in MiB.
+func (g *guest) memory() int {
+	raw, _, _ := strings.Cut(g.Config["memory"], ",")
+	raw = strings.TrimPrefix(raw, "current=")
+	memory, err := strconv.Atoi(raw)
+	if err != nil || memory == 0 {
+		return 512
+	}
+	return memory
+}
+
+// kind returns how the API refers to the guest in messages.
+func (g *guest) kind() string { return guestKind(g.Type) }
+
+func guestKind(kind GuestType) string {
+	if kind == GuestLxc {
+		return "CT"
+	}
+	return "VM"
+}
+
+func taskPrefix(kind GuestType) string {
+	if kind == GuestLxc {
+		return "vz"
+	}
+	return "qm"
+}
+
+func (g *guest) checkLock(r *request) *apiError {
+	if g.Lock == "" || (r.param("skiplock") == "1" && r.user == RootUser) {
+		return nil
+	}
+	return errorf("%s is locked (%s)", g.kind(), g.Lock)
+}
+
+// allocate creates the volumes for the disks in the config, e.g. "local-lvm:8" becomes "local-lvm:vm-100-disk-0,size=8G".
+func (g *guest) allocate(key, value string) string {
+	if !diskKeys[g.Type].MatchString(key) {
+		return value
+	}
+	match := volumeAllocation.FindStringSubmatch(value)
+	if match == nil {
+		return value
+	}
+	storage, size, options := match[1], match[2], match[4]
+	if size == "cloudinit" {
+		return storage + ":vm-" + g.id() + "-cloudinit,media=cdrom"
+	}
+	gib, _ := strconv.ParseFloat(size, 64)
+	options = allocationOptions.ReplaceAllString(options, "")
+	return storage + ":" + g.newVolume() + options + ",size=" + formatSize(uint64(math.Ceil(gib*1024*1024)))
+}
+
+// newVolume returns the name of an unused volume of the guest.
+func (g *guest) newVolume() string {
+	used := func(volume string) bool {
+		for _, value := range g.Config {
+			if strings.Contains(value, ":"+volume+",") || strings.HasSuffix(value, ":"+volume) {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 0; ; i++ {
+		volume := "vm-" + g.id() + "-disk-" + strconv.Itoa(i)
+		if !used(volume) {
+			return volume
+		}
+	}
+}
+
+// export returns the config the way the API does.
+func (g *guest) export(config map[string]string) map[string]any {
+	data := make(map[string]any, len(config)+1)
+	for key, value := range config {
+		data[key] = value
+	}
+	for _, key := range intKeys[g.Type] {
+		if value, set := config[key]; set {
+			if number, err := strconv.Atoi(value); err == nil {
+				data[key] = number
+			}
+		}
+	}
+	return data
+}
+
+func (g *guest) digest() string {
+	hash := sha1.New()
+	for _, key := range sortedKeys(g.Config) {
+		hash.Write([]byte(key + ": " + g.Config[key] + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (g *guest) snapshot(name string) int {
+	return slices.IndexFunc(g.Snapshots, func(snap Snapshot) bool { return snap.Name == name })
+}
+
+// formatSize formats a size in KiB the way the API does, in the largest unit without a remainder.
+func formatSize(kib uint64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   uint64
+	}{{"T", 1 << 30}, {"G", 1 << 20}, {"M", 1 << 10}} {
+		if kib >= unit.size && kib%unit.size == 0 {
+			return strconv.FormatUint(kib/unit.size, 10) + unit.suffix
+		}
+	}
+	return strconv.FormatUint(kib, 10) + "K"
+}
+
+// parseSize parses a size like "10G" to KiB, a size without a unit is in bytes.
+func parseSize(size string) (uint64, bool) {
+	units := map[byte]float64{'K': 1, 'M': 1 << 10, 'G': 1 << 20, 'T': 1 << 30}
+	multiplier := 1.0 / 1024
+	if size != "" {
+		if unit, ok := units[size[len(size)-1]]; ok {
+			multiplier = unit
+			size = size[:len(size)-1]
+		}
+	}
+	value, err := strconv.ParseFloat(size, 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return uint64(math.Ceil(value * multiplier)), true
+}
+
+// diskSize returns the size option of a disk in KiB.
+func diskSize(value string) uint64 {
+	for option := range strings.SplitSeq(value, ",") {
+		if raw, ok := strings.CutPrefix(option, "size="); ok {
+			size, _ := parseSize(raw)
+			return size
+		}
+	}
+	return 0
+}
+
+// guest returns the guest of the request, the way the API reports a missing configuration file.
+func (s *Server) guest(r *request, kind GuestType) (*guest, *apiError) {
+	if err := s.node(r); err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseUint(r.PathValue("vmid"), 10, 0)
+	if err != nil {
+		return nil, parameterError("vmid", "type check ('integer') failed - got '"+r.PathValue("vmid")+"'")
+	}
+	g, exists := s.guests[uint(id)]
+	if !exists || g.Type != kind || g.Node != r.PathValue("node") {
+		return nil, errorf("%s", configNotFound(kind, r.PathValue("node"), uint(id)))
+	}
+	return g, nil
+}
+
+func configNotFound(kind GuestType, node string, id uint) string {
+	directory := "qemu-server"
+	if kind == GuestLxc {
+		directory = "lxc"
+	}
+	return "Configuration file 'nodes/" + node + "/" + directory + "/" + strconv.FormatUint(uint64(id), 10) + ".conf' does not exist"
+}
+
+func (s *Server) createGuest(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		if err := s.node(r); err != nil {
+			return nil, err
+		}
+		rawID, err := r.required("vmid")
+		if err != nil {
+			return nil, err
+		}
+		id, parseErr := strconv.ParseUint(rawID, 10, 0)
+		if parseErr != nil || id < 100 {
+			return nil, parameterError("vmid", "value must have a minimum value of 100")
+		}
+		if existing, exists := s.guests[uint(id)]; exists {
+			return nil, errorf("unable to create %s %d - %s %d already exists on node '%s'", guestKind(kind), id, guestKind(kind), id, existing.Node)
+		}
+		poolName := r.param("pool")
+		if _, exists := s.pools[poolName]; poolName != "" && !exists {
+			return nil, errorf("pool '%s' does not exist", poolName)
+		}
+		g := &guest{Guest: Guest{
+			ID:     uint(id),
+			Node:   r.PathValue("node"),
+			Type:   kind,
+			Config: map[string]string{},
+			Pool:   poolName}}
+		for key := range r.Form {
+			if !slices.Contains(createOnlyKeys, key) {
+				g.Config[key] = g.allocate(key, r.param(key))
+			}
+		}
+		if r.param("start") == "1" {
+			g.Running = true
+			g.started = time.Now()
+		}
+		s.guests[g.ID] = g
+		return s.startTask(r, g.Node, taskPrefix(kind)+"create", rawID, g, "create", ""), nil
+	}
+}
+
+func (s *Server) deleteGuest(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		if err := s.node(r); err != nil {
+			return nil, err
+		}
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return s.startTask(r, r.PathValue("node"), taskPrefix(kind)+"destroy", r.PathValue("vmid"), nil, "", err.message), nil
+		}
+		if err := g.checkLock(r); err != nil {
+			return nil, err
+		}
+		if g.Config["protection"] == "1" {
+			return nil, errorf("can't remove %s %d - protection mode enabled", g.kind(), g.ID)
+		}
+		if g.Running {
+			if kind == GuestLxc {
+				return nil, errorf("unable to destroy CT %d - container is running", g.ID)
+			}
+			return nil, errorf("unable to destroy VM %d - VM is running", g.ID)
+		}
+		if _, managed := s.haResources[g.ID]; managed {
+			if r.param("purge") != "1" {
+				return nil, errorf("unable to remove %s %d - used in HA resources and purge parameter not set.", g.kind(), g.ID)
+			}
+			delete(s.haResources, g.ID)
+		}
+		delete(s.guests, g.ID)
+		return s.startTask(r, g.Node, taskPrefix(kind)+"destroy", g.id(), nil, "", ""), nil
+	}
+}
+
+func (s *Server) getGuestConfig(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		config := g.export(g.Config)
+		config["digest"] = g.digest()
+		if g.Lock != "" {
+			config["lock"] = g.Lock
+		}
+		if g.parent != "" {
+			config["parent"] = g.parent
+		}
+		return config, nil
+	}
+}
+
+// updateGuestConfig applies the changes immediately, with async the API starts a task for it.
+func (s *Server) updateGuestConfig(kind GuestType, async bool) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.checkLock(r); err != nil {
+			return nil, err
+		}
+		if r.Form.Has("digest") && r.param("digest") != g.digest() {
+			return nil, errorf("detected modified configuration - file changed by other user? Try again.")
+		}
+		deleted := r.list("delete")
+		for key := range r.Form {
+			switch key {
+			case "background_delay", "delete", "digest", "force", "revert", "skiplock":
+				continue
+			}
+			if slices.Contains(deleted, key) {
+				return nil, parameterError(key, "unable to delete parameter that is set")
+			}
+			g.Config[key] = g.allocate(key, r.param(key))
+		}
+		for _, key := range deleted {
+			delete(g.Config, key)
+		}
+		if async {
+			return s.startTask(r, g.Node, taskPrefix(kind)+"config", g.id(), nil, "", ""), nil
+		}
+		return nil, nil
+	}
+}
+
+func (s *Server) getGuestPending(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		config := g.export(g.Config)
+		pending := make([]map[string]any, 0, len(config))
+		for _, key := range sortedKeys(config) {
+			pending = append(pending, map[string]any{"key": key, "value": config[key]})
+		}
+		return pending, nil
+	}
+}
+
+func (s *Server) getGuestFeature(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		if _, err := s.guest(r, kind); err != nil {
+			return nil, err
+		}
+		if _, err := r.required("feature"); err != nil {
+			return nil, err
+		}
+		return map[string]any{"hasFeature": 1, "nodes": []string{}}, nil
+	}
+}
+
+func (s *Server) getGuestStatus(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		status := map[string]any{
+			"vmid":   g.ID,
+			"name":   g.name(),
+			"status": g.status(),
+			"uptime": g.uptime(),
+			"cpus":   g.cores(),
+			"maxmem": g.memory() << 20}
+		if g.Lock != "" {
+			status["lock"] = g.Lock
+		}
+		if g.Config["template"] == "1" {
+			status["template"] = 1
+		}
+		if ha, managed := s.haResources[g.ID]; managed {
+			status["ha"] = map[string]any{"managed": 1, "state": ha["state"]}
+		} else {
+			status["ha"] = map[string]any{"managed": 0}
+		}
+		return status, nil
+	}
+}
+
+func (s *Server) updateGuestStatus(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		action := r.PathValue("action")
+		switch action {
+		case "reboot", "shutdown", "start", "stop":
+		default:
+			return nil, errorf("Method 'POST %s' not implemented", r.URL.Path)
+		}
+		if err := g.checkLock(r); err != nil {
+			return nil, err
+		}
+		if action == "start" {
+			// Like Proxmox VE, starting a running virtual machine is a no-op while containers refuse.
+			if g.Running && kind == GuestLxc {
+				return nil, errorf("%s %d already running", g.kind(), g.ID)
+			}
+			if g.Config["template"] == "1" {
+				return nil, errorf("you can't start a vm if it's a template")
+			}
+			if !g.Running {
+				g.Running = true
+				g.started = time.Now()
+			}
+		} else {
+			if !g.Running {
+				return nil, errorf("%s %d not running", g.kind(), g.ID)
+			}
+			switch action {
+			case "reboot":
+				g.started = time.Now()
+			default:
+				g.Running = false
+			}
+		}
+		return s.startTask(r, g.Node, taskPrefix(kind)+action, g.id(), nil, "", ""), nil
+	}
+}
+
+func (s *Server) resizeGuestDisk(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.checkLock(r); err != nil {
+			return nil, err
+		}
+		disk, err := r.required("disk")
+		if err != nil {
+			return nil, err
+		}
+		rawSize, err := r.required("size")
+		if err != nil {
+			return nil, err
+		}
+		value, exists := g.Config[disk]
+		if !exists {
+			return nil, errorf("disk '%s' does not exist", disk)
+		}
+		current := diskSize(value)
+		relative := strings.HasPrefix(rawSize, "+")
+		size, ok := parseSize(strings.TrimPrefix(rawSize, "+"))
+		if !ok {
+			return nil, parameterError("size", "value does not match the regex pattern")
+		}
+		if relative {
+			size += current
+		}
+		if size < current {
+			return nil, errorf("shrinking disks is not supported")
+		}
+		options := slices.DeleteFunc(strings.Split(value, ","), func(option string) bool { return strings.HasPrefix(option, "size=") })
+		g.Config[disk] = strings.Join(append(options, "size="+formatSize(size)), ",")
+		return s.startTask(r, g.Node, taskPrefix(kind)+"resize", g.id(), nil, "", ""), nil
+	}
+}
+
+func (s *Server) migrateGuest(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		target, err := r.required("target")
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := s.nodes[target]; !exists {
+			return nil, errorf("no such cluster node '%s'", target)
+		}
+		if target == g.Node {
+			return nil, errorf("target is local node.")
+		}
+		if err := g.checkLock(r); err != nil {
+			return nil, err
+		}
+		if g.Running && r.param("online") != "1" && r.param("restart") != "1" {
+			return nil, errorf("can't migrate running VM without --online")
+		}
+		upid := s.startTask(r, g.Node, taskPrefix(kind)+"migrate", g.id(), g, "migrate", "")
+		g.Node = target
+		return upid, nil
+	}
+}
+
+func (s *Server) cloneGuest(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		rawID, err := r.required("newid")
+		if err != nil {
+			return nil, err
+		}
+		id, parseErr := strconv.ParseUint(rawID, 10, 0)
+		if parseErr != nil || id < 100 {
+			return nil, parameterError("newid", "value must have a minimum value of 100")
+		}
+		if _, exists := s.guests[uint(id)]; exists {
+			return nil, errorf("unable to create %s %d: config file already exists", g.kind(), id)
+		}
+		target := g.Node
+		if r.param("target") != "" {
+			target = r.param("target")
+			if _, exists := s.nodes[target]; !exists {
+				return nil, errorf("no such cluster node '%s'", target)
+			}
+		}
+		poolName := r.param("pool")
+		if _, exists := s.pools[poolName]; poolName != "" && !exists {
+			return nil, errorf("pool '%s' does not exist", poolName)
+		}
+		template := g.Config["template"] == "1"
+		if r.param("full") == "0" && !template {
+			return nil, errorf("linked clone is only possible from a template")
+		}
+		if err := g.checkLock(r); err != nil {
+			return nil, err
+		}
+		clone := &guest{Guest: Guest{
+			ID:     uint(id),
+			Node:   target,
+			Type:   kind,
+			Config: make(map[string]string, len(g.Config)),
+			Pool:   poolName}}
+		for key, value := range g.Config {
+			if key != "template" {
+				clone.Config[key] = strings.ReplaceAll(value, "-"+g.id()+"-disk-", "-"+clone.id()+"-disk-")
+			}
+		}
+		nameKey := "name"
+		if kind == GuestLxc {
+			nameKey = "hostname"
+		}
+		if name := r.param(nameKey); name != "" {
+			clone.Config[nameKey] = name
+		}
+		if description := r.param("description"); description != "" {
+			clone.Config["description"] = description
+		}
+		s.guests[clone.ID] = clone
+		return s.startTask(r, g.Node, taskPrefix(kind)+"clone", g.id(), g, "clone", ""), nil
+	}
+}
+
+// templateGuest converts the guest into a template, like Proxmox VE only virtual machines start a task for it.
+func (s *Server) templateGuest(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.checkLock(r); err != nil {
+			return nil, err
+		}
+		if g.Config["template"] == "1" {
+			return nil, errorf("you can't convert a template to a template")
+		}
+		if g.Running {
+			return nil, errorf("you can't convert a %s to template if %s is running", g.kind(), g.kind())
+		}
+		g.Config["template"] = "1"
+		if kind == GuestLxc {
+			return nil, nil
+		}
+		return s.startTask(r, g.Node, taskPrefix(kind)+"template", g.id(), nil, "", ""), nil
+	}
+}
+
+func (s *Server) listSnapshots(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		snapshots := make([]map[string]any, 0, len(g.Snapshots)+1)
+		for _, snap := range g.Snapshots {
+			data := map[string]any{
+				"name":        snap.Name,
+				"description": snap.Description,
+				"snaptime":    snap.Time.Unix()}
+			if kind == GuestQemu {
+				data["vmstate"] = boolToInt(snap.VmState)
+			}
+			if snap.Parent != "" {
+				data["parent"] = snap.Parent
+			}
+			snapshots = append(snapshots, data)
+		}
+		current := map[string]any{"name": "current", "description": "You are here!", "running": boolToInt(g.Running)}
+		if g.parent != "" {
+			current["parent"] = g.parent
+		}
+		return append(snapshots, current), nil
+	}
+}
+
+func (s *Server) createSnapshot(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		name, err := r.required("snapname")
+		if err != nil {
+			return nil, err
+		}
+		if err := g.checkLock(r); err != nil {
+			return nil, err
+		}
+		if g.snapshot(name) != -1 || name == "current" {
+			return nil, errorf("snapshot name '%s' already used", name)
+		}
+		g.Snapshots = append(g.Snapshots, Snapshot{
+			Name:        name,
+			Description: r.param("description"),
+			Parent:      g.parent,
+			Time:        time.Now(),
+			VmState:     kind == GuestQemu && g.Running && r.param("vmstate") == "1",
+			Config:      maps.Clone(g.Config)})
+		g.parent = name
+		return s.startTask(r, g.Node, taskPrefix(kind)+"snapshot", g.id(), g, "snapshot", ""), nil
+	}
+}
+
+func (s *Server) deleteSnapshot(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, err := s.guest(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		name := r.PathValue("snapname")
+		index := g.snapshot(name)
+		if index == -1 {
+			return s.startTask(r, g.Node, taskPrefix(kind)+"delsnapshot", g.id(), nil, "", "snapshot '"+name+"' does not exist"), nil
+		}
+		if err := g.checkLock(r); err != nil {
+			return nil, err
+		}
+		parent := g.Snapshots[index].Parent
+		g.Snapshots = slices.Delete(g.Snapshots, index, index+1)
+		for i := range g.Snapshots {
+			if g.Snapshots[i].Parent == name {
+				g.Snapshots[i].Parent = parent
+			}
+		}
+		if g.parent == name {
+			g.parent = parent
+		}
+		return s.startTask(r, g.Node, taskPrefix(kind)+"delsnapshot", g.id(), g, "snapshot-delete", ""), nil
+	}
+}
+
+func (s *Server) snapshotOf(r *request, kind GuestType) (*guest, *Snapshot, *apiError) {
+	g, err := s.guest(r, kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	index := g.snapshot(r.PathValue("snapname"))
+	if index == -1 {
+		return nil, nil, errorf("snapshot '%s' does not exist", r.PathValue("snapname"))
+	}
+	return g, &g.Snapshots[index], nil
+}
+
+func (s *Server) getSnapshotConfig(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, snap, err := s.snapshotOf(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		config := g.export(snap.Config)
+		config["description"] = snap.Description
+		config["snaptime"] = snap.Time.Unix()
+		if snap.Parent != "" {
+			config["parent"] = snap.Parent
+		}
+		return config, nil
+	}
+}
+
+func (s *Server) updateSnapshotConfig(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		_, snap, err := s.snapshotOf(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		if r.Form.Has("description") {
+			snap.Description = r.param("description")
+		}
+		return nil, nil
+	}
+}
+
+func (s *Server) rollbackSnapshot(kind GuestType) handlerFunc {
+	return func(r *request) (any, *apiError) {
+		g, snap, err := s.snapshotOf(r, kind)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.checkLock(r); err != nil {
+			return nil, err
+		}
+		g.Config = maps.Clone(snap.Config)
+		g.parent = snap.Name
+		g.Running = snap.VmState || r.param("start") == "1"
+		if g.Running {
+			g.started = time.Now()
+		}
+		return s.startTask(r, g.Node, taskPrefix(kind)+"rollback", g.id(), g, "rollback", ""), nil
+	}
+}
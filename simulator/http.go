@@ -0,0 +1,196 @@
+package simulator
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const apiPath = "/api2/json"
+
+// apiError is returned to the client the way Proxmox VE returns errors.
+type apiError struct {
+	status  int
+	message string
+	errors  map[string]string
+}
+
+func errorf(format string, a ...any) *apiError {
+	return &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf(format, a...)}
+}
+
+func parameterError(key, message string) *apiError {
+	return &apiError{
+		status:  http.StatusBadRequest,
+		message: "Parameter verification failed.",
+		errors:  map[string]string{key: message}}
+}
+
+func unauthorized(message string) *apiError {
+	return &apiError{status: http.StatusUnauthorized, message: message}
+}
+
+// request is a request with its parameters parsed, and the user or API token that made it.
+type request struct {
+	*http.Request
+	user string
+}
+
+func (r *request) param(key string) string { return r.Form.Get(key) }
+
+// list returns the comma separated values of the parameter.
+func (r *request) list(key string) []string { return splitList(r.Form.Get(key)) }
+
+func (r *request) required(key string) (string, *apiError) {
+	if !r.Form.Has(key) {
+		return "", parameterError(key, "property is missing and it is not optional")
+	}
+	return r.Form.Get(key), nil
+}
+
+type handlerFunc func(*request) (any, *apiError)
+
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	handle := func(pattern string, handler handlerFunc) {
+		method, path, _ := strings.Cut(pattern, " ")
+		mux.HandleFunc(method+" "+apiPath+path, func(w http.ResponseWriter, r *http.Request) {
+			s.serve(w, r, path == ticketPath, handler)
+		})
+	}
+	handle("GET /version", s.getVersion)
+	handle("GET /nodes", s.listNodes)
+
+	handle("POST "+ticketPath, s.createTicket)
+	handle("GET /access/permissions", s.getPermissions)
+	handle("PUT /access/password", s.updatePassword)
+	handle("GET /access/groups", s.listGroups)
+	handle("POST /access/groups", s.createGroup)
+	handle("GET /access/groups/{groupid}", s.getGroup)
+	handle("PUT /access/groups/{groupid}", s.updateGroup)
+	handle("DELETE /access/groups/{groupid}", s.deleteGroup)
+	handle("GET /access/users", s.listUsers)
+	handle("POST /access/users", s.createUser)
+	handle("GET /access/users/{userid}", s.getUser)
+	handle("PUT /access/users/{userid}", s.updateUser)
+	handle("DELETE /access/users/{userid}", s.deleteUser)
+	handle("GET /access/users/{userid}/token", s.listTokens)
+	handle("GET /access/users/{userid}/token/{tokenid}", s.getToken)
+	handle("POST /access/users/{userid}/token/{tokenid}", s.createToken)
+	handle("PUT /access/users/{userid}/token/{tokenid}", s.updateToken)
+	handle("DELETE /access/users/{userid}/token/{tokenid}", s.deleteToken)
+
+	handle("GET /cluster/nextid", s.getNextID)
+	handle("GET /cluster/resources", s.listResources)
+	handle("GET /cluster/tasks", s.listTasks)
+	handle("POST /cluster/ha/resources", s.createHaResource)
+	handle("GET /cluster/ha/resources/{sid}", s.getHaResource)
+	handle("PUT /cluster/ha/resources/{sid}", s.updateHaResource)
+	handle("DELETE /cluster/ha/resources/{sid}", s.deleteHaResource)
+	handle("GET /cluster/ha/rules", s.listHaRules)
+	handle("POST /cluster/ha/rules", s.createHaRule)
+	handle("GET /cluster/ha/rules/{rule}", s.getHaRule)
+	handle("PUT /cluster/ha/rules/{rule}", s.updateHaRule)
+	handle("DELETE /cluster/ha/rules/{rule}", s.deleteHaRule)
+
+	handle("GET /pools", s.listPools)
+	handle("POST /pools", s.createPool)
+	handle("GET /pools/{poolid}", s.getPool)
+	handle("PUT /pools/{poolid}", s.updatePool)
+	handle("DELETE /pools/{poolid}", s.deletePool)
+
+	handle("GET /nodes/{node}/tasks/{upid}/log", s.getTaskLog)
+	handle("GET /nodes/{node}/tasks/{upid}/status", s.getTaskStatus)
+	handle("DELETE /nodes/{node}/tasks/{upid}", s.cancelTask)
+
+	for _, kind := range []GuestType{GuestLxc, GuestQemu} {
+		guests := "/nodes/{node}/" + string(kind)
+		handle("POST "+guests, s.createGuest(kind))
+		handle("DELETE "+guests+"/{vmid}", s.deleteGuest(kind))
+		handle("POST "+guests+"/{vmid}/clone", s.cloneGuest(kind))
+		handle("GET "+guests+"/{vmid}/config", s.getGuestConfig(kind))
+		handle("PUT "+guests+"/{vmid}/config", s.updateGuestConfig(kind, false))
+		handle("GET "+guests+"/{vmid}/feature", s.getGuestFeature(kind))
+		handle("GET "+guests+"/{vmid}/pending", s.getGuestPending(kind))
+		handle("POST "+guests+"/{vmid}/migrate", s.migrateGuest(kind))
+		handle("PUT "+guests+"/{vmid}/resize", s.resizeGuestDisk(kind))
+		handle("GET "+guests+"/{vmid}/status/current", s.getGuestStatus(kind))
+		handle("POST "+guests+"/{vmid}/status/{action}", s.updateGuestStatus(kind))
+		handle("GET "+guests+"/{vmid}/snapshot", s.listSnapshots(kind))
+		handle("POST "+guests+"/{vmid}/snapshot", s.createSnapshot(kind))
+		handle("DELETE "+guests+"/{vmid}/snapshot/{snapname}", s.deleteSnapshot(kind))
+		handle("GET "+guests+"/{vmid}/snapshot/{snapname}/config", s.getSnapshotConfig(kind))
+		handle("PUT "+guests+"/{vmid}/snapshot/{snapname}/config", s.updateSnapshotConfig(kind))
+		handle("POST "+guests+"/{vmid}/snapshot/{snapname}/rollback", s.rollbackSnapshot(kind))
+		handle("POST "+guests+"/{vmid}/template", s.templateGuest(kind))
+	}
+	handle("POST /nodes/{node}/qemu/{vmid}/config", s.updateGuestConfig(GuestQemu, true))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, nil, &apiError{
+			status:  http.StatusNotImplemented,
+			message: "Method '" + r.Method + " " + r.URL.Path + "' not implemented"})
+	})
+	return mux
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, public bool, handler handlerFunc) {
+	if err := r.ParseForm(); err != nil {
+		reply(w, nil, &apiError{status: http.StatusBadRequest, message: err.Error()})
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.settleTasks()
+	req := &request{Request: r}
+	if !public {
+		var err *apiError
+		if req.user, err = s.authenticate(r); err != nil {
+			reply(w, nil, err)
+			return
+		}
+	}
+	data, err := handler(req)
+	reply(w, data, err)
+}
+
+func reply(w http.ResponseWriter, data any, err *apiError) {
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	if err == nil {
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+		return
+	}
+	body := map[string]any{"data": nil, "message": err.message + "\n"}
+	if err.errors != nil {
+		body["errors"] = err.errors
+	}
+	w.WriteHeader(err.status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func randomHex(bytes int) string {
+	b := make([]byte, bytes)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func splitList(s string) []string {
+	var values []string
+	for value := range strings.FieldsFuncSeq(s, func(r rune) bool { return r == ',' || r == ';' || r == ' ' }) {
+		values = append(values, value)
+	}
+	return values
+}
+
+func sortedKeys[K ~string | ~uint, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
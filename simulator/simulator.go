@@ -0,0 +1,228 @@
+// Package simulator provides a stateful in-memory Proxmox VE API server, so the SDK and the CLI can be tested end-to-end without a cluster.
+//
+// Where internal/mockServer replies with canned responses, the simulator keeps track of nodes, guests, snapshots, pools,
+// users, API tokens, groups, HA rules and tasks, and enforces the basic semantics of Proxmox VE:
+// guest IDs are unique, locked guests can't be modified and protected guests can't be deleted.
+// Requests that start a task in Proxmox VE return a UPID, the effects of the task are applied immediately.
+//
+//	sim := simulator.New(t)
+//	client, err := proxmox.NewClient(sim.Url(), nil, "", &tls.Config{InsecureSkipVerify: true}, "", 300, false)
+//	err = client.Login(ctx, simulator.RootUser, simulator.RootPassword, "")
+package simulator
+
+import (
+	"errors"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Telmate/proxmox-api-go/internal/mockServer"
+)
+
+const (
+	DefaultNode  = "pve"
+	RootUser     = "root@pam"
+	RootPassword = "simulator"
+	// The Proxmox VE version reported by the simulator.
+	Version = "9.0.10"
+)
+
+const (
+	Server_Error_GuestExists = "simulator: guest ID already in use: "
+	Server_Error_GuestID     = "simulator: guest ID must be 100 or greater"
+	Server_Error_GuestType   = "simulator: unknown guest type: "
+	Server_Error_NoGuest     = "simulator: no such guest: "
+	Server_Error_NoNode      = "simulator: no such node: "
+)
+
+// GuestType is an enum.
+type GuestType string
+
+const (
+	GuestLxc  GuestType = "lxc"
+	GuestQemu GuestType = "qemu"
+)
+
+// Guest is the state of a simulated guest.
+type Guest struct {
+	ID   uint
+	Node string // Defaults to DefaultNode.
+	Type GuestType
+	// The configuration as it's sent to the API, e.g. "memory": "2048".
+	Config  map[string]string
+	Running bool
+	// Locked guests can't be modified, e.g. "backup".
+	Lock string
+	// Pool the guest is a member of, it's created when it does not exist.
+	Pool string
+	// Ordered from oldest to newest, the guest is at the newest snapshot.
+	Snapshots []Snapshot
+}
+
+func (g Guest) clone() Guest {
+	g.Config = maps.Clone(g.Config)
+	if g.Config == nil {
+		g.Config = map[string]string{}
+	}
+	g.Snapshots = slices.Clone(g.Snapshots)
+	for i := range g.Snapshots {
+		g.Snapshots[i].Config = maps.Clone(g.Snapshots[i].Config)
+	}
+	return g
+}
+
+// Snapshot is a snapshot of a simulated guest.
+type Snapshot struct {
+	Name        string
+	Description string
+	Parent      string
+	Time        time.Time
+	VmState     bool
+	// The configuration of the guest when the snapshot was taken.
+	Config map[string]string
+}
+
+// Task is a task started by a request.
+type Task struct {
+	UPID       string
+	Type       string // e.g. "qmstart"
+	ID         string // ID of the guest the task is about, if any.
+	User       string
+	ExitStatus string // Empty while the task is running.
+}
+
+// Server is a simulated Proxmox VE cluster, it is safe for concurrent use.
+type Server struct {
+	listener net.Listener
+	server   *http.Server
+
+	mutex        sync.Mutex
+	groups       map[string]*group
+	guests       map[uint]*guest
+	haResources  map[uint]map[string]string
+	haRules      map[string]map[string]string
+	nodes        map[string]struct{}
+	pools        map[string]*pool
+	sessions     map[string]session
+	taskDuration time.Duration
+	tasks        []*task
+	users        map[string]*user
+}
+
+// New starts a simulated cluster with a single node named DefaultNode and the user RootUser.
+// The server is closed when the test finishes.
+func New(t *testing.T) *Server {
+	s := &Server{
+		listener:    mockServer.NewListener(t),
+		groups:      map[string]*group{},
+		guests:      map[uint]*guest{},
+		haResources: map[uint]map[string]string{},
+		haRules:     map[string]map[string]string{},
+		nodes:       map[string]struct{}{DefaultNode: {}},
+		pools:       map[string]*pool{},
+		sessions:    map[string]session{},
+		users: map[string]*user{RootUser: {
+			config:   map[string]string{},
+			password: RootPassword,
+			tokens:   map[string]*token{}}}}
+	s.server = &http.Server{Handler: s.routes()}
+	go func() { // Serve blocks until the server is closed
+		_ = s.server.Serve(s.listener)
+	}()
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Url returns the API url of the simulated cluster, to pass to proxmox.NewClient.
+func (s *Server) Url() string { return "https://" + s.listener.Addr().String() + apiPath }
+
+func (s *Server) Close() { _ = s.server.Close() }
+
+// AddNode adds a node to the cluster.
+func (s *Server) AddNode(name string) {
+	s.mutex.Lock()
+	s.nodes[name] = struct{}{}
+	s.mutex.Unlock()
+}
+
+// AddGuest adds a guest to the cluster, without starting a task.
+func (s *Server) AddGuest(g Guest) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if g.Type != GuestLxc && g.Type != GuestQemu {
+		return errors.New(Server_Error_GuestType + string(g.Type))
+	}
+	if g.ID < 100 {
+		return errors.New(Server_Error_GuestID)
+	}
+	if _, exists := s.guests[g.ID]; exists {
+		return errors.New(Server_Error_GuestExists + strconv.FormatUint(uint64(g.ID), 10))
+	}
+	if g.Node == "" {
+		g.Node = DefaultNode
+	}
+	if _, exists := s.nodes[g.Node]; !exists {
+		return errors.New(Server_Error_NoNode + g.Node)
+	}
+	if _, exists := s.pools[g.Pool]; g.Pool != "" && !exists {
+		s.pools[g.Pool] = &pool{storages: map[string]struct{}{}}
+	}
+	added := &guest{Guest: g.clone()}
+	if added.Running {
+		added.started = time.Now()
+	}
+	if len(added.Snapshots) > 0 {
+		added.parent = added.Snapshots[len(added.Snapshots)-1].Name
+	}
+	s.guests[g.ID] = added
+	return nil
+}
+
+// Guest returns a copy of the current state of the guest.
+func (s *Server) Guest(id uint) (Guest, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.settleTasks()
+	g, exists := s.guests[id]
+	if !exists {
+		return Guest{}, false
+	}
+	return g.clone(), true
+}
+
+// SetLock sets the lock of the guest, an empty lock unlocks the guest.
+func (s *Server) SetLock(id uint, lock string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	g, exists := s.guests[id]
+	if !exists {
+		return errors.New(Server_Error_NoGuest + strconv.FormatUint(uint64(id), 10))
+	}
+	g.Lock = lock
+	return nil
+}
+
+// SetTaskDuration sets how long tasks keep running, the default of 0 finishes tasks immediately.
+// While a task runs, the guest it is about stays locked.
+func (s *Server) SetTaskDuration(d time.Duration) {
+	s.mutex.Lock()
+	s.taskDuration = d
+	s.mutex.Unlock()
+}
+
+// Tasks returns all tasks started so far, oldest first.
+func (s *Server) Tasks() []Task {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.settleTasks()
+	tasks := make([]Task, len(s.tasks))
+	for i := range s.tasks {
+		tasks[i] = s.tasks[i].Task
+	}
+	return tasks
+}
@@ -0,0 +1,594 @@
+package simulator_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"iter"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Telmate/proxmox-api-go/cli"
+	_ "github.com/Telmate/proxmox-api-go/cli/command/commands"
+	"github.com/Telmate/proxmox-api-go/internal/util"
+	"github.com/Telmate/proxmox-api-go/proxmox"
+	"github.com/Telmate/proxmox-api-go/simulator"
+	"github.com/stretchr/testify/require"
+)
+
+func testClient(t *testing.T) (*simulator.Server, *proxmox.Client) {
+	sim := simulator.New(t)
+	c, err := proxmox.NewClient(sim.Url(), nil, "", &tls.Config{InsecureSkipVerify: true}, "", 10, false)
+	require.NoError(t, err)
+	require.NoError(t, c.Login(context.Background(), simulator.RootUser, simulator.RootPassword, ""))
+	return sim, c
+}
+
+func qemuRef(id proxmox.GuestID) proxmox.VmRef {
+	vmr := proxmox.NewVmRef(id)
+	vmr.SetNode(simulator.DefaultNode)
+	vmr.SetVmType(proxmox.GuestQemu)
+	return *vmr
+}
+
+func Test_Server_AddGuest(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input simulator.Guest
+		err   string
+	}{
+		{name: `Valid`,
+			input: simulator.Guest{ID: 100, Type: simulator.GuestQemu}},
+		{name: `Invalid ID`,
+			input: simulator.Guest{ID: 99, Type: simulator.GuestQemu},
+			err:   simulator.Server_Error_GuestID},
+		{name: `Invalid type`,
+			input: simulator.Guest{ID: 100, Type: "vm"},
+			err:   simulator.Server_Error_GuestType + "vm"},
+		{name: `Invalid node`,
+			input: simulator.Guest{ID: 100, Node: "pve2", Type: simulator.GuestLxc},
+			err:   simulator.Server_Error_NoNode + "pve2"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			sim := simulator.New(t)
+			err := sim.AddGuest(test.input)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualError(t, sim.AddGuest(test.input), simulator.Server_Error_GuestExists+"100")
+			guest, exists := sim.Guest(100)
+			require.True(t, exists)
+			require.Equal(t, simulator.DefaultNode, guest.Node)
+		})
+	}
+}
+
+func Test_Server_Authentication(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	user := proxmox.UserID{Name: "test", Realm: "pve"}
+	require.NoError(t, c.New().User.Create(ctx, proxmox.ConfigUser{User: user}))
+	secret, err := c.New().ApiToken.Create(ctx, user, proxmox.ApiTokenConfig{Name: "token"})
+	require.NoError(t, err)
+	tokenClient, err := proxmox.NewClient(sim.Url(), nil, "", &tls.Config{InsecureSkipVerify: true}, "", 10, false)
+	require.NoError(t, err)
+	tokenClient.SetAPIToken(proxmox.ApiToken{
+		ID:     proxmox.ApiTokenID{User: user, TokenName: "token"},
+		Secret: secret})
+	version, err := tokenClient.GetVersion(ctx)
+	require.NoError(t, err)
+	require.Equal(t, proxmox.Version{Major: 9, Minor: 0, Patch: 10}, version)
+	// the token stops working once it's deleted
+	deleted, err := c.New().ApiToken.Delete(ctx, proxmox.ApiTokenID{User: user, TokenName: "token"})
+	require.NoError(t, err)
+	require.True(t, deleted)
+	_, err = tokenClient.GetVersion(ctx)
+	require.Error(t, err)
+	// a wrong password is rejected
+	require.Error(t, c.Login(ctx, simulator.RootUser, "wrong", ""))
+}
+
+func Test_Server_Group(t *testing.T) {
+	t.Parallel()
+	_, c := testClient(t)
+	ctx := context.Background()
+	users := []proxmox.UserID{{Name: "a", Realm: "pve"}, {Name: "b", Realm: "pve"}}
+	for _, user := range users {
+		require.NoError(t, c.New().User.Create(ctx, proxmox.ConfigUser{User: user}))
+	}
+	require.NoError(t, c.New().Group.Create(ctx, proxmox.ConfigGroup{
+		Name:    "admins",
+		Comment: util.Pointer("test"),
+		Members: &users}))
+	raw, err := c.New().Group.Read(ctx, "admins")
+	require.NoError(t, err)
+	require.Equal(t, "test", raw.GetComment())
+	require.ElementsMatch(t, users, raw.GetMembers())
+	rawUser, err := c.New().User.Read(ctx, users[0])
+	require.NoError(t, err)
+	require.Equal(t, &[]proxmox.GroupName{"admins"}, rawUser.GetGroups())
+	deleted, err := c.New().Group.Delete(ctx, "admins")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	deleted, err = c.New().Group.Delete(ctx, "admins")
+	require.NoError(t, err)
+	require.False(t, deleted)
+}
+
+func Test_Server_Pool(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	require.NoError(t, c.New().Pool.Create(ctx, proxmox.ConfigPool{Name: "test", Comment: util.Pointer("comment")}))
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 100, Type: simulator.GuestQemu}))
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 101, Type: simulator.GuestLxc, Pool: "other"}))
+	require.NoError(t, c.New().Pool.AddMembers(ctx, "test", []proxmox.GuestID{100, 101}, []proxmox.StorageName{"local"}))
+	raw, err := c.New().Pool.Read(ctx, "test")
+	require.NoError(t, err)
+	require.Equal(t, "comment", raw.GetComment())
+	guests, storages := raw.GetMembers().AsArrays()
+	require.Len(t, guests, 2)
+	require.Len(t, storages, 1)
+	guest, _ := sim.Guest(101)
+	require.Equal(t, "test", guest.Pool)
+	// a pool that is not empty is emptied before it is deleted
+	deleted, err := c.New().Pool.Delete(ctx, "test")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	exists, err := c.New().Pool.Exists(ctx, "test")
+	require.NoError(t, err)
+	require.False(t, exists)
+	guest, _ = sim.Guest(101)
+	require.Empty(t, guest.Pool)
+}
+
+func Test_GuestInterface_Delete(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		guest   *simulator.Guest
+		ha      bool
+		deleted bool
+		err     string
+	}{
+		{name: `Stopped`,
+			guest:   &simulator.Guest{ID: 100, Type: simulator.GuestQemu},
+			deleted: true},
+		{name: `Running`,
+			guest:   &simulator.Guest{ID: 100, Type: simulator.GuestLxc, Running: true},
+			deleted: true},
+		{name: `Running HA`,
+			guest:   &simulator.Guest{ID: 100, Type: simulator.GuestQemu, Running: true},
+			ha:      true,
+			deleted: true},
+		{name: `Stopped HA`,
+			guest:   &simulator.Guest{ID: 100, Type: simulator.GuestQemu},
+			ha:      true,
+			deleted: true},
+		{name: `Protected`,
+			guest: &simulator.Guest{ID: 100, Type: simulator.GuestQemu, Config: map[string]string{"protection": "1"}},
+			err:   "cannot delete guest because it is protected: ID 100"},
+		{name: `Locked`,
+			guest: &simulator.Guest{ID: 100, Type: simulator.GuestQemu, Lock: "backup"},
+			err:   "VM is locked (backup)"},
+		{name: `Does not exist`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			sim, c := testClient(t)
+			ctx := context.Background()
+			if test.guest != nil {
+				require.NoError(t, sim.AddGuest(*test.guest))
+			}
+			if test.ha {
+				_, err := c.UpdateVMHA(ctx, util.Pointer(qemuRef(100)), "started", "")
+				require.NoError(t, err)
+			}
+			deleted, err := c.New().Guest.Delete(ctx, *proxmox.NewVmRef(100))
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				_, exists := sim.Guest(100)
+				require.True(t, exists)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.deleted, deleted)
+			_, exists := sim.Guest(100)
+			require.False(t, exists)
+		})
+	}
+}
+
+func Test_GuestInterface_Power(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 100, Type: simulator.GuestQemu}))
+	vmr := qemuRef(100)
+	require.NoError(t, c.New().Guest.Start(ctx, vmr))
+	guest, _ := sim.Guest(100)
+	require.True(t, guest.Running)
+	require.NoError(t, c.New().Guest.Reboot(ctx, vmr))
+	require.NoError(t, c.New().Guest.Shutdown(ctx, vmr))
+	guest, _ = sim.Guest(100)
+	require.False(t, guest.Running)
+	require.ErrorContains(t, c.New().Guest.Shutdown(ctx, vmr), "VM 100 not running")
+	require.NoError(t, sim.SetLock(100, "backup"))
+	err := c.New().Guest.Start(ctx, vmr)
+	require.ErrorContains(t, err, "VM is locked (backup)")
+	require.ErrorIs(t, err, proxmox.Error.GuestLocked())
+	var types []string
+	for _, task := range sim.Tasks() {
+		require.Equal(t, "OK", task.ExitStatus)
+		require.Equal(t, "100", task.ID)
+		types = append(types, task.Type)
+	}
+	require.Equal(t, []string{"qmstart", "qmreboot", "qmshutdown"}, types)
+}
+
+func Test_GuestInterface_Batch(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	sim.AddNode("pve2")
+	guests := make([]proxmox.VmRef, 0)
+	for id := uint(100); id < 110; id++ {
+		require.NoError(t, sim.AddGuest(simulator.Guest{ID: id, Type: simulator.GuestQemu, Config: map[string]string{"tags": "prod"}}))
+		guests = append(guests, *proxmox.NewVmRef(proxmox.GuestID(id)))
+	}
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 110, Type: simulator.GuestLxc, Running: true}))
+	guests = append(guests, *proxmox.NewVmRef(110))
+
+	result, err := c.New().Guest.Batch(ctx, guests, proxmox.GuestBatch{Action: proxmox.GuestBatchAction_Start, Concurrency: 3})
+	require.NoError(t, err)
+	require.Len(t, result, 11)
+	for id := uint(100); id <= 110; id++ {
+		guest, _ := sim.Guest(id)
+		require.True(t, guest.Running)
+	}
+
+	_, err = c.New().Guest.Batch(ctx, guests, proxmox.GuestBatch{
+		Action: proxmox.GuestBatchAction_Tags,
+		Tags:   &proxmox.GuestBatchTags{Add: proxmox.Tags{"patched"}, Remove: proxmox.Tags{"prod"}}})
+	require.NoError(t, err)
+	guest, _ := sim.Guest(100)
+	require.Equal(t, "patched", guest.Config["tags"])
+
+	_, err = c.New().Guest.Batch(ctx, guests[:2], proxmox.GuestBatch{
+		Action:  proxmox.GuestBatchAction_Migrate,
+		Migrate: &proxmox.GuestBatchMigrate{Node: "pve2", Online: true}})
+	require.NoError(t, err)
+	guest, _ = sim.Guest(101)
+	require.Equal(t, "pve2", guest.Node)
+
+	// the locked guest fails, all guests after it are skipped
+	require.NoError(t, sim.SetLock(100, "backup"))
+	result, err = c.New().Guest.Batch(ctx, append(guests, *proxmox.NewVmRef(999)), proxmox.GuestBatch{
+		Action:      proxmox.GuestBatchAction_Snapshot,
+		Concurrency: 1,
+		FailFast:    true,
+		Snapshot:    &proxmox.GuestBatchSnapshot{Name: "patch"}})
+	require.ErrorIs(t, err, proxmox.Error.GuestLocked())
+	require.ErrorIs(t, result[100], proxmox.Error.GuestLocked())
+	require.ErrorIs(t, result[101], proxmox.Error.GuestBatchSkipped())
+	require.ErrorIs(t, result[999], proxmox.Error.NotFound())
+	require.Len(t, result.Failed(), 12)
+
+	// without fail-fast the other guests are processed
+	result, err = c.New().Guest.Batch(ctx, guests, proxmox.GuestBatch{
+		Action:   proxmox.GuestBatchAction_Snapshot,
+		Snapshot: &proxmox.GuestBatchSnapshot{Name: "patch"}})
+	require.Error(t, err)
+	require.Equal(t, []proxmox.GuestID{100}, result.Failed())
+	guest, _ = sim.Guest(110)
+	require.Len(t, guest.Snapshots, 1)
+}
+
+func Test_QemuGuestInterface_Create(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 100, Type: simulator.GuestLxc}))
+	vmr, err := c.New().QemuGuest.Create(ctx, proxmox.ConfigQemu{
+		Name:   util.Pointer(proxmox.GuestName("test")),
+		Node:   util.Pointer(proxmox.NodeName(simulator.DefaultNode)),
+		CPU:    &proxmox.QemuCPU{Cores: util.Pointer(proxmox.QemuCpuCores(2))},
+		Memory: &proxmox.QemuMemory{CapacityMiB: util.Pointer(proxmox.QemuMemoryCapacity(1024))},
+		State:  util.Pointer(proxmox.PowerStateRunning)})
+	require.NoError(t, err)
+	require.Equal(t, proxmox.GuestID(101), vmr.VmId())
+	guest, exists := sim.Guest(101)
+	require.True(t, exists)
+	require.True(t, guest.Running)
+	require.Equal(t, "2", guest.Config["cores"])
+	config, err := proxmox.NewConfigQemuFromApi(ctx, vmr, c)
+	require.NoError(t, err)
+	require.Equal(t, proxmox.GuestName("test"), *config.Name)
+	require.Equal(t, proxmox.QemuCpuCores(2), *config.CPU.Cores)
+	require.Equal(t, proxmox.QemuMemoryCapacity(1024), *config.Memory.CapacityMiB)
+	// the guest ID is already in use
+	_, err = c.New().QemuGuest.Create(ctx, proxmox.ConfigQemu{
+		ID:     util.Pointer(proxmox.GuestID(101)),
+		Node:   util.Pointer(proxmox.NodeName(simulator.DefaultNode)),
+		CPU:    &proxmox.QemuCPU{Cores: util.Pointer(proxmox.QemuCpuCores(1))},
+		Memory: &proxmox.QemuMemory{CapacityMiB: util.Pointer(proxmox.QemuMemoryCapacity(512))}})
+	require.ErrorContains(t, err, "unable to create VM 101 - VM 101 already exists on node 'pve'")
+	require.ErrorIs(t, err, proxmox.Error.AlreadyExists())
+}
+
+func Test_LxcGuestInterface(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	lxc := c.New().LxcGuest
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 100, Type: simulator.GuestQemu}))
+	vmr, err := lxc.Create(ctx, proxmox.ConfigLXC{
+		BootMount: &proxmox.LxcBootMount{
+			SizeInKibibytes: util.Pointer(proxmox.LxcMountSize(4 * 1024 * 1024)),
+			Storage:         util.Pointer("local-lvm")},
+		CreateOptions: &proxmox.LxcCreateOptions{
+			OsTemplate: &proxmox.LxcTemplate{Storage: "local", File: "vztmpl/debian.tar.zst"}},
+		Memory: util.Pointer(proxmox.LxcMemory(512)),
+		Name:   util.Pointer(proxmox.GuestName("test")),
+		Node:   util.Pointer(proxmox.NodeName(simulator.DefaultNode))})
+	require.NoError(t, err)
+	require.Equal(t, proxmox.GuestID(101), vmr.VmId())
+	require.NoError(t, lxc.Update(ctx, *proxmox.NewVmRef(101), false, proxmox.ConfigLXC{
+		Memory: util.Pointer(proxmox.LxcMemory(1024))}))
+	raw, err := lxc.Read(ctx, *proxmox.NewVmRef(101))
+	require.NoError(t, err)
+	require.Equal(t, proxmox.LxcMemory(1024), raw.GetMemory())
+	require.Equal(t, proxmox.GuestName("test"), raw.GetName())
+	// convert to a template, a second time is a no-op
+	require.NoError(t, lxc.Template(ctx, *proxmox.NewVmRef(101)))
+	require.NoError(t, lxc.Template(ctx, *proxmox.NewVmRef(101)))
+	guest, _ := sim.Guest(101)
+	require.Equal(t, "1", guest.Config["template"])
+	clone, err := lxc.Clone(ctx, *proxmox.NewVmRef(101), proxmox.CloneLxcTarget{Linked: &proxmox.CloneLinked{
+		Node: simulator.DefaultNode,
+		Name: util.Pointer(proxmox.GuestName("clone"))}})
+	require.NoError(t, err)
+	require.Equal(t, proxmox.GuestID(102), clone.VmId())
+	raw, err = lxc.Read(ctx, *clone)
+	require.NoError(t, err)
+	require.Equal(t, proxmox.GuestName("clone"), raw.GetName())
+	require.Equal(t, proxmox.LxcMemory(1024), raw.GetMemory())
+	// the guest is not a container
+	_, err = lxc.Read(ctx, *proxmox.NewVmRef(100))
+	require.EqualError(t, err, proxmox.VmRef_Error_GuestType)
+	require.EqualError(t, lxc.Template(ctx, *proxmox.NewVmRef(100)), proxmox.VmRef_Error_GuestType)
+}
+
+func Test_QemuGuestInterface_CheckUpdatePermissions(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 100, Type: simulator.GuestQemu, Config: map[string]string{"cores": "1"}}))
+	check, err := c.New().QemuGuest.CheckUpdatePermissions(ctx, qemuRef(100), proxmox.ConfigQemu{
+		CPU: &proxmox.QemuCPU{Cores: util.Pointer(proxmox.QemuCpuCores(4))}})
+	require.NoError(t, err)
+	require.Equal(t, proxmox.PermissionCheck{
+		Required: []proxmox.Permission{{
+			Category:   proxmox.PermissionCategory_Guest,
+			Item:       "100",
+			Privileges: proxmox.Privileges{VMConfigCPU: true}}},
+		Missing: []proxmox.Permission{}}, check)
+	require.NoError(t, check.Err())
+	guest, _ := sim.Guest(100)
+	require.Equal(t, "1", guest.Config["cores"])
+}
+
+func Test_QemuGuestInterface_Plan(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 100, Type: simulator.GuestQemu, Running: true, Config: map[string]string{"cores": "1", "name": "test"}}))
+	plan, err := c.New().QemuGuest.Plan(ctx, qemuRef(100), proxmox.ConfigQemu{
+		CPU:  &proxmox.QemuCPU{Cores: util.Pointer(proxmox.QemuCpuCores(4))},
+		Name: util.Pointer(proxmox.GuestName("new"))})
+	require.NoError(t, err)
+	require.Equal(t, []proxmox.GuestConfigChange{
+		{Key: "cores", Old: "1", New: "4"},
+		{Key: "name", Old: "test", New: "new"}}, plan.Changes)
+	require.True(t, plan.RebootRequired)
+	require.False(t, plan.ShutdownRequired)
+	guest, _ := sim.Guest(100)
+	require.Equal(t, "1", guest.Config["cores"])
+	require.Equal(t, "test", guest.Config["name"])
+}
+
+func Test_SnapshotInterface(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 100, Type: simulator.GuestQemu, Config: map[string]string{"cores": "1"}}))
+	vmr := qemuRef(100)
+	require.NoError(t, c.New().Snapshot.CreateQemu(ctx, vmr, "first", "before", false))
+	require.NoError(t, c.New().QemuGuest.Update(ctx, vmr, false, false, proxmox.ConfigQemu{
+		CPU: &proxmox.QemuCPU{Cores: util.Pointer(proxmox.QemuCpuCores(4))}}))
+	require.NoError(t, c.New().Snapshot.CreateQemu(ctx, vmr, "second", "", false))
+	require.NoError(t, c.New().Snapshot.Update(ctx, vmr, "second", "after"))
+	raw, err := c.New().Snapshot.List(ctx, vmr)
+	require.NoError(t, err)
+	snapshots := raw.AsMap()
+	require.Len(t, snapshots, 3)
+	require.Equal(t, "after", snapshots["second"].GetDescription())
+	require.Equal(t, util.Pointer(proxmox.SnapshotName("first")), snapshots["second"].GetParent())
+	require.NoError(t, c.New().Snapshot.Rollback(ctx, vmr, "first", false))
+	guest, _ := sim.Guest(100)
+	require.Equal(t, "1", guest.Config["cores"])
+	deleted, err := c.New().Snapshot.Delete(ctx, vmr, "first")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	deleted, err = c.New().Snapshot.Delete(ctx, vmr, "first")
+	require.NoError(t, err)
+	require.False(t, deleted)
+	guest, _ = sim.Guest(100)
+	require.Len(t, guest.Snapshots, 1)
+	require.Empty(t, guest.Snapshots[0].Parent)
+}
+
+func Test_TaskInterface_Cancel(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 100, Type: simulator.GuestQemu}))
+	sim.SetTaskDuration(time.Hour)
+	result := make(chan error)
+	go func() { result <- c.New().Snapshot.CreateQemu(ctx, qemuRef(100), "snap", "", false) }()
+	var tasks []simulator.Task
+	require.Eventually(t, func() bool {
+		tasks = sim.Tasks()
+		return len(tasks) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	guest, _ := sim.Guest(100)
+	require.Equal(t, "snapshot", guest.Lock)
+	var upid proxmox.UPID
+	require.NoError(t, upid.Parse(tasks[0].UPID))
+	raw, err := c.New().Task.Read(ctx, upid)
+	require.NoError(t, err)
+	require.False(t, raw.Finished())
+	require.NoError(t, c.New().Task.Cancel(ctx, upid))
+	err = <-result
+	var taskErr *proxmox.TaskError
+	require.ErrorAs(t, err, &taskErr)
+	require.Equal(t, "interrupted by signal", taskErr.Message)
+	guest, _ = sim.Guest(100)
+	require.Empty(t, guest.Lock)
+	log, err := c.New().Task.Log(ctx, upid, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, "TASK ERROR: interrupted by signal", log[len(log)-1].Text)
+}
+
+func Test_HaNodeAffinityRule(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 100, Type: simulator.GuestQemu}))
+	vmr := qemuRef(100)
+	require.NoError(t, proxmox.HaNodeAffinityRule{
+		ID:     "rule",
+		Guests: &[]proxmox.VmRef{vmr},
+		Nodes:  &[]proxmox.HaNode{{Node: simulator.DefaultNode}},
+		Strict: util.Pointer(true)}.Create(ctx, c))
+	raw, err := proxmox.NewHaRuleFromApi(ctx, "rule", c)
+	require.NoError(t, err)
+	rule, ok := raw.GetNodeAffinity()
+	require.True(t, ok)
+	require.True(t, rule.GetStrict())
+	require.True(t, rule.GetEnabled())
+	require.NoError(t, proxmox.HaNodeAffinityRule{
+		ID:      "rule",
+		Enabled: util.Pointer(false),
+		Strict:  util.Pointer(false)}.Update(ctx, c))
+	raw, err = proxmox.NewHaRuleFromApi(ctx, "rule", c)
+	require.NoError(t, err)
+	rule, _ = raw.GetNodeAffinity()
+	require.False(t, rule.GetStrict())
+	require.False(t, rule.GetEnabled())
+	require.NoError(t, proxmox.HaRuleID("rule").Delete(ctx, c))
+	rules, err := proxmox.ListHaRules(ctx, c)
+	require.NoError(t, err)
+	require.Empty(t, rules.ConvertArray())
+}
+
+// Not parallel, the CLI is configured through environment variables.
+func Test_WatcherInterface_Watch(t *testing.T) {
+	t.Parallel()
+	sim, c := testClient(t)
+	ctx := context.Background()
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 100, Type: simulator.GuestQemu}))
+	vmr := qemuRef(100)
+	// an empty state reports everything that already exists as added
+	state := &proxmox.WatchState{Guests: map[proxmox.GuestID]proxmox.WatchGuest{}, Tasks: map[string]proxmox.TaskState{}}
+	filter := proxmox.WatchFilter{Interval: 10 * time.Millisecond, State: state}
+	next, stop := iter.Pull2(c.New().Watcher.Watch(ctx, filter))
+	event, err, _ := next()
+	require.NoError(t, err)
+	require.Equal(t, proxmox.WatchEventType_GuestAdded, event.Type)
+	require.Equal(t, proxmox.PowerStateStopped, event.Guest.State)
+
+	require.NoError(t, c.New().Guest.Start(ctx, vmr))
+	var types []proxmox.WatchEventType
+	for range 3 {
+		event, err, _ = next()
+		require.NoError(t, err)
+		types = append(types, event.Type)
+	}
+	require.Equal(t, []proxmox.WatchEventType{
+		proxmox.WatchEventType_PowerStateChanged,
+		proxmox.WatchEventType_TaskStarted,
+		proxmox.WatchEventType_TaskFinished}, types)
+	require.Equal(t, "qmstart", event.Task.UPID.Type)
+	require.Equal(t, "OK", event.Task.ExitStatus)
+	stop()
+
+	// changes made while not watching are reported when resuming from the stored state
+	raw, err := json.Marshal(state)
+	require.NoError(t, err)
+	require.NoError(t, c.New().Guest.Shutdown(ctx, vmr))
+	var resumed proxmox.WatchState
+	require.NoError(t, json.Unmarshal(raw, &resumed))
+	filter.Events = []proxmox.WatchEventType{proxmox.WatchEventType_PowerStateChanged}
+	filter.State = &resumed
+	for event, err := range c.New().Watcher.Watch(ctx, filter) {
+		require.NoError(t, err)
+		require.Equal(t, proxmox.PowerStateRunning, event.Previous.State)
+		require.Equal(t, proxmox.PowerStateStopped, event.Guest.State)
+		break
+	}
+
+	for _, err := range c.New().Watcher.Watch(ctx, proxmox.WatchFilter{Interval: -1}) {
+		require.Equal(t, errors.New(proxmox.WatchFilter_Error_Interval), err)
+	}
+}
+
+func Test_Cli(t *testing.T) {
+	sim := simulator.New(t)
+	t.Setenv("PM_API_URL", sim.Url())
+	t.Setenv("PM_USER", simulator.RootUser)
+	t.Setenv("PM_PASS", simulator.RootPassword)
+	run := func(input string, args ...string) string {
+		buffer := new(bytes.Buffer)
+		cli.RootCmd.SetArgs(append(args, "--insecure"))
+		cli.RootCmd.SetIn(strings.NewReader(input))
+		cli.RootCmd.SetOut(buffer)
+		require.NoError(t, cli.RootCmd.Execute())
+		return buffer.String()
+	}
+	require.Contains(t, run(`{"comment":"test"}`, "create", "pool", "cli-pool"), "cli-pool")
+	require.Contains(t, run("", "list", "pools"), `"cli-pool"`)
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 100, Type: simulator.GuestQemu}))
+	run("", "guest", "start", "100")
+	guest, _ := sim.Guest(100)
+	require.True(t, guest.Running)
+	require.NoError(t, sim.AddGuest(simulator.Guest{ID: 101, Type: simulator.GuestLxc}))
+	require.Contains(t, run("", "guest", "batch", "tags", "100", "101", "--add", "a,b"), `"101": "ok"`)
+	guest, _ = sim.Guest(101)
+	require.Equal(t, "a,b", guest.Config["tags"])
+	// profiles, last as flags keep their value between runs
+	t.Setenv(proxmox.ProfilesPathEnv, filepath.Join(t.TempDir(), "profiles.json"))
+	profile, err := json.Marshal(proxmox.Profile{
+		ApiUrls:     []string{sim.Url()},
+		Credentials: proxmox.ProfileCredentials{User: simulator.RootUser, Password: simulator.RootPassword},
+		TLS:         proxmox.ProfileTLS{Insecure: true}})
+	require.NoError(t, err)
+	require.Contains(t, run(string(profile), "config", "set", "sim"), "sim")
+	require.Contains(t, run(`{"api_urls":["https://127.0.0.1:1"],"credentials":{"user":"root@pam"}}`, "config", "set", "other"), "other")
+	require.Contains(t, run("", "config", "use", "sim"), "sim")
+	require.Contains(t, run("", "config", "list"), `"current": true`)
+	t.Setenv("PM_API_URL", "")
+	require.Contains(t, run("", "list", "pools"), `"cli-pool"`)
+	require.Contains(t, run("", "list", "pools", "--profile", "sim"), `"cli-pool"`)
+}
@@ -0,0 +1,160 @@
+package simulator
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type task struct {
+	Task
+	node     string
+	pid      int
+	started  time.Time
+	finishes time.Time
+	// The guest that stays locked while the task runs, if any.
+	guest *guest
+	lock  string
+	// The exit status of the task once it finishes.
+	result string
+	log    []string
+}
+
+// startTask starts a task and returns its UPID.
+// A non-empty failure makes the task fail with that message, the guest is locked with lock until the task finishes.
+func (s *Server) startTask(r *request, node, taskType, id string, lockGuest *guest, lock, failure string) string {
+	now := time.Now()
+	pid := len(s.tasks) + 1
+	t := &task{
+		Task: Task{
+			UPID: fmt.Sprintf("UPID:%s:%08X:%08X:%08X:%s:%s:%s:", node, pid, pid, now.Unix(), taskType, id, r.user),
+			Type: taskType,
+			ID:   id,
+			User: r.user},
+		node:     node,
+		pid:      pid,
+		started:  now,
+		finishes: now.Add(s.taskDuration),
+		result:   "OK"}
+	if failure != "" {
+		t.result = failure
+	}
+	if lockGuest != nil && lock != "" && s.taskDuration > 0 {
+		t.guest = lockGuest
+		t.lock = lock
+		lockGuest.Lock = lock
+	}
+	s.tasks = append(s.tasks, t)
+	s.settleTasks()
+	return t.UPID
+}
+
+// settleTasks finishes all tasks that have run for long enough.
+func (s *Server) settleTasks() {
+	now := time.Now()
+	for _, t := range s.tasks {
+		if t.ExitStatus == "" && !now.Before(t.finishes) {
+			t.finish(t.result)
+		}
+	}
+}
+
+func (t *task) finish(exitStatus string) {
+	t.ExitStatus = exitStatus
+	if exitStatus == "OK" {
+		t.log = append(t.log, "TASK OK")
+	} else {
+		t.log = append(t.log, "TASK ERROR: "+exitStatus)
+	}
+	if t.guest != nil && t.guest.Lock == t.lock {
+		t.guest.Lock = ""
+	}
+}
+
+func (s *Server) task(r *request) (*task, *apiError) {
+	upid := r.PathValue("upid")
+	for _, t := range s.tasks {
+		if t.UPID == upid && t.node == r.PathValue("node") {
+			return t, nil
+		}
+	}
+	return nil, &apiError{status: http.StatusBadRequest, message: "no such task"}
+}
+
+func (s *Server) getTaskStatus(r *request) (any, *apiError) {
+	t, err := s.task(r)
+	if err != nil {
+		return nil, err
+	}
+	status := map[string]any{
+		"upid":      t.UPID,
+		"node":      t.node,
+		"pid":       t.pid,
+		"pstart":    t.pid,
+		"starttime": t.started.Unix(),
+		"type":      t.Type,
+		"id":        t.ID,
+		"user":      t.User,
+		"status":    "running"}
+	if t.ExitStatus != "" {
+		status["status"] = "stopped"
+		status["exitstatus"] = t.ExitStatus
+	}
+	return status, nil
+}
+
+// listTasks returns the tasks of the cluster, the most recent first.
+func (s *Server) listTasks(r *request) (any, *apiError) {
+	tasks := make([]map[string]any, 0, len(s.tasks))
+	for i := len(s.tasks) - 1; i >= 0; i-- {
+		t := s.tasks[i]
+		entry := map[string]any{
+			"upid":      t.UPID,
+			"node":      t.node,
+			"pid":       t.pid,
+			"pstart":    t.pid,
+			"starttime": t.started.Unix(),
+			"type":      t.Type,
+			"id":        t.ID,
+			"user":      t.User}
+		if t.ExitStatus != "" {
+			entry["endtime"] = t.finishes.Unix()
+			entry["status"] = t.ExitStatus
+		}
+		tasks = append(tasks, entry)
+	}
+	return tasks, nil
+}
+
+func (s *Server) getTaskLog(r *request) (any, *apiError) {
+	t, err := s.task(r)
+	if err != nil {
+		return nil, err
+	}
+	var start, limit int
+	if _, err := fmt.Sscan(r.param("start"), &start); r.Form.Has("start") && err != nil {
+		return nil, parameterError("start", "value must be an integer")
+	}
+	if _, err := fmt.Sscan(r.param("limit"), &limit); r.Form.Has("limit") && err != nil {
+		return nil, parameterError("limit", "value must be an integer")
+	}
+	if limit == 0 {
+		limit = 50
+	}
+	lines := []map[string]any{}
+	for i := start; i < len(t.log) && len(lines) < limit; i++ {
+		lines = append(lines, map[string]any{"n": i + 1, "t": t.log[i]})
+	}
+	return lines, nil
+}
+
+func (s *Server) cancelTask(r *request) (any, *apiError) {
+	t, err := s.task(r)
+	if err != nil {
+		return nil, err
+	}
+	if t.ExitStatus == "" {
+		t.finish("interrupted by signal")
+	}
+	return nil, nil
+}
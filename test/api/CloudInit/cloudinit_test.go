@@ -35,7 +35,10 @@ func Test_Cloud_Init_VM(t *testing.T) {
 	_, err = Test.GetClient().New().QemuGuest.Create(context.Background(), config)
 	require.NoError(t, err)
 
-	config.Boot = "order=virtio0;ide2;net0"
+	config.Boot = &pxapi.QemuBootOrder{
+		{Disk: new(pxapi.QemuDiskId("virtio0"))},
+		{Disk: new(pxapi.QemuDiskId("ide2"))},
+		{Network: new(pxapi.QemuNetworkInterfaceID0)}}
 
 	config.CloudInit = &pxapi.CloudInit{
 		NetworkInterfaces: pxapi.CloudInitNetworkInterfaces{
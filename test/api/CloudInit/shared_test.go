@@ -33,7 +33,7 @@ func _create_vm_spec(network bool) pxapi.ConfigQemu {
 			Type:    util.Pointer(pxapi.CpuType_QemuKvm64),
 		},
 		QemuKVM: util.Pointer(true),
-		Hotplug: "network,disk,usb",
+		Hotplug: &pxapi.QemuHotplug{CPU: new(false), CloudInit: new(false), Disk: new(true), Memory: new(false), Network: new(true), USB: new(true)},
 
 		Networks: pxapi.QemuNetworkInterfaces{
 			pxapi.QemuNetworkInterfaceID0: pxapi.QemuNetworkInterface{
@@ -41,10 +41,13 @@ func _create_vm_spec(network bool) pxapi.ConfigQemu {
 				Firewall: util.Pointer(true),
 				Model:    util.Pointer(pxapi.QemuNetworkModelVirtIO),
 				MAC:      &mac}},
-		QemuIso:   "none",
-		Boot:      "order=ide2;net0",
-		Scsihw:    "virtio-scsi-pci",
-		QemuDisks: disks,
+		Disks: &pxapi.QemuStorages{Ide: &pxapi.QemuIdeDisks{
+			Disk_2: &pxapi.QemuIdeStorage{CdRom: &pxapi.QemuCdRom{}}}},
+		Boot: &pxapi.QemuBootOrder{
+			{Disk: new(pxapi.QemuDiskId("ide2"))},
+			{Network: new(pxapi.QemuNetworkInterfaceID0)}},
+		ScsiController: new(pxapi.QemuScsiControllerVirtio),
+		QemuDisks:      disks,
 	}
 
 	return config
@@ -61,7 +61,7 @@ func Test_Snapshot_ReadQemu(t *testing.T) {
 				require.NoError(t, err)
 				require.NotNil(t, raw)
 				config, _ := raw.Get(pveSDK.VmRef{})
-				config.Smbios1 = ""
+				config.Smbios = nil
 				expected.Description = &snapshot.Description
 				require.Equal(t, expected, config)
 			}},
@@ -3,6 +3,7 @@ package api_test
 import (
 	"context"
 	"crypto/tls"
+	"net/http"
 
 	pxapi "github.com/Telmate/proxmox-api-go/proxmox"
 	testConstant "github.com/Telmate/proxmox-api-go/test"
@@ -15,6 +16,8 @@ type Test struct {
 	OTP         string
 	HttpHeaders string
 	RequireSSL  bool
+	// Optional, e.g. the client of a cassette.Transport to run the test offline.
+	HttpClient *http.Client
 
 	_client *pxapi.Client
 }
@@ -35,7 +38,7 @@ func (test *Test) CreateClient() (err error) {
 		tlsConfig = nil
 	}
 
-	test._client, err = pxapi.NewClient(test.APIurl, nil, test.HttpHeaders, tlsConfig, "", 300, false)
+	test._client, err = pxapi.NewClient(test.APIurl, test.HttpClient, test.HttpHeaders, tlsConfig, "", 300, false)
 	return err
 }
 
@@ -65,13 +65,13 @@ func ReducedConfig(id pveSDK.GuestID, node pveSDK.NodeName, name pveSDK.GuestNam
 	}
 	expected = &pveSDK.ConfigQemu{
 		Bios: "seabios",
-		Boot: " ",
+		Boot: new(pveSDK.QemuBootOrder{}),
 		CPU: &pveSDK.QemuCPU{
 			Cores:   new(pveSDK.QemuCpuCores(1)),
 			Numa:    new(false),
 			Sockets: new(pveSDK.QemuCpuSockets(1))},
 		Description:     new(""),
-		Hotplug:         "network,disk,usb",
+		Hotplug:         &pveSDK.QemuHotplug{CPU: new(false), CloudInit: new(false), Disk: new(true), Memory: new(false), Network: new(true), USB: new(true)},
 		ID:              &id,
 		Memory:          &pveSDK.QemuMemory{CapacityMiB: new(pveSDK.QemuMemoryCapacity(16))},
 		Name:            &name,
@@ -80,9 +80,7 @@ func ReducedConfig(id pveSDK.GuestID, node pveSDK.NodeName, name pveSDK.GuestNam
 		QemuDisks:       pveSDK.QemuDevices{},
 		QemuKVM:         new(true),
 		QemuOs:          "other",
-		QemuUnusedDisks: pveSDK.QemuDevices{},
-		QemuVga:         pveSDK.QemuDevice{},
-		Scsihw:          "lsi",
+		ScsiController:  new(pveSDK.QemuScsiControllerLsi),
 		StartAtNodeBoot: new(false),
 		Tablet:          new(false),
 		Tags:            new(pveSDK.Tags),
@@ -100,13 +98,13 @@ func MinimumConfig(id pveSDK.GuestID, node pveSDK.NodeName, name pveSDK.GuestNam
 	}
 	expected = &pveSDK.ConfigQemu{
 		Bios: "seabios",
-		Boot: " ",
+		Boot: new(pveSDK.QemuBootOrder{}),
 		CPU: &pveSDK.QemuCPU{
 			Cores:   new(pveSDK.QemuCpuCores(1)),
 			Numa:    new(false),
 			Sockets: new(pveSDK.QemuCpuSockets(1))},
 		Description:     new(""),
-		Hotplug:         "network,disk,usb",
+		Hotplug:         &pveSDK.QemuHotplug{CPU: new(false), CloudInit: new(false), Disk: new(true), Memory: new(false), Network: new(true), USB: new(true)},
 		ID:              &id,
 		Memory:          &pveSDK.QemuMemory{CapacityMiB: new(pveSDK.QemuMemoryCapacity(16))},
 		Name:            &name,
@@ -115,9 +113,7 @@ func MinimumConfig(id pveSDK.GuestID, node pveSDK.NodeName, name pveSDK.GuestNam
 		QemuDisks:       pveSDK.QemuDevices{},
 		QemuKVM:         new(true),
 		QemuOs:          "other",
-		QemuUnusedDisks: pveSDK.QemuDevices{},
-		QemuVga:         pveSDK.QemuDevice{},
-		Scsihw:          "lsi",
+		ScsiController:  new(pveSDK.QemuScsiControllerLsi),
 		StartAtNodeBoot: new(false),
 		Tablet:          new(true),
 		Tags:            new(pveSDK.Tags),
@@ -199,7 +195,7 @@ func MaximumConfig(id pveSDK.GuestID, node pveSDK.NodeName, name pveSDK.GuestNam
 	}
 	expected = &pveSDK.ConfigQemu{
 		Bios: "seabios",
-		Boot: " ",
+		Boot: new(pveSDK.QemuBootOrder{}),
 		CPU: &pveSDK.QemuCPU{
 			Affinity: &[]uint{1, 2},
 			Cores:    new(pveSDK.QemuCpuCores(2)),
@@ -262,7 +258,7 @@ func MaximumConfig(id pveSDK.GuestID, node pveSDK.NodeName, name pveSDK.GuestNam
 			PreEnrolledKeys: new(true),
 			Storage:         new(pveSDK.StorageName(test.GuestStorage)),
 		},
-		Hotplug:         "network,disk,usb",
+		Hotplug:         &pveSDK.QemuHotplug{CPU: new(false), CloudInit: new(false), Disk: new(true), Memory: new(false), Network: new(true), USB: new(true)},
 		ID:              &id,
 		Memory:          &pveSDK.QemuMemory{CapacityMiB: new(pveSDK.QemuMemoryCapacity(16))},
 		Name:            &name,
@@ -271,9 +267,7 @@ func MaximumConfig(id pveSDK.GuestID, node pveSDK.NodeName, name pveSDK.GuestNam
 		QemuDisks:       pveSDK.QemuDevices{},
 		QemuKVM:         new(true),
 		QemuOs:          "other",
-		QemuUnusedDisks: pveSDK.QemuDevices{},
-		QemuVga:         pveSDK.QemuDevice{},
-		Scsihw:          "lsi",
+		ScsiController:  new(pveSDK.QemuScsiControllerLsi),
 		StartAtNodeBoot: new(true),
 		Tablet:          new(true),
 		Tags:            new(pveSDK.Tags{"debian", "test", pveSDK.Tag(strings.ToLower(name.String()))}),
@@ -171,7 +171,7 @@ func Test_Qemu_Create_Max(t *testing.T) {
 						Numa:    new(false),
 						Sockets: new(pveSDK.QemuCpuSockets(1))},
 					Description:     util.Pointer(""),
-					Hotplug:         "network,disk,usb",
+					Hotplug:         &pveSDK.QemuHotplug{CPU: new(false), CloudInit: new(false), Disk: new(true), Memory: new(false), Network: new(true), USB: new(true)},
 					ID:              util.Pointer(pveSDK.GuestID(guestID)),
 					Memory:          &pveSDK.QemuMemory{CapacityMiB: util.Pointer(pveSDK.QemuMemoryCapacity(16))},
 					Name:            util.Pointer(pveSDK.GuestName(guestName)),
@@ -180,13 +180,11 @@ func Test_Qemu_Create_Max(t *testing.T) {
 					QemuDisks:       pveSDK.QemuDevices{},
 					QemuKVM:         util.Pointer(true),
 					QemuOs:          "other",
-					QemuUnusedDisks: pveSDK.QemuDevices{},
-					QemuVga:         pveSDK.QemuDevice{},
-					Scsihw:          "lsi",
+					ScsiController:  new(pveSDK.QemuScsiControllerLsi),
 					StartAtNodeBoot: util.Pointer(false),
 					Tablet:          util.Pointer(true),
 					Bios:            "seabios",
-					Boot:            " ",
+					Boot:            new(pveSDK.QemuBootOrder{}),
 					Tags:            new(pveSDK.Tags),
 					Watchdog: &pveSDK.Watchdog{
 						Action: new(pveSDK.WatchdogActionNone),
@@ -246,7 +244,7 @@ func Test_Qemu_Create_Disk_Minimal_Size(t *testing.T) {
 			test: func(t *testing.T) {
 				CheckConfig(t, ctx, cl, guestID, &pveSDK.ConfigQemu{
 					Bios: "seabios",
-					Boot: " ",
+					Boot: new(pveSDK.QemuBootOrder{}),
 					CPU: &pveSDK.QemuCPU{
 						Cores:   new(pveSDK.QemuCpuCores(1)),
 						Numa:    new(false),
@@ -258,7 +256,7 @@ func Test_Qemu_Create_Disk_Minimal_Size(t *testing.T) {
 						Format:          util.Pointer(pveSDK.QemuDiskFormat_Raw),
 						Size:            pveSDK.EfiDiskSize(1024),
 						Storage:         util.Pointer(pveSDK.StorageName(test.GuestStorage))},
-					Hotplug:         "network,disk,usb",
+					Hotplug:         &pveSDK.QemuHotplug{CPU: new(false), CloudInit: new(false), Disk: new(true), Memory: new(false), Network: new(true), USB: new(true)},
 					ID:              util.Pointer(pveSDK.GuestID(guestID)),
 					Memory:          &pveSDK.QemuMemory{CapacityMiB: util.Pointer(pveSDK.QemuMemoryCapacity(16))},
 					Name:            util.Pointer(pveSDK.GuestName(guestName)),
@@ -267,9 +265,7 @@ func Test_Qemu_Create_Disk_Minimal_Size(t *testing.T) {
 					QemuDisks:       pveSDK.QemuDevices{},
 					QemuKVM:         util.Pointer(true),
 					QemuOs:          "other",
-					QemuUnusedDisks: pveSDK.QemuDevices{},
-					QemuVga:         pveSDK.QemuDevice{},
-					Scsihw:          "lsi",
+					ScsiController:  new(pveSDK.QemuScsiControllerLsi),
 					StartAtNodeBoot: util.Pointer(false),
 					Tablet:          util.Pointer(true),
 					Tags:            new(pveSDK.Tags),
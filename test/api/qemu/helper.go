@@ -16,8 +16,7 @@ func GetConfig(t *testing.T, ctx context.Context, c *pveSDK.Client, guestID pveS
 	config, err := raw.Get(*vmr)
 	require.NoError(t, err)
 	require.NotNil(t, config)
-	config.Smbios1 = ""                           // This field is unique and cannot be predicted, so we ignore it for the comparison.
-	config.QemuUnusedDisks = pveSDK.QemuDevices{} // TODO include this field in test once reworked
+	config.Smbios = nil // This field is unique and cannot be predicted, so we ignore it for the comparison.
 	return config
 }
 
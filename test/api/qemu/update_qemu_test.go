@@ -55,7 +55,7 @@ func Test_Qemu_Update_Max_Transform(t *testing.T) {
 			test: func(t *testing.T) {
 				CheckConfig(t, ctx, cl, guestID, &pveSDK.ConfigQemu{
 					Bios: "seabios",
-					Boot: " ",
+					Boot: new(pveSDK.QemuBootOrder{}),
 					CPU: &pveSDK.QemuCPU{
 						Cores:   new(pveSDK.QemuCpuCores(1)),
 						Numa:    new(false),
@@ -67,7 +67,7 @@ func Test_Qemu_Update_Max_Transform(t *testing.T) {
 						PreEnrolledKeys: util.Pointer(false),
 						Storage:         util.Pointer(pveSDK.StorageName(test.GuestStorage)),
 					},
-					Hotplug:         "network,disk,usb",
+					Hotplug:         &pveSDK.QemuHotplug{CPU: new(false), CloudInit: new(false), Disk: new(true), Memory: new(false), Network: new(true), USB: new(true)},
 					ID:              util.Pointer(pveSDK.GuestID(guestID)),
 					Memory:          &pveSDK.QemuMemory{CapacityMiB: util.Pointer(pveSDK.QemuMemoryCapacity(16))},
 					Name:            util.Pointer(pveSDK.GuestName(guestName)),
@@ -76,9 +76,7 @@ func Test_Qemu_Update_Max_Transform(t *testing.T) {
 					QemuDisks:       pveSDK.QemuDevices{},
 					QemuKVM:         util.Pointer(true),
 					QemuOs:          "other",
-					QemuUnusedDisks: pveSDK.QemuDevices{},
-					QemuVga:         pveSDK.QemuDevice{},
-					Scsihw:          "lsi",
+					ScsiController:  new(pveSDK.QemuScsiControllerLsi),
 					StartAtNodeBoot: util.Pointer(false),
 					Tablet:          util.Pointer(true),
 					Tags:            new(pveSDK.Tags),
@@ -105,7 +103,7 @@ func Test_Qemu_Update_Max_Transform(t *testing.T) {
 			test: func(t *testing.T) {
 				CheckConfig(t, ctx, cl, guestID, &pveSDK.ConfigQemu{
 					Bios: "seabios",
-					Boot: " ",
+					Boot: new(pveSDK.QemuBootOrder{}),
 					CPU: &pveSDK.QemuCPU{
 						Cores:   new(pveSDK.QemuCpuCores(1)),
 						Numa:    new(false),
@@ -117,7 +115,7 @@ func Test_Qemu_Update_Max_Transform(t *testing.T) {
 						PreEnrolledKeys: util.Pointer(true),
 						Storage:         util.Pointer(pveSDK.StorageName(test.GuestStorage)),
 					},
-					Hotplug:         "network,disk,usb",
+					Hotplug:         &pveSDK.QemuHotplug{CPU: new(false), CloudInit: new(false), Disk: new(true), Memory: new(false), Network: new(true), USB: new(true)},
 					ID:              util.Pointer(pveSDK.GuestID(guestID)),
 					Memory:          &pveSDK.QemuMemory{CapacityMiB: util.Pointer(pveSDK.QemuMemoryCapacity(16))},
 					Name:            util.Pointer(pveSDK.GuestName(guestName)),
@@ -126,9 +124,7 @@ func Test_Qemu_Update_Max_Transform(t *testing.T) {
 					QemuDisks:       pveSDK.QemuDevices{},
 					QemuKVM:         util.Pointer(true),
 					QemuOs:          "other",
-					QemuUnusedDisks: pveSDK.QemuDevices{},
-					QemuVga:         pveSDK.QemuDevice{},
-					Scsihw:          "lsi",
+					ScsiController:  new(pveSDK.QemuScsiControllerLsi),
 					StartAtNodeBoot: util.Pointer(false),
 					Tablet:          util.Pointer(true),
 					Tags:            new(pveSDK.Tags),
@@ -215,7 +211,9 @@ func Test_Qemu_Upate_Max_To_Reduced(t *testing.T) {
 	c := cl.New()
 	var vmr *pveSDK.VmRef
 	setMax, expectedMax := MaximumConfig(guestID, node, guestName)
-	expectedMax.Boot = "order=ide1;ide0"
+	expectedMax.Boot = &pveSDK.QemuBootOrder{
+		{Disk: new(pveSDK.QemuDiskId("ide1"))},
+		{Disk: new(pveSDK.QemuDiskId("ide0"))}}
 	expectedMax.Architecture = new(pveSDK.QemuCpuArchitectureAmd64)
 	setReduced, expectedReduced := ReducedConfig(guestID, node, guestName)
 	expectedReduced.Architecture = new(pveSDK.QemuCpuArchitectureAmd64)